@@ -3,4 +3,4 @@
 #SECRET=$(openssl rand -base64 32)
 SECRET="some-secret"
 ISSUER="some-issuer"
-docker run -ti --rm -e SECRET=$SECRET -e ISSUER=$ISSUER -p 8080:8080 coding-exercise-1
+docker run -ti --rm -e SECRET=$SECRET -e ISSUER=$ISSUER -e CREDENTIALS_FILE=/configs/credentials.json -e CLIENTS_FILE=/configs/clients.json -v $(pwd)/../configs:/configs:ro -p 8080:8080 coding-exercise-1
@@ -0,0 +1,7 @@
+#!/bin/bash
+curl localhost:8080/token \
+  -v \
+  -u some-client:some-client-secret \
+  -H "Content-Type: application/x-www-form-urlencoded" \
+  -X POST -k \
+  --data 'grant_type=client_credentials&scope=sum:compute'
@@ -6,6 +6,7 @@ require (
 	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/gorilla/mux v1.8.0
 	github.com/stretchr/testify v1.8.0
+	golang.org/x/crypto v0.14.0
 )
 
 require (
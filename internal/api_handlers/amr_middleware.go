@@ -0,0 +1,43 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type AmrMiddleware struct {
+	required_methods []string
+}
+
+// NewAmrMiddleware only lets callers through who authenticated with all of the
+// required methods, for example "otp" for routes which need a second factor. It
+// reads the principal, so it has to run after the OidcAuthMiddleware.
+func NewAmrMiddleware(required_methods ...string) AuthMiddleware {
+	return &AmrMiddleware{
+		required_methods: required_methods,
+	}
+}
+
+func (m *AmrMiddleware) GetHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := lib.PrincipalFromContext(r.Context())
+		if !ok {
+			log.Println("principal missing, the amr middleware needs a token")
+			HttpErrorWithDescription(w, "unauthorized", "authentication is missing", http.StatusUnauthorized)
+			return
+		}
+
+		for _, method := range m.required_methods {
+			if !principal.HasAmr(method) {
+				log.Printf("token of %s is missing authentication method %s\n", principal.Subject, method)
+				HttpErrorWithDescription(w, "insufficient_user_authentication", fmt.Sprintf("authentication needs methods %s", strings.Join(m.required_methods, " ")), http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,74 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AmrMiddleware_returns_401_without_principal(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	sut := NewAmrMiddleware("otp").GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.JSONEq(t, `{"error":"unauthorized","error_description":"authentication is missing"}`, recorder.Body.String())
+}
+
+func Test_AmrMiddleware_returns_403_when_authentication_method_is_missing(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	sut := NewAmrMiddleware("otp").GetHandler(http.HandlerFunc(next_func))
+	principal := &lib.Principal{Subject: "some-user", Amr: []string{"pwd"}}
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(lib.WithPrincipal(req.Context(), principal))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.JSONEq(t, `{"error":"insufficient_user_authentication","error_description":"authentication needs methods otp"}`, recorder.Body.String())
+	assert.Contains(t, recorder.Body.String(), "insufficient_user_authentication")
+}
+
+func Test_AmrMiddleware_calls_next_when_authenticated_with_required_methods(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	sut := NewAmrMiddleware("pwd", "otp").GetHandler(http.HandlerFunc(next_func))
+	principal := &lib.Principal{Subject: "some-user", Amr: []string{"pwd", "otp"}}
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(lib.WithPrincipal(req.Context(), principal))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.True(t, called_next)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
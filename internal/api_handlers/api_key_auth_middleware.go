@@ -0,0 +1,90 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// ApiKeyHeader is the header integrations send their api key in.
+const ApiKeyHeader = "X-API-Key"
+
+type ApiKeyAuthMiddleware struct {
+	api_key_store   lib.ApiKeyStore
+	required_scopes []string
+}
+
+// NewApiKeyAuthMiddleware authenticates callers with the api key in the X-API-Key
+// header, for integrations which can only send a static header. Like with tokens
+// the key needs all of the required scopes.
+func NewApiKeyAuthMiddleware(api_key_store lib.ApiKeyStore, required_scopes ...string) AuthMiddleware {
+	return &ApiKeyAuthMiddleware{
+		api_key_store:   api_key_store,
+		required_scopes: required_scopes,
+	}
+}
+
+func (m *ApiKeyAuthMiddleware) GetHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// get api key, 401
+		key := r.Header.Get(ApiKeyHeader)
+		if key == "" {
+			log.Println("api key missing")
+			HttpErrorWithDescription(w, "unauthorized", "api key is missing", http.StatusUnauthorized)
+			return
+		}
+
+		// verify api key, 401
+		api_key, err := m.api_key_store.Verify(key)
+		if err != nil {
+			log.Printf("api key verification error: %s\n", err.Error())
+			HttpErrorWithDescription(w, "invalid_api_key", "api key is invalid, expired or revoked", http.StatusUnauthorized)
+			return
+		}
+
+		// check scopes, 403
+		principal := api_key.Principal()
+		for _, scope := range m.required_scopes {
+			if !principal.HasScope(scope) {
+				log.Printf("api key %s of %s is missing scope %s\n", api_key.Id, principal.Subject, scope)
+				HttpErrorWithDescription(w, "insufficient_scope", fmt.Sprintf("api key needs scope %s", strings.Join(m.required_scopes, " ")), http.StatusForbidden)
+				return
+			}
+		}
+
+		// if key ok call next, with the owner on the context
+		ctx := lib.WithPrincipal(r.Context(), principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type ApiKeyOrBearerAuthMiddleware struct {
+	api_key_middleware AuthMiddleware
+	bearer_middleware  AuthMiddleware
+}
+
+// NewApiKeyOrBearerAuthMiddleware lets routes accept either an api key or a bearer
+// token. Requests with the X-API-Key header are authenticated with the key only,
+// so a broken key doesn't fall back to the token or the other way around.
+func NewApiKeyOrBearerAuthMiddleware(api_key_middleware AuthMiddleware, bearer_middleware AuthMiddleware) AuthMiddleware {
+	return &ApiKeyOrBearerAuthMiddleware{
+		api_key_middleware: api_key_middleware,
+		bearer_middleware:  bearer_middleware,
+	}
+}
+
+func (m *ApiKeyOrBearerAuthMiddleware) GetHandler(next http.Handler) http.Handler {
+	api_key_handler := m.api_key_middleware.GetHandler(next)
+	bearer_handler := m.bearer_middleware.GetHandler(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(ApiKeyHeader) != "" {
+			api_key_handler.ServeHTTP(w, r)
+			return
+		}
+
+		bearer_handler.ServeHTTP(w, r)
+	})
+}
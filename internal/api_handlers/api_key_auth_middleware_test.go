@@ -0,0 +1,161 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ApiKeyAuthMiddleware_returns_401_when_missing_api_key(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	api_key_store_mock := &lib.ApiKeyStoreMock{}
+	sut := NewApiKeyAuthMiddleware(api_key_store_mock).GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.False(t, api_key_store_mock.VerifyCalled)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.JSONEq(t, `{"error":"unauthorized","error_description":"api key is missing"}`, recorder.Body.String())
+}
+
+func Test_ApiKeyAuthMiddleware_returns_401_on_invalid_api_key(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	api_key_store_mock := &lib.ApiKeyStoreMock{
+		NextVerifyError: lib.ErrApiKeyStoreInvalidKey,
+	}
+	sut := NewApiKeyAuthMiddleware(api_key_store_mock).GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("X-API-Key", "some-key")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, "some-key", api_key_store_mock.LastKey)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.JSONEq(t, `{"error":"invalid_api_key","error_description":"api key is invalid, expired or revoked"}`, recorder.Body.String())
+}
+
+func Test_ApiKeyAuthMiddleware_returns_403_when_missing_required_scope(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	api_key_store_mock := &lib.ApiKeyStoreMock{
+		NextVerifyResult: &lib.ApiKey{Id: "some-id", Owner: "some-owner", Scopes: []string{"other-scope"}},
+	}
+	sut := NewApiKeyAuthMiddleware(api_key_store_mock, "some-scope").GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("X-API-Key", "some-key")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.JSONEq(t, `{"error":"insufficient_scope","error_description":"api key needs scope some-scope"}`, recorder.Body.String())
+	assert.Contains(t, recorder.Body.String(), "insufficient_scope")
+}
+
+func Test_ApiKeyAuthMiddleware_calls_next_with_owner_as_principal(t *testing.T) {
+	// Arrange
+	var principal *lib.Principal
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = lib.PrincipalFromContext(r.Context())
+	}
+
+	api_key_store_mock := &lib.ApiKeyStoreMock{
+		NextVerifyResult: &lib.ApiKey{Id: "some-id", Owner: "some-owner", Scopes: []string{"some-scope"}, ExpiresAt: time.Now().Add(time.Hour)},
+	}
+	sut := NewApiKeyAuthMiddleware(api_key_store_mock, "some-scope").GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("X-API-Key", "some-key")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	require.NotNil(t, principal)
+	assert.Equal(t, "some-owner", principal.Subject)
+}
+
+func Test_ApiKeyOrBearerAuthMiddleware_uses_api_key_when_header_is_present(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	api_key_store_mock := &lib.ApiKeyStoreMock{
+		NextVerifyResult: &lib.ApiKey{Id: "some-id", Owner: "some-owner"},
+	}
+	oidc_provider_mock := &lib.OidcProviderMock{}
+	middleware := NewApiKeyOrBearerAuthMiddleware(NewApiKeyAuthMiddleware(api_key_store_mock), NewOidcAuthMiddleware(oidc_provider_mock, "some-realm"))
+	sut := middleware.GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("X-API-Key", "some-key")
+	req.Header.Add("Authorization", "Bearer some-token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.True(t, called_next)
+	assert.True(t, api_key_store_mock.VerifyCalled)
+	assert.False(t, oidc_provider_mock.ValidateTokenCalled)
+}
+
+func Test_ApiKeyOrBearerAuthMiddleware_uses_bearer_token_without_api_key(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	api_key_store_mock := &lib.ApiKeyStoreMock{}
+	oidc_provider_mock := &lib.OidcProviderMock{
+		NextValidateTokenResult: map[string]interface{}{"sub": "some-user"},
+	}
+	middleware := NewApiKeyOrBearerAuthMiddleware(NewApiKeyAuthMiddleware(api_key_store_mock), NewOidcAuthMiddleware(oidc_provider_mock, "some-realm"))
+	sut := middleware.GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("Authorization", "Bearer some-token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.True(t, called_next)
+	assert.False(t, api_key_store_mock.VerifyCalled)
+	assert.True(t, oidc_provider_mock.ValidateTokenCalled)
+}
@@ -0,0 +1,45 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type ApiKeyIssueHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.ApiKeyIssueRequest, app_handlers.ApiKeyIssueResponse]
+}
+
+func NewApiKeyIssueHandler(app_handler app_handlers.AppHandler[app_handlers.ApiKeyIssueRequest, app_handlers.ApiKeyIssueResponse]) *ApiKeyIssueHandler {
+	return &ApiKeyIssueHandler{
+		app_handler: app_handler,
+	}
+}
+
+func (h *ApiKeyIssueHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var req app_handlers.ApiKeyIssueRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	// the response contains the key, it must not be cached
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrApiKeyIssueValidationError) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else {
+			HttpError(w, app_handlers.ErrApiKeyIssueError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
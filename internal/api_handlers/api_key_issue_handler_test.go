@@ -0,0 +1,85 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ApiKeyIssueHandler_returns_400_on_invalid_json_in_body(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.ApiKeyIssueHandlerMock{}
+	sut := NewApiKeyIssueHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"owner":"some-owner","expiresIn":"invalid"}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.False(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_ApiKeyIssueHandler_calls_app_handler_and_returns_key(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.ApiKeyIssueHandlerMock{
+		NextResponse: &app_handlers.ApiKeyIssueResponse{Id: "some-id", Key: "some-key"},
+	}
+	sut := NewApiKeyIssueHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"owner":"some-owner","scopes":["some-scope"],"expiresIn":"24h"}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "some-owner", app_handler_mock.LastRequest.Owner)
+	assert.Equal(t, []string{"some-scope"}, app_handler_mock.LastRequest.Scopes)
+	assert.Equal(t, 24*time.Hour, time.Duration(app_handler_mock.LastRequest.ExpiresIn))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+	assert.Contains(t, recorder.Body.String(), `"key":"some-key"`)
+}
+
+func Test_ApiKeyIssueHandler_returns_400_on_validation_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.ApiKeyIssueHandlerMock{
+		NextError: app_handlers.ErrApiKeyIssueValidationError,
+	}
+	sut := NewApiKeyIssueHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_ApiKeyIssueHandler_returns_500_on_unknown_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.ApiKeyIssueHandlerMock{
+		NextError: errors.New("some-error"),
+	}
+	sut := NewApiKeyIssueHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"owner":"some-owner"}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
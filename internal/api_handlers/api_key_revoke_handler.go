@@ -0,0 +1,42 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type ApiKeyRevokeHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.ApiKeyRevokeRequest, app_handlers.ApiKeyRevokeResponse]
+}
+
+func NewApiKeyRevokeHandler(app_handler app_handlers.AppHandler[app_handlers.ApiKeyRevokeRequest, app_handlers.ApiKeyRevokeResponse]) *ApiKeyRevokeHandler {
+	return &ApiKeyRevokeHandler{
+		app_handler: app_handler,
+	}
+}
+
+// Handle reads the id of the key from the {id} variable of the route.
+func (h *ApiKeyRevokeHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	req := app_handlers.ApiKeyRevokeRequest{
+		Id: mux.Vars(r)["id"],
+	}
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrApiKeyRevokeValidationError) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, app_handlers.ErrApiKeyRevokeUnknownKey) {
+			HttpError(w, err.Error(), http.StatusNotFound)
+		} else {
+			HttpError(w, app_handlers.ErrApiKeyRevokeError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
@@ -0,0 +1,63 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func newApiKeyRevokeRequest(id string) *http.Request {
+	req := httptest.NewRequest("DELETE", "/"+id, nil)
+	return mux.SetURLVars(req, map[string]string{"id": id})
+}
+
+func Test_ApiKeyRevokeHandler_calls_app_handler_with_id_of_route(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.ApiKeyRevokeHandlerMock{
+		NextResponse: &app_handlers.ApiKeyRevokeResponse{},
+	}
+	sut := NewApiKeyRevokeHandler(app_handler_mock)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, newApiKeyRevokeRequest("some-id"))
+
+	// Assert
+	assert.Equal(t, "some-id", app_handler_mock.LastRequest.Id)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_ApiKeyRevokeHandler_returns_404_on_unknown_key(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.ApiKeyRevokeHandlerMock{
+		NextError: app_handlers.ErrApiKeyRevokeUnknownKey,
+	}
+	sut := NewApiKeyRevokeHandler(app_handler_mock)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, newApiKeyRevokeRequest("some-id"))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func Test_ApiKeyRevokeHandler_returns_500_on_unknown_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.ApiKeyRevokeHandlerMock{
+		NextError: errors.New("some-error"),
+	}
+	sut := NewApiKeyRevokeHandler(app_handler_mock)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, newApiKeyRevokeRequest("some-id"))
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
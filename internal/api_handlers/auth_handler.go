@@ -4,7 +4,10 @@ import (
 	"coding_exercise/internal/app_handlers"
 	"encoding/json"
 	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 )
 
 type AuthHandler struct {
@@ -25,11 +28,17 @@ func (h *AuthHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.app_handler.Handle(req)
+	req.ClientIp = clientIp(r)
+	res, err := h.app_handler.Handle(r.Context(), req)
 
 	if err != nil {
 		if errors.Is(err, app_handlers.ErrAuthValidationError) {
 			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, app_handlers.ErrAuthInvalidCredentials) || errors.Is(err, app_handlers.ErrAuthOtpRequired) || errors.Is(err, app_handlers.ErrAuthInvalidOtp) {
+			HttpError(w, err.Error(), http.StatusUnauthorized)
+		} else if errors.Is(err, app_handlers.ErrAuthTooManyAttempts) {
+			setRetryAfter(w, err)
+			HttpError(w, err.Error(), http.StatusTooManyRequests)
 		} else {
 			HttpError(w, "error while generating token", http.StatusInternalServerError)
 		}
@@ -39,3 +48,26 @@ func (h *AuthHandler) Handle(w http.ResponseWriter, r *http.Request) {
 
 	HttpSuccess(w, res)
 }
+
+// clientIp is the address of the connection, X-Forwarded-For is not used as every
+// client could set it to avoid being throttled.
+func clientIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// setRetryAfter sets the Retry-After header in seconds, rounded up so the client
+// doesn't retry too early.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var retry_after_err *app_handlers.RetryAfterError
+	if !errors.As(err, &retry_after_err) {
+		return
+	}
+
+	seconds := int64(math.Ceil(retry_after_err.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+}
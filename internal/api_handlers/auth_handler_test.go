@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -61,6 +62,7 @@ func Test_AuthHandler_calls_app_handler_with_specified_username_and_password(t *
 	require.NotNil(t, app_handler_mock.LastRequest)
 	assert.Equal(t, "some-username", app_handler_mock.LastRequest.Username)
 	assert.Equal(t, "some-password", app_handler_mock.LastRequest.Password)
+	assert.Equal(t, "192.0.2.1", app_handler_mock.LastRequest.ClientIp)
 }
 
 func Test_AuthHandler_returns_400_on_validation_error(t *testing.T) {
@@ -83,6 +85,87 @@ func Test_AuthHandler_returns_400_on_validation_error(t *testing.T) {
 	assert.Contains(t, recorder.Body.String(), app_handlers.ErrAuthValidationError.Error())
 }
 
+func Test_AuthHandler_returns_401_on_invalid_credentials(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.AuthHandlerMock{
+		NextError: app_handlers.ErrAuthInvalidCredentials,
+	}
+	sut := NewAuthHandler(app_handler_mock)
+
+	body := strings.NewReader(`{"username":"some-username","password":"wrong-password"}`)
+	req := httptest.NewRequest("POST", "/", body)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.True(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), app_handlers.ErrAuthInvalidCredentials.Error())
+}
+
+func Test_AuthHandler_returns_401_when_otp_is_missing_or_invalid(t *testing.T) {
+	for _, app_err := range []error{app_handlers.ErrAuthOtpRequired, app_handlers.ErrAuthInvalidOtp} {
+		// Arrange
+		app_handler_mock := &app_handlers.AuthHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewAuthHandler(app_handler_mock)
+
+		body := strings.NewReader(`{"username":"some-username","password":"some-password","otp":"123456"}`)
+		req := httptest.NewRequest("POST", "/", body)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, req)
+
+		// Assert
+		assert.Equal(t, "123456", app_handler_mock.LastRequest.Otp)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), app_err.Error())
+	}
+}
+
+func Test_AuthHandler_returns_429_with_retry_after_on_too_many_attempts(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.AuthHandlerMock{
+		NextError: &app_handlers.RetryAfterError{Err: app_handlers.ErrAuthTooManyAttempts, RetryAfter: 1500 * time.Millisecond},
+	}
+	sut := NewAuthHandler(app_handler_mock)
+
+	body := strings.NewReader(`{"username":"some-username","password":"some-password"}`)
+	req := httptest.NewRequest("POST", "/", body)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+	assert.Contains(t, recorder.Body.String(), app_handlers.ErrAuthTooManyAttempts.Error())
+}
+
+func Test_AuthHandler_returns_500_on_credential_verification_failure(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.AuthHandlerMock{
+		NextError: app_handlers.ErrAuthCredentialVerificationError,
+	}
+	sut := NewAuthHandler(app_handler_mock)
+
+	body := strings.NewReader(`{}`)
+	req := httptest.NewRequest("POST", "/", body)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.True(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
 func Test_AuthHandler_returns_500_on_token_generation_failure(t *testing.T) {
 	// Arrange
 	app_handler_mock := &app_handlers.AuthHandlerMock{
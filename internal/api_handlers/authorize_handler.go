@@ -0,0 +1,157 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"coding_exercise/internal/lib"
+	"crypto/subtle"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+)
+
+// csrfCookieName is the cookie which holds the CSRF token of the login form, a
+// post is only accepted when the form sends the same token. Other sites can't read
+// or set the cookie, so they can't submit the form in the name of the user.
+const csrfCookieName = "authorize_csrf"
+
+// errInvalidCsrfToken is shown on the login form, the user can simply log in again.
+var errInvalidCsrfToken = errors.New("the login form has expired, please try again")
+
+// loginForm posts the authorization request back together with the credentials,
+// so no session is needed between showing the form and issuing the code.
+var loginForm = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Log in</title></head>
+<body>
+<form method="POST" action="/authorize">
+{{if .Error}}<p>{{.Error}}</p>{{end}}
+<input type="hidden" name="response_type" value="{{.Request.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.Request.ClientId}}">
+<input type="hidden" name="redirect_uri" value="{{.Request.RedirectUri}}">
+<input type="hidden" name="scope" value="{{.Request.Scope}}">
+<input type="hidden" name="state" value="{{.Request.State}}">
+<input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.Request.CodeChallengeMethod}}">
+<input type="hidden" name="nonce" value="{{.Request.Nonce}}">
+<input type="hidden" name="csrf_token" value="{{.CsrfToken}}">
+<label>Username <input type="text" name="username" value="{{.Request.Username}}" autofocus></label>
+<label>Password <input type="password" name="password"></label>
+<label>One time password <input type="text" name="otp" inputmode="numeric" autocomplete="one-time-code"></label>
+<button type="submit">Log in</button>
+</form>
+</body>
+</html>
+`))
+
+type loginFormData struct {
+	Request   app_handlers.AuthorizeRequest
+	Error     string
+	CsrfToken string
+}
+
+type AuthorizeHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.AuthorizeRequest, app_handlers.AuthorizeResponse]
+}
+
+func NewAuthorizeHandler(app_handler app_handlers.AppHandler[app_handlers.AuthorizeRequest, app_handlers.AuthorizeResponse]) *AuthorizeHandler {
+	return &AuthorizeHandler{
+		app_handler: app_handler,
+	}
+}
+
+// Handle shows the login form on GET and redirects back to the client once the
+// posted credentials are valid. The credentials are only read from the body.
+func (h *AuthorizeHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		HttpError(w, "unable to read request", http.StatusBadRequest)
+		return
+	}
+
+	req := app_handlers.AuthorizeRequest{
+		ResponseType:        r.Form.Get("response_type"),
+		ClientId:            r.Form.Get("client_id"),
+		RedirectUri:         r.Form.Get("redirect_uri"),
+		Scope:               r.Form.Get("scope"),
+		State:               r.Form.Get("state"),
+		CodeChallenge:       r.Form.Get("code_challenge"),
+		CodeChallengeMethod: r.Form.Get("code_challenge_method"),
+		Nonce:               r.Form.Get("nonce"),
+		Username:            r.PostForm.Get("username"),
+		Password:            r.PostForm.Get("password"),
+		Otp:                 r.PostForm.Get("otp"),
+		ClientIp:            clientIp(r),
+	}
+
+	// the credentials of a post are only checked when it came from our own form
+	if r.Method == http.MethodPost && !hasValidCsrfToken(r) {
+		log.Printf("rejecting login of %s from %s without valid csrf token", req.Username, req.ClientIp)
+		renderLoginForm(w, r, req, errInvalidCsrfToken.Error(), http.StatusForbidden)
+		return
+	}
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrAuthorizeLoginRequired) {
+			renderLoginForm(w, r, req, "", http.StatusOK)
+		} else if errors.Is(err, app_handlers.ErrAuthorizeInvalidCredentials) || errors.Is(err, app_handlers.ErrAuthorizeOtpRequired) || errors.Is(err, app_handlers.ErrAuthorizeInvalidOtp) {
+			renderLoginForm(w, r, req, err.Error(), http.StatusUnauthorized)
+		} else if errors.Is(err, app_handlers.ErrAuthorizeTooManyAttempts) {
+			setRetryAfter(w, err)
+			renderLoginForm(w, r, req, err.Error(), http.StatusTooManyRequests)
+		} else if errors.Is(err, app_handlers.ErrAuthorizeInvalidClient) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else {
+			HttpError(w, "error while authorizing", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	http.Redirect(w, r, res.RedirectUri, http.StatusFound)
+}
+
+func renderLoginForm(w http.ResponseWriter, r *http.Request, req app_handlers.AuthorizeRequest, message string, status_code int) {
+	// the password and one time password are never rendered back
+	req.Password = ""
+	req.Otp = ""
+
+	// every form gets a new token, the cookie only lives as long as the browser session
+	csrf_token, err := lib.NewRandomToken()
+	if err != nil {
+		log.Printf("unable to create csrf token: %s\n", err)
+		HttpError(w, "error while authorizing", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookieName,
+		Value:    csrf_token,
+		Path:     "/authorize",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	// the form must not be framed by other sites, or the login could be clickjacked
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+	w.WriteHeader(status_code)
+
+	if err := loginForm.Execute(w, loginFormData{Request: req, Error: message, CsrfToken: csrf_token}); err != nil {
+		log.Printf("unable to render login form: %s\n", err)
+	}
+}
+
+// hasValidCsrfToken compares the token of the form with the one of the cookie.
+func hasValidCsrfToken(r *http.Request) bool {
+	cookie, err := r.Cookie(csrfCookieName)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(r.PostForm.Get("csrf_token"))) == 1
+}
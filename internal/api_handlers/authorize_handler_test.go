@@ -0,0 +1,227 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLoginFormRequest posts the form with the token of its csrf cookie.
+func newLoginFormRequest(body string) *http.Request {
+	req := newFormRequest(body + "&csrf_token=some-csrf-token")
+	req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: "some-csrf-token"})
+	return req
+}
+
+func Test_AuthorizeHandler_renders_login_form_when_login_required(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.AuthorizeHandlerMock{
+		NextError: app_handlers.ErrAuthorizeLoginRequired,
+	}
+	sut := NewAuthorizeHandler(app_handler_mock)
+
+	req := httptest.NewRequest("GET", "/authorize?response_type=code&client_id=some-client&state=%22%3E%3Cscript%3E", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "some-client", app_handler_mock.LastRequest.ClientId)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "DENY", recorder.Header().Get("X-Frame-Options"))
+	assert.Contains(t, recorder.Body.String(), `name="client_id" value="some-client"`)
+	assert.Contains(t, recorder.Body.String(), `name="password"`)
+	assert.NotContains(t, recorder.Body.String(), `"><script>`)
+}
+
+func Test_AuthorizeHandler_renders_login_form_with_csrf_token_of_cookie(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.AuthorizeHandlerMock{
+		NextError: app_handlers.ErrAuthorizeLoginRequired,
+	}
+	sut := NewAuthorizeHandler(app_handler_mock)
+
+	req := httptest.NewRequest("GET", "/authorize?response_type=code&client_id=some-client", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, csrfCookieName, cookies[0].Name)
+	assert.NotEmpty(t, cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+	assert.Contains(t, recorder.Body.String(), `name="csrf_token" value="`+cookies[0].Value+`"`)
+}
+
+func Test_AuthorizeHandler_rejects_login_without_valid_csrf_token(t *testing.T) {
+	for _, req := range []*http.Request{
+		newFormRequest("client_id=some-client&username=some-user&password=some-password"),
+		newFormRequest("client_id=some-client&username=some-user&password=some-password&csrf_token=some-csrf-token"),
+		func() *http.Request {
+			req := newFormRequest("client_id=some-client&username=some-user&password=some-password&csrf_token=other-csrf-token")
+			req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: "some-csrf-token"})
+			return req
+		}(),
+	} {
+		// Arrange
+		app_handler_mock := &app_handlers.AuthorizeHandlerMock{
+			NextResponse: &app_handlers.AuthorizeResponse{RedirectUri: "http://localhost/callback?code=some-code"},
+		}
+		sut := NewAuthorizeHandler(app_handler_mock)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, req)
+
+		// Assert
+		assert.False(t, app_handler_mock.HandleCalled)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "the login form has expired")
+		assert.NotContains(t, recorder.Body.String(), "some-password")
+	}
+}
+
+func Test_AuthorizeHandler_ignores_credentials_in_query(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.AuthorizeHandlerMock{
+		NextError: app_handlers.ErrAuthorizeLoginRequired,
+	}
+	sut := NewAuthorizeHandler(app_handler_mock)
+
+	req := httptest.NewRequest("GET", "/authorize?username=some-user&password=some-password", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Empty(t, app_handler_mock.LastRequest.Username)
+	assert.Empty(t, app_handler_mock.LastRequest.Password)
+}
+
+func Test_AuthorizeHandler_renders_login_form_with_error_on_invalid_credentials(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.AuthorizeHandlerMock{
+		NextError: app_handlers.ErrAuthorizeInvalidCredentials,
+	}
+	sut := NewAuthorizeHandler(app_handler_mock)
+
+	req := newLoginFormRequest("client_id=some-client&username=some-user&password=wrong-password")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "some-user", app_handler_mock.LastRequest.Username)
+	assert.Equal(t, "wrong-password", app_handler_mock.LastRequest.Password)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid username or password")
+	assert.NotContains(t, recorder.Body.String(), "wrong-password")
+}
+
+func Test_AuthorizeHandler_renders_login_form_with_error_on_missing_or_invalid_otp(t *testing.T) {
+	for _, app_err := range []error{app_handlers.ErrAuthorizeOtpRequired, app_handlers.ErrAuthorizeInvalidOtp} {
+		// Arrange
+		app_handler_mock := &app_handlers.AuthorizeHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewAuthorizeHandler(app_handler_mock)
+
+		req := newLoginFormRequest("client_id=some-client&username=some-user&password=some-password&otp=654321")
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, req)
+
+		// Assert
+		assert.Equal(t, "654321", app_handler_mock.LastRequest.Otp)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), app_err.Error())
+		assert.Contains(t, recorder.Body.String(), `name="otp"`)
+		assert.NotContains(t, recorder.Body.String(), "654321")
+	}
+}
+
+func Test_AuthorizeHandler_renders_login_form_with_429_on_too_many_attempts(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.AuthorizeHandlerMock{
+		NextError: &app_handlers.RetryAfterError{Err: app_handlers.ErrAuthorizeTooManyAttempts, RetryAfter: time.Minute},
+	}
+	sut := NewAuthorizeHandler(app_handler_mock)
+
+	req := newLoginFormRequest("client_id=some-client&username=some-user&password=some-password")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "192.0.2.1", app_handler_mock.LastRequest.ClientIp)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "60", recorder.Header().Get("Retry-After"))
+	assert.Contains(t, recorder.Body.String(), "too many failed logins")
+}
+
+func Test_AuthorizeHandler_redirects_to_client(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.AuthorizeHandlerMock{
+		NextResponse: &app_handlers.AuthorizeResponse{RedirectUri: "http://localhost/callback?code=some-code"},
+	}
+	sut := NewAuthorizeHandler(app_handler_mock)
+
+	req := newLoginFormRequest("client_id=some-client&username=some-user&password=some-password")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, "http://localhost/callback?code=some-code", recorder.Header().Get("Location"))
+}
+
+func Test_AuthorizeHandler_returns_400_on_invalid_client(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.AuthorizeHandlerMock{
+		NextError: app_handlers.ErrAuthorizeInvalidClient,
+	}
+	sut := NewAuthorizeHandler(app_handler_mock)
+
+	req := httptest.NewRequest("GET", "/authorize?client_id=unknown-client", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_AuthorizeHandler_returns_500_on_unknown_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.AuthorizeHandlerMock{
+		NextError: errors.New("some-error"),
+	}
+	sut := NewAuthorizeHandler(app_handler_mock)
+
+	req := httptest.NewRequest("GET", "/authorize", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
@@ -0,0 +1,107 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+type ClientAuthMiddleware struct {
+	client_store lib.ClientStore
+	optional     bool
+}
+
+// NewClientAuthMiddleware authenticates clients with HTTP Basic authentication,
+// the client_secret_basic method of RFC 6749.
+func NewClientAuthMiddleware(client_store lib.ClientStore) AuthMiddleware {
+	return &ClientAuthMiddleware{
+		client_store: client_store,
+	}
+}
+
+// NewOptionalClientAuthMiddleware authenticates confidential clients like
+// NewClientAuthMiddleware and identifies public clients by the client_id of the
+// form body, as RFC 7009 §2.1 asks for. Requests without a client are passed on
+// without a principal.
+func NewOptionalClientAuthMiddleware(client_store lib.ClientStore) AuthMiddleware {
+	return &ClientAuthMiddleware{
+		client_store: client_store,
+		optional:     true,
+	}
+}
+
+func (m *ClientAuthMiddleware) GetHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// get client credentials, 401
+		client_id, client_secret, ok := r.BasicAuth()
+		if !ok && m.optional {
+			m.identifyPublicClient(w, r, next)
+			return
+		}
+
+		if !ok {
+			log.Println("client credentials missing")
+			w.Header().Set("WWW-Authenticate", `Basic realm="client"`)
+			HttpErrorWithDescription(w, "invalid_client", "client credentials are missing", http.StatusUnauthorized)
+			return
+		}
+
+		// RFC 6749 form encodes the credentials before encoding them as basic auth
+		client_id = unescapeCredential(client_id)
+		client_secret = unescapeCredential(client_secret)
+
+		// verify client, 401
+		client, err := m.client_store.VerifyClient(client_id, client_secret)
+		if err != nil {
+			log.Printf("client verification error for %s: %s\n", client_id, err.Error())
+			w.Header().Set("WWW-Authenticate", `Basic realm="client"`)
+			HttpErrorWithDescription(w, "invalid_client", "client authentication failed", http.StatusUnauthorized)
+			return
+		}
+
+		// if client ok call next, with the client on the context
+		ctx := lib.WithPrincipal(r.Context(), client.Principal())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// identifyPublicClient only accepts clients without a secret, confidential clients
+// must authenticate with their secret.
+func (m *ClientAuthMiddleware) identifyPublicClient(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	if err := r.ParseForm(); err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	client_id := r.PostForm.Get("client_id")
+	if client_id == "" {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	client, err := m.client_store.GetClient(client_id)
+	if err == nil && !client.IsPublic() {
+		err = lib.ErrClientStoreInvalidClient
+	}
+
+	if err != nil {
+		log.Printf("client identification error for %s: %s\n", client_id, err.Error())
+		HttpErrorWithDescription(w, "invalid_client", "client authentication failed", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := lib.WithPrincipal(r.Context(), client.Principal())
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// unescapeCredential keeps the value as is when it isn't form encoded, most clients
+// only use characters which don't need encoding.
+func unescapeCredential(value string) string {
+	unescaped, err := url.QueryUnescape(value)
+	if err != nil {
+		return value
+	}
+
+	return unescaped
+}
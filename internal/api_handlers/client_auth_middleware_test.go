@@ -0,0 +1,194 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ClientAuthMiddleware_returns_401_when_missing_credentials(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	client_store_mock := &lib.ClientStoreMock{}
+	middleware := NewClientAuthMiddleware(client_store_mock)
+
+	sut := middleware.GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("POST", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.False(t, client_store_mock.VerifyClientCalled)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.JSONEq(t, `{"error":"invalid_client","error_description":"client credentials are missing"}`, recorder.Body.String())
+	assert.Equal(t, `Basic realm="client"`, recorder.Header().Get("WWW-Authenticate"))
+}
+
+func Test_ClientAuthMiddleware_returns_401_on_invalid_client(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	client_store_mock := &lib.ClientStoreMock{
+		NextVerifyClientError: lib.ErrClientStoreInvalidClient,
+	}
+	middleware := NewClientAuthMiddleware(client_store_mock)
+
+	sut := middleware.GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("POST", "/", nil)
+	req.SetBasicAuth("some-client", "wrong-secret")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.JSONEq(t, `{"error":"invalid_client","error_description":"client authentication failed"}`, recorder.Body.String())
+}
+
+func Test_ClientAuthMiddleware_calls_next_on_valid_client(t *testing.T) {
+	// Arrange
+	called_next := false
+	var principal *lib.Principal
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+		principal, _ = lib.PrincipalFromContext(r.Context())
+	}
+
+	client_store_mock := &lib.ClientStoreMock{
+		NextVerifyClientResult: &lib.Client{Id: "some client"},
+	}
+	middleware := NewClientAuthMiddleware(client_store_mock)
+
+	sut := middleware.GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("POST", "/", nil)
+	req.SetBasicAuth("some+client", "some%3Asecret")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.True(t, called_next)
+	assert.Equal(t, "some client", client_store_mock.LastId)
+	assert.Equal(t, "some:secret", client_store_mock.LastSecret)
+	if assert.NotNil(t, principal) {
+		assert.Equal(t, "some client", principal.Subject)
+	}
+}
+
+func Test_OptionalClientAuthMiddleware_calls_next_without_client(t *testing.T) {
+	// Arrange
+	called_next := false
+	has_principal := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+		_, has_principal = lib.PrincipalFromContext(r.Context())
+	}
+
+	client_store_mock := &lib.ClientStoreMock{}
+	middleware := NewOptionalClientAuthMiddleware(client_store_mock)
+
+	sut := middleware.GetHandler(http.HandlerFunc(next_func))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, newFormRequest("token=some-token"))
+
+	// Assert
+	assert.True(t, called_next)
+	assert.False(t, has_principal)
+	assert.False(t, client_store_mock.GetClientCalled)
+	assert.False(t, client_store_mock.VerifyClientCalled)
+}
+
+func Test_OptionalClientAuthMiddleware_identifies_public_client_by_client_id(t *testing.T) {
+	// Arrange
+	var principal *lib.Principal
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = lib.PrincipalFromContext(r.Context())
+	}
+
+	client_store_mock := &lib.ClientStoreMock{
+		NextGetClientResult: &lib.Client{Id: "some-public-client"},
+	}
+	middleware := NewOptionalClientAuthMiddleware(client_store_mock)
+
+	sut := middleware.GetHandler(http.HandlerFunc(next_func))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, newFormRequest("token=some-token&client_id=some-public-client"))
+
+	// Assert
+	assert.Equal(t, "some-public-client", client_store_mock.LastId)
+	if assert.NotNil(t, principal) {
+		assert.Equal(t, "some-public-client", principal.Subject)
+	}
+}
+
+func Test_OptionalClientAuthMiddleware_returns_401_for_confidential_client_without_secret(t *testing.T) {
+	for _, client_store_mock := range []*lib.ClientStoreMock{
+		{NextGetClientResult: &lib.Client{Id: "some-client", SecretHash: "some-hash"}},
+		{NextGetClientError: lib.ErrClientStoreInvalidClient},
+	} {
+		// Arrange
+		called_next := false
+		next_func := func(w http.ResponseWriter, r *http.Request) {
+			called_next = true
+		}
+
+		middleware := NewOptionalClientAuthMiddleware(client_store_mock)
+
+		sut := middleware.GetHandler(http.HandlerFunc(next_func))
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.ServeHTTP(recorder, newFormRequest("token=some-token&client_id=some-client"))
+
+		// Assert
+		assert.False(t, called_next)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.JSONEq(t, `{"error":"invalid_client","error_description":"client authentication failed"}`, recorder.Body.String())
+	}
+}
+
+func Test_OptionalClientAuthMiddleware_verifies_basic_auth(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	client_store_mock := &lib.ClientStoreMock{
+		NextVerifyClientError: lib.ErrClientStoreInvalidClient,
+	}
+	middleware := NewOptionalClientAuthMiddleware(client_store_mock)
+
+	sut := middleware.GetHandler(http.HandlerFunc(next_func))
+	req := newFormRequest("token=some-token")
+	req.SetBasicAuth("some-client", "wrong-secret")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.True(t, client_store_mock.VerifyClientCalled)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
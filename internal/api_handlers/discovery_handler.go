@@ -0,0 +1,30 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"log"
+	"net/http"
+)
+
+type DiscoveryHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.DiscoveryRequest, app_handlers.DiscoveryResponse]
+}
+
+func NewDiscoveryHandler(app_handler app_handlers.AppHandler[app_handlers.DiscoveryRequest, app_handlers.DiscoveryResponse]) *DiscoveryHandler {
+	return &DiscoveryHandler{
+		app_handler: app_handler,
+	}
+}
+
+func (h *DiscoveryHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	res, err := h.app_handler.Handle(r.Context(), app_handlers.DiscoveryRequest{})
+
+	if err != nil {
+		log.Printf("unable to handle discovery request: %s\n", err)
+		HttpError(w, "error while handling discovery request", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	HttpSuccess(w, res)
+}
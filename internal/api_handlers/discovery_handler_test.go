@@ -0,0 +1,48 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DiscoveryHandler_returns_the_discovery_document(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.DiscoveryHandlerMock{
+		NextResponse: &app_handlers.DiscoveryResponse{Issuer: "some-issuer"},
+	}
+	sut := NewDiscoveryHandler(app_handler_mock)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.True(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"issuer":"some-issuer"`)
+}
+
+func Test_DiscoveryHandler_returns_500_on_app_handler_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.DiscoveryHandlerMock{
+		NextError: errors.New("some-error"),
+	}
+	sut := NewDiscoveryHandler(app_handler_mock)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.True(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
@@ -7,12 +7,20 @@ import (
 )
 
 type HttpErrorResponse struct {
-	Error string `json:"error"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
 }
 
 func HttpError(w http.ResponseWriter, err string, status_code int) {
+	HttpErrorWithDescription(w, err, "", status_code)
+}
+
+// HttpErrorWithDescription adds a human readable description to the error code,
+// like the error responses of OAuth 2.0.
+func HttpErrorWithDescription(w http.ResponseWriter, err string, description string, status_code int) {
 	http_err := &HttpErrorResponse{
-		Error: err,
+		Error:            err,
+		ErrorDescription: description,
 	}
 
 	response, marshal_err := json.Marshal(http_err)
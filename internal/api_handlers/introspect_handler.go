@@ -0,0 +1,44 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+)
+
+type IntrospectHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.IntrospectRequest, app_handlers.IntrospectResponse]
+}
+
+func NewIntrospectHandler(app_handler app_handlers.AppHandler[app_handlers.IntrospectRequest, app_handlers.IntrospectResponse]) *IntrospectHandler {
+	return &IntrospectHandler{
+		app_handler: app_handler,
+	}
+}
+
+// Handle reads the form encoded body defined by RFC 7662.
+func (h *IntrospectHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	req := app_handlers.IntrospectRequest{
+		Token:         r.PostForm.Get("token"),
+		TokenTypeHint: r.PostForm.Get("token_type_hint"),
+	}
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrIntrospectValidationError) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else {
+			HttpError(w, "error while introspecting token", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
@@ -0,0 +1,67 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IntrospectHandler_returns_active_and_claims(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.IntrospectHandlerMock{
+		NextResponse: &app_handlers.IntrospectResponse{
+			Active: true,
+			Claims: map[string]interface{}{"sub": "some-user"},
+		},
+	}
+	sut := NewIntrospectHandler(app_handler_mock)
+
+	req := newFormRequest("token=some-token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "some-token", app_handler_mock.LastRequest.Token)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"active":true,"sub":"some-user"}`, recorder.Body.String())
+}
+
+func Test_IntrospectHandler_returns_400_on_validation_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.IntrospectHandlerMock{
+		NextError: app_handlers.ErrIntrospectValidationError,
+	}
+	sut := NewIntrospectHandler(app_handler_mock)
+
+	req := newFormRequest("")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_IntrospectHandler_returns_500_on_unknown_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.IntrospectHandlerMock{
+		NextError: errors.New("some-error"),
+	}
+	sut := NewIntrospectHandler(app_handler_mock)
+
+	req := newFormRequest("token=some-token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
@@ -0,0 +1,32 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"coding_exercise/internal/lib"
+	"log"
+	"net/http"
+)
+
+type JwksHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.JwksRequest, lib.JwkSet]
+}
+
+func NewJwksHandler(app_handler app_handlers.AppHandler[app_handlers.JwksRequest, lib.JwkSet]) *JwksHandler {
+	return &JwksHandler{
+		app_handler: app_handler,
+	}
+}
+
+func (h *JwksHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	res, err := h.app_handler.Handle(r.Context(), app_handlers.JwksRequest{})
+
+	if err != nil {
+		log.Printf("unable to handle jwks request: %s\n", err)
+		HttpError(w, "error while handling jwks request", http.StatusInternalServerError)
+		return
+	}
+
+	// keys change rarely, allow clients to cache them for a while
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	HttpSuccess(w, res)
+}
@@ -0,0 +1,52 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"coding_exercise/internal/lib"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JwksHandler_returns_the_key_set(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.JwksHandlerMock{
+		NextResponse: &lib.JwkSet{
+			Keys: []lib.Jwk{{Kty: "OKP", Crv: "Ed25519", X: "some-x", Kid: "some-kid"}},
+		},
+	}
+	sut := NewJwksHandler(app_handler_mock)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.True(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `{"keys":[{"kty":"OKP","kid":"some-kid","crv":"Ed25519","x":"some-x"}]}`, recorder.Body.String())
+	assert.NotEmpty(t, recorder.Header().Get("Cache-Control"))
+}
+
+func Test_JwksHandler_returns_500_on_app_handler_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.JwksHandlerMock{
+		NextError: errors.New("some-error"),
+	}
+	sut := NewJwksHandler(app_handler_mock)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.True(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
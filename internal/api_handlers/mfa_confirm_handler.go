@@ -0,0 +1,45 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type MfaConfirmHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.MfaConfirmRequest, app_handlers.MfaConfirmResponse]
+}
+
+func NewMfaConfirmHandler(app_handler app_handlers.AppHandler[app_handlers.MfaConfirmRequest, app_handlers.MfaConfirmResponse]) *MfaConfirmHandler {
+	return &MfaConfirmHandler{
+		app_handler: app_handler,
+	}
+}
+
+func (h *MfaConfirmHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var req app_handlers.MfaConfirmRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrMfaConfirmValidationError) ||
+			errors.Is(err, app_handlers.ErrMfaConfirmNoEnrollment) ||
+			errors.Is(err, app_handlers.ErrMfaConfirmInvalidOtp) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, app_handlers.ErrMfaConfirmForbidden) {
+			HttpError(w, err.Error(), http.StatusForbidden)
+		} else {
+			HttpError(w, app_handlers.ErrMfaConfirmError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
@@ -0,0 +1,101 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MfaConfirmHandler_returns_400_on_invalid_json_in_body(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.MfaConfirmHandlerMock{}
+	sut := NewMfaConfirmHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("invalid-json"))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.False(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_MfaConfirmHandler_calls_app_handler_with_specified_otp(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.MfaConfirmHandlerMock{
+		NextResponse: &app_handlers.MfaConfirmResponse{Enrolled: true},
+	}
+	sut := NewMfaConfirmHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"otp":"123456"}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "123456", app_handler_mock.LastRequest.Otp)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"enrolled":true}`, recorder.Body.String())
+}
+
+func Test_MfaConfirmHandler_returns_400_on_invalid_otp_or_missing_enrollment(t *testing.T) {
+	for _, app_err := range []error{app_handlers.ErrMfaConfirmValidationError, app_handlers.ErrMfaConfirmNoEnrollment, app_handlers.ErrMfaConfirmInvalidOtp} {
+		// Arrange
+		app_handler_mock := &app_handlers.MfaConfirmHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewMfaConfirmHandler(app_handler_mock)
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"otp":"123456"}`))
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), app_err.Error())
+	}
+}
+
+func Test_MfaConfirmHandler_returns_403_for_token_of_client(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.MfaConfirmHandlerMock{
+		NextError: app_handlers.ErrMfaConfirmForbidden,
+	}
+	sut := NewMfaConfirmHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"otp":"123456"}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func Test_MfaConfirmHandler_returns_500_on_unknown_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.MfaConfirmHandlerMock{
+		NextError: errors.New("some-error"),
+	}
+	sut := NewMfaConfirmHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"otp":"123456"}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
@@ -0,0 +1,37 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+)
+
+type MfaEnrollHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.MfaEnrollRequest, app_handlers.MfaEnrollResponse]
+}
+
+func NewMfaEnrollHandler(app_handler app_handlers.AppHandler[app_handlers.MfaEnrollRequest, app_handlers.MfaEnrollResponse]) *MfaEnrollHandler {
+	return &MfaEnrollHandler{
+		app_handler: app_handler,
+	}
+}
+
+func (h *MfaEnrollHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	// the response contains the secret and recovery codes, it must not be cached
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	res, err := h.app_handler.Handle(r.Context(), app_handlers.MfaEnrollRequest{})
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrMfaEnrollForbidden) || errors.Is(err, app_handlers.ErrMfaEnrollOtpRequired) {
+			HttpError(w, err.Error(), http.StatusForbidden)
+		} else {
+			HttpError(w, app_handlers.ErrMfaEnrollError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
@@ -0,0 +1,73 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MfaEnrollHandler_returns_enrollment_without_caching(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.MfaEnrollHandlerMock{
+		NextResponse: &app_handlers.MfaEnrollResponse{
+			Secret:        "some-secret",
+			Uri:           "otpauth://totp/some-uri",
+			RecoveryCodes: []string{"some-code"},
+		},
+	}
+	sut := NewMfaEnrollHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.True(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"secret":"some-secret","uri":"otpauth://totp/some-uri","recoveryCodes":["some-code"]}`, recorder.Body.String())
+}
+
+func Test_MfaEnrollHandler_returns_403_when_enrollment_is_not_allowed(t *testing.T) {
+	for _, app_err := range []error{app_handlers.ErrMfaEnrollForbidden, app_handlers.ErrMfaEnrollOtpRequired} {
+		// Arrange
+		app_handler_mock := &app_handlers.MfaEnrollHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewMfaEnrollHandler(app_handler_mock)
+
+		req := httptest.NewRequest("POST", "/", nil)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, req)
+
+		// Assert
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), app_err.Error())
+	}
+}
+
+func Test_MfaEnrollHandler_returns_500_on_unknown_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.MfaEnrollHandlerMock{
+		NextError: errors.New("some-error"),
+	}
+	sut := NewMfaEnrollHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "some-error")
+}
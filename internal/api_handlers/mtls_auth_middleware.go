@@ -0,0 +1,92 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type MtlsAuthMiddleware struct {
+	certificate_mapper lib.CertificateMapper
+	required_scopes    []string
+}
+
+// NewMtlsAuthMiddleware authenticates callers with the client certificate of the
+// TLS connection, only certificates the server verified against the client CA are
+// used. Like with tokens the caller needs all of the required scopes.
+func NewMtlsAuthMiddleware(certificate_mapper lib.CertificateMapper, required_scopes ...string) AuthMiddleware {
+	return &MtlsAuthMiddleware{
+		certificate_mapper: certificate_mapper,
+		required_scopes:    required_scopes,
+	}
+}
+
+func (m *MtlsAuthMiddleware) GetHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// get verified client certificate, 401
+		if !hasClientCertificate(r) {
+			log.Println("verified client certificate missing")
+			HttpErrorWithDescription(w, "unauthorized", "verified client certificate is missing", http.StatusUnauthorized)
+			return
+		}
+
+		// map certificate to principal, 401
+		certificate := r.TLS.VerifiedChains[0][0]
+		principal, err := m.certificate_mapper.Principal(certificate)
+		if err != nil {
+			log.Printf("client certificate error: %s\n", err.Error())
+			HttpErrorWithDescription(w, "invalid_client_certificate", "client certificate is not mapped to a client", http.StatusUnauthorized)
+			return
+		}
+
+		// check scopes, 403
+		for _, scope := range m.required_scopes {
+			if !principal.HasScope(scope) {
+				log.Printf("client certificate of %s is missing scope %s\n", principal.Subject, scope)
+				HttpErrorWithDescription(w, "insufficient_scope", fmt.Sprintf("client certificate needs scope %s", strings.Join(m.required_scopes, " ")), http.StatusForbidden)
+				return
+			}
+		}
+
+		// if certificate ok call next, with the caller on the context
+		ctx := lib.WithPrincipal(r.Context(), principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type MtlsOrFallbackAuthMiddleware struct {
+	mtls_middleware     AuthMiddleware
+	fallback_middleware AuthMiddleware
+}
+
+// NewMtlsOrFallbackAuthMiddleware lets routes accept client certificates besides
+// another scheme, like bearer tokens. Requests with a verified client certificate
+// are authenticated with the certificate only, others with the fallback.
+func NewMtlsOrFallbackAuthMiddleware(mtls_middleware AuthMiddleware, fallback_middleware AuthMiddleware) AuthMiddleware {
+	return &MtlsOrFallbackAuthMiddleware{
+		mtls_middleware:     mtls_middleware,
+		fallback_middleware: fallback_middleware,
+	}
+}
+
+func (m *MtlsOrFallbackAuthMiddleware) GetHandler(next http.Handler) http.Handler {
+	mtls_handler := m.mtls_middleware.GetHandler(next)
+	fallback_handler := m.fallback_middleware.GetHandler(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hasClientCertificate(r) {
+			mtls_handler.ServeHTTP(w, r)
+			return
+		}
+
+		fallback_handler.ServeHTTP(w, r)
+	})
+}
+
+// hasClientCertificate is only true for certificates which were verified during
+// the handshake, presented but unverified certificates don't count.
+func hasClientCertificate(r *http.Request) bool {
+	return r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0
+}
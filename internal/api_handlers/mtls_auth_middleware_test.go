@@ -0,0 +1,186 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMtlsRequest(certificate *x509.Certificate) *http.Request {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{certificate},
+		VerifiedChains:   [][]*x509.Certificate{{certificate}},
+	}
+
+	return req
+}
+
+func Test_MtlsAuthMiddleware_returns_401_without_client_certificate(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	certificate_mapper_mock := &lib.CertificateMapperMock{}
+	sut := NewMtlsAuthMiddleware(certificate_mapper_mock).GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.False(t, certificate_mapper_mock.PrincipalCalled)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.JSONEq(t, `{"error":"unauthorized","error_description":"verified client certificate is missing"}`, recorder.Body.String())
+}
+
+func Test_MtlsAuthMiddleware_returns_401_on_unverified_client_certificate(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	certificate_mapper_mock := &lib.CertificateMapperMock{
+		NextPrincipalResult: &lib.Principal{Subject: "some-service"},
+	}
+	sut := NewMtlsAuthMiddleware(certificate_mapper_mock).GetHandler(http.HandlerFunc(next_func))
+	req := newMtlsRequest(&x509.Certificate{})
+	req.TLS.VerifiedChains = nil
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func Test_MtlsAuthMiddleware_returns_401_on_unknown_identity(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	certificate_mapper_mock := &lib.CertificateMapperMock{
+		NextPrincipalError: lib.ErrCertificateMapperUnknownIdentity,
+	}
+	sut := NewMtlsAuthMiddleware(certificate_mapper_mock).GetHandler(http.HandlerFunc(next_func))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, newMtlsRequest(&x509.Certificate{}))
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.JSONEq(t, `{"error":"invalid_client_certificate","error_description":"client certificate is not mapped to a client"}`, recorder.Body.String())
+}
+
+func Test_MtlsAuthMiddleware_returns_403_when_missing_required_scope(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	certificate_mapper_mock := &lib.CertificateMapperMock{
+		NextPrincipalResult: &lib.Principal{Subject: "some-service", Scopes: []string{"other-scope"}},
+	}
+	sut := NewMtlsAuthMiddleware(certificate_mapper_mock, "some-scope").GetHandler(http.HandlerFunc(next_func))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, newMtlsRequest(&x509.Certificate{}))
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.JSONEq(t, `{"error":"insufficient_scope","error_description":"client certificate needs scope some-scope"}`, recorder.Body.String())
+}
+
+func Test_MtlsAuthMiddleware_calls_next_with_principal_of_certificate(t *testing.T) {
+	// Arrange
+	var principal *lib.Principal
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = lib.PrincipalFromContext(r.Context())
+	}
+
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: "some-service"}}
+	certificate_mapper_mock := &lib.CertificateMapperMock{
+		NextPrincipalResult: &lib.Principal{Subject: "some-service", Scopes: []string{"some-scope"}},
+	}
+	sut := NewMtlsAuthMiddleware(certificate_mapper_mock, "some-scope").GetHandler(http.HandlerFunc(next_func))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, newMtlsRequest(certificate))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Same(t, certificate, certificate_mapper_mock.LastCertificate)
+	require.NotNil(t, principal)
+	assert.Equal(t, "some-service", principal.Subject)
+}
+
+func Test_MtlsOrFallbackAuthMiddleware_uses_client_certificate_when_verified(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	certificate_mapper_mock := &lib.CertificateMapperMock{
+		NextPrincipalResult: &lib.Principal{Subject: "some-service"},
+	}
+	oidc_provider_mock := &lib.OidcProviderMock{}
+	middleware := NewMtlsOrFallbackAuthMiddleware(NewMtlsAuthMiddleware(certificate_mapper_mock), NewOidcAuthMiddleware(oidc_provider_mock, "some-realm"))
+	sut := middleware.GetHandler(http.HandlerFunc(next_func))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, newMtlsRequest(&x509.Certificate{}))
+
+	// Assert
+	assert.True(t, called_next)
+	assert.False(t, oidc_provider_mock.ValidateTokenCalled)
+}
+
+func Test_MtlsOrFallbackAuthMiddleware_uses_fallback_without_client_certificate(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	certificate_mapper_mock := &lib.CertificateMapperMock{}
+	oidc_provider_mock := &lib.OidcProviderMock{
+		NextValidateTokenResult: map[string]interface{}{"sub": "some-user"},
+	}
+	middleware := NewMtlsOrFallbackAuthMiddleware(NewMtlsAuthMiddleware(certificate_mapper_mock), NewOidcAuthMiddleware(oidc_provider_mock, "some-realm"))
+	sut := middleware.GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("Authorization", "Bearer some-token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.True(t, called_next)
+	assert.False(t, certificate_mapper_mock.PrincipalCalled)
+	assert.True(t, oidc_provider_mock.ValidateTokenCalled)
+}
@@ -2,6 +2,8 @@ package api_handlers
 
 import (
 	"coding_exercise/internal/lib"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -11,53 +13,104 @@ type AuthMiddleware interface {
 	GetHandler(next http.Handler) http.Handler
 }
 
+// invalidTokenDescriptions explain why a token was rejected, other errors are
+// reported as an invalid token without details.
+var invalidTokenDescriptions = []struct {
+	err         error
+	description string
+}{
+	{lib.ErrJwtOidcProviderMalformedToken, "token is malformed"},
+	{lib.ErrJwtOidcProviderExpiredToken, "token is expired"},
+	{lib.ErrJwtOidcProviderTokenNotValidYet, "token is not valid yet"},
+	{lib.ErrJwtOidcProviderUnknownIssuer, "token issuer is not trusted"},
+	{lib.ErrCompositeOidcProviderUnknownIssuer, "token issuer is not trusted"},
+	{lib.ErrJwtOidcProviderInvalidAudience, "token is not meant for this audience"},
+	{lib.ErrJwtOidcProviderRevokedToken, "token has been revoked"},
+}
+
 type OidcAuthMiddleware struct {
-	oidc_provider lib.OidcProvider
+	oidc_provider   lib.OidcProvider
+	realm           string
+	required_scopes []string
 }
 
-func NewOidcAuthMiddleware(oidc_provider lib.OidcProvider) AuthMiddleware {
+// NewOidcAuthMiddleware only lets tokens through which were issued for all of the
+// required scopes, other tokens are rejected with 403. Rejected requests get a
+// WWW-Authenticate challenge for the realm as described in RFC 6750.
+func NewOidcAuthMiddleware(oidc_provider lib.OidcProvider, realm string, required_scopes ...string) AuthMiddleware {
 	return &OidcAuthMiddleware{
-		oidc_provider: oidc_provider,
+		oidc_provider:   oidc_provider,
+		realm:           realm,
+		required_scopes: required_scopes,
 	}
 }
 
 func (m *OidcAuthMiddleware) GetHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// get authorization header, 401
-		auth_header := r.Header.Get("Authorization")
-		if auth_header == "" {
-			log.Println("authorization header missing")
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
-
-		// check for bearer, 401
-		if !strings.HasPrefix(auth_header, "Bearer") {
-			log.Println("Bearer not found")
-			w.WriteHeader(http.StatusUnauthorized)
+		// get bearer token, 401 without an error code as the request has no credentials
+		scheme, token, _ := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+		token = strings.TrimSpace(token)
+		if !strings.EqualFold(scheme, "Bearer") || token == "" {
+			log.Println("bearer token missing")
+			m.challenge(w, "", "")
+			HttpErrorWithDescription(w, "unauthorized", "bearer token is missing", http.StatusUnauthorized)
 			return
 		}
 
-		// extract token, 401
-		auth_split := strings.Split(strings.TrimSpace(auth_header), " ")
-		if len(auth_split) != 2 {
-			log.Println("token missing")
-			w.WriteHeader(http.StatusUnauthorized)
+		// the token itself can't contain spaces, 400
+		if strings.ContainsAny(token, " \t") {
+			log.Println("authorization header is malformed")
+			m.challenge(w, "invalid_request", "authorization header is malformed")
+			HttpErrorWithDescription(w, "invalid_request", "authorization header is malformed", http.StatusBadRequest)
 			return
 		}
 
 		// validate token, 401
-		token := auth_split[1]
-		_, err := m.oidc_provider.ValidateToken(token)
+		claims, err := m.oidc_provider.ValidateToken(token)
 		if err != nil {
 			log.Printf("token validation error: %s\n", err.Error())
-			w.WriteHeader(http.StatusUnauthorized)
+			description := invalidTokenDescription(err)
+			m.challenge(w, "invalid_token", description)
+			HttpErrorWithDescription(w, "invalid_token", description, http.StatusUnauthorized)
 			return
 		}
 
-		// todo: read claims and put on context
+		// check scopes, 403
+		principal := lib.NewPrincipal(claims)
+		for _, scope := range m.required_scopes {
+			if !principal.HasScope(scope) {
+				log.Printf("token of %s is missing scope %s\n", principal.Subject, scope)
+				description := fmt.Sprintf("token needs scope %s", strings.Join(m.required_scopes, " "))
+				m.challenge(w, "insufficient_scope", description, fmt.Sprintf(`scope="%s"`, strings.Join(m.required_scopes, " ")))
+				HttpErrorWithDescription(w, "insufficient_scope", description, http.StatusForbidden)
+				return
+			}
+		}
 
-		// if token ok call next
-		next.ServeHTTP(w, r)
+		// if token ok call next, with the caller on the context
+		ctx := lib.WithPrincipal(r.Context(), principal)
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// challenge sets the WWW-Authenticate header of RFC 6750, the error code is left
+// out when the request had no credentials at all.
+func (m *OidcAuthMiddleware) challenge(w http.ResponseWriter, error_code string, description string, params ...string) {
+	challenge := []string{fmt.Sprintf(`realm="%s"`, m.realm)}
+	if error_code != "" {
+		challenge = append(challenge, fmt.Sprintf(`error="%s"`, error_code), fmt.Sprintf(`error_description="%s"`, description))
+	}
+
+	challenge = append(challenge, params...)
+	w.Header().Set("WWW-Authenticate", "Bearer "+strings.Join(challenge, ", "))
+}
+
+func invalidTokenDescription(err error) string {
+	for _, known := range invalidTokenDescriptions {
+		if errors.Is(err, known.err) {
+			return known.description
+		}
+	}
+
+	return "token is invalid"
+}
@@ -3,11 +3,13 @@ package api_handlers
 import (
 	"coding_exercise/internal/lib"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_OidcAuthMiddleware_returns_401_when_missing_authorization_header(t *testing.T) {
@@ -18,7 +20,7 @@ func Test_OidcAuthMiddleware_returns_401_when_missing_authorization_header(t *te
 	}
 
 	oidc_provider_mock := &lib.OidcProviderMock{}
-	middleware := NewOidcAuthMiddleware(oidc_provider_mock)
+	middleware := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm")
 	handler := http.HandlerFunc(next_func)
 
 	sut := middleware.GetHandler(handler)
@@ -31,6 +33,9 @@ func Test_OidcAuthMiddleware_returns_401_when_missing_authorization_header(t *te
 	// Assert
 	assert.False(t, called_next)
 	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, `Bearer realm="some-realm"`, recorder.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"unauthorized","error_description":"bearer token is missing"}`, recorder.Body.String())
 }
 
 func Test_OidcAuthMiddleware_returns_401_when_missing_bearer_in_authorization_header(t *testing.T) {
@@ -40,7 +45,7 @@ func Test_OidcAuthMiddleware_returns_401_when_missing_bearer_in_authorization_he
 	}
 
 	oidc_provider_mock := &lib.OidcProviderMock{}
-	middleware := NewOidcAuthMiddleware(oidc_provider_mock)
+	middleware := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm")
 	handler := http.HandlerFunc(next_func)
 
 	sut := middleware.GetHandler(handler)
@@ -63,7 +68,7 @@ func Test_OidcAuthMiddleware_returns_401_when_missing_token_authorization_header
 	}
 
 	oidc_provider_mock := &lib.OidcProviderMock{}
-	middleware := NewOidcAuthMiddleware(oidc_provider_mock)
+	middleware := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm")
 	handler := http.HandlerFunc(next_func)
 
 	sut := middleware.GetHandler(handler)
@@ -88,7 +93,7 @@ func Test_OidcAuthMiddleware_returns_401_when_invalid_token_in_authorization_hea
 	oidc_provider_mock := &lib.OidcProviderMock{
 		NextValidateTokenError: errors.New("some error"),
 	}
-	middleware := NewOidcAuthMiddleware(oidc_provider_mock)
+	middleware := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm")
 	handler := http.HandlerFunc(next_func)
 
 	sut := middleware.GetHandler(handler)
@@ -113,7 +118,7 @@ func Test_OidcAuthMiddleware_returns_calls_next_when_valid_token_in_authorizatio
 	oidc_provider_mock := &lib.OidcProviderMock{
 		NextValidateTokenError: nil,
 	}
-	middleware := NewOidcAuthMiddleware(oidc_provider_mock)
+	middleware := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm")
 	handler := http.HandlerFunc(next_func)
 
 	sut := middleware.GetHandler(handler)
@@ -128,3 +133,180 @@ func Test_OidcAuthMiddleware_returns_calls_next_when_valid_token_in_authorizatio
 	assert.True(t, called_next)
 	assert.Equal(t, http.StatusOK, recorder.Code)
 }
+
+func Test_OidcAuthMiddleware_puts_principal_on_request_context(t *testing.T) {
+	// Arrange
+	var principal *lib.Principal
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = lib.PrincipalFromContext(r.Context())
+	}
+
+	oidc_provider_mock := &lib.OidcProviderMock{
+		NextValidateTokenResult: map[string]interface{}{"sub": "some-user", "iss": "some-issuer"},
+	}
+	middleware := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm")
+	handler := http.HandlerFunc(next_func)
+
+	sut := middleware.GetHandler(handler)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("Authorization", "Bearer valid-token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	require.NotNil(t, principal)
+	assert.Equal(t, "some-user", principal.Subject)
+	assert.Equal(t, "some-issuer", principal.Issuer)
+}
+
+func Test_OidcAuthMiddleware_returns_403_when_token_is_missing_required_scope(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	oidc_provider_mock := &lib.OidcProviderMock{
+		NextValidateTokenResult: map[string]interface{}{"sub": "some-user", "scope": "other-scope"},
+	}
+	middleware := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm", "some-scope")
+	handler := http.HandlerFunc(next_func)
+
+	sut := middleware.GetHandler(handler)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("Authorization", "Bearer valid-token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.JSONEq(t, `{"error":"insufficient_scope","error_description":"token needs scope some-scope"}`, recorder.Body.String())
+	assert.Equal(t, `Bearer realm="some-realm", error="insufficient_scope", error_description="token needs scope some-scope", scope="some-scope"`, recorder.Header().Get("WWW-Authenticate"))
+}
+
+func Test_OidcAuthMiddleware_calls_next_when_token_has_required_scopes(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	oidc_provider_mock := &lib.OidcProviderMock{
+		NextValidateTokenResult: map[string]interface{}{"sub": "some-user", "scope": "other-scope some-scope"},
+	}
+	middleware := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm", "some-scope")
+	handler := http.HandlerFunc(next_func)
+
+	sut := middleware.GetHandler(handler)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("Authorization", "Bearer valid-token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.True(t, called_next)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_OidcAuthMiddleware_returns_401_when_scheme_only_starts_with_bearer(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	oidc_provider_mock := &lib.OidcProviderMock{}
+	sut := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm").GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("Authorization", "Bearerxyz")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.False(t, oidc_provider_mock.ValidateTokenCalled)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, `Bearer realm="some-realm"`, recorder.Header().Get("WWW-Authenticate"))
+}
+
+func Test_OidcAuthMiddleware_accepts_scheme_regardless_of_case(t *testing.T) {
+	for _, auth_header := range []string{"bearer valid-token", "BEARER valid-token", "Bearer  valid-token"} {
+		// Arrange
+		called_next := false
+		next_func := func(w http.ResponseWriter, r *http.Request) {
+			called_next = true
+		}
+
+		oidc_provider_mock := &lib.OidcProviderMock{}
+		sut := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm").GetHandler(http.HandlerFunc(next_func))
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Add("Authorization", auth_header)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.ServeHTTP(recorder, req)
+
+		// Assert
+		assert.True(t, called_next, auth_header)
+		assert.Equal(t, "valid-token", oidc_provider_mock.LastToken)
+	}
+}
+
+func Test_OidcAuthMiddleware_returns_400_when_authorization_header_is_malformed(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	oidc_provider_mock := &lib.OidcProviderMock{}
+	sut := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm").GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("Authorization", "Bearer some-token other-token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, `Bearer realm="some-realm", error="invalid_request", error_description="authorization header is malformed"`, recorder.Header().Get("WWW-Authenticate"))
+	assert.JSONEq(t, `{"error":"invalid_request","error_description":"authorization header is malformed"}`, recorder.Body.String())
+}
+
+func Test_OidcAuthMiddleware_describes_why_token_is_invalid(t *testing.T) {
+	for validation_err, expected := range map[error]string{
+		fmt.Errorf("%w: some-detail", lib.ErrJwtOidcProviderMalformedToken):       "token is malformed",
+		lib.ErrJwtOidcProviderExpiredToken:                                        "token is expired",
+		fmt.Errorf("%w: other-issuer", lib.ErrJwtOidcProviderUnknownIssuer):       "token issuer is not trusted",
+		fmt.Errorf("%w: other-issuer", lib.ErrCompositeOidcProviderUnknownIssuer): "token issuer is not trusted",
+		errors.New("some error"):                                                  "token is invalid",
+	} {
+		// Arrange
+		oidc_provider_mock := &lib.OidcProviderMock{
+			NextValidateTokenError: validation_err,
+		}
+		sut := NewOidcAuthMiddleware(oidc_provider_mock, "some-realm").GetHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Add("Authorization", "Bearer some-token")
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.ServeHTTP(recorder, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, `Bearer realm="some-realm", error="invalid_token", error_description="`+expected+`"`, recorder.Header().Get("WWW-Authenticate"))
+		assert.JSONEq(t, `{"error":"invalid_token","error_description":"`+expected+`"}`, recorder.Body.String())
+	}
+}
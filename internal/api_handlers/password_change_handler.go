@@ -0,0 +1,47 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type PasswordChangeHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.PasswordChangeRequest, app_handlers.PasswordChangeResponse]
+}
+
+func NewPasswordChangeHandler(app_handler app_handlers.AppHandler[app_handlers.PasswordChangeRequest, app_handlers.PasswordChangeResponse]) *PasswordChangeHandler {
+	return &PasswordChangeHandler{
+		app_handler: app_handler,
+	}
+}
+
+func (h *PasswordChangeHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var req app_handlers.PasswordChangeRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	req.ClientIp = clientIp(r)
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrPasswordChangeValidationError) || errors.Is(err, app_handlers.ErrPasswordChangePolicyViolation) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, app_handlers.ErrPasswordChangeForbidden) || errors.Is(err, app_handlers.ErrPasswordChangeInvalidPassword) {
+			HttpError(w, err.Error(), http.StatusForbidden)
+		} else if errors.Is(err, app_handlers.ErrPasswordChangeTooManyAttempts) {
+			setRetryAfter(w, err)
+			HttpError(w, err.Error(), http.StatusTooManyRequests)
+		} else {
+			HttpError(w, app_handlers.ErrPasswordChangeError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
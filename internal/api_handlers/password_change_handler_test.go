@@ -0,0 +1,95 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PasswordChangeHandler_returns_400_on_invalid_json_in_body(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.PasswordChangeHandlerMock{}
+	sut := NewPasswordChangeHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"currentPassword":`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.False(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_PasswordChangeHandler_calls_app_handler_with_passwords_and_client_ip(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.PasswordChangeHandlerMock{
+		NextResponse: &app_handlers.PasswordChangeResponse{},
+	}
+	sut := NewPasswordChangeHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"currentPassword":"some-password","newPassword":"new-password"}`))
+	req.RemoteAddr = "192.0.2.1:1234"
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "some-password", app_handler_mock.LastRequest.CurrentPassword)
+	assert.Equal(t, "new-password", app_handler_mock.LastRequest.NewPassword)
+	assert.Equal(t, "192.0.2.1", app_handler_mock.LastRequest.ClientIp)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_PasswordChangeHandler_maps_app_errors_to_status_codes(t *testing.T) {
+	for app_err, expected := range map[error]int{
+		app_handlers.ErrPasswordChangeValidationError:                                     http.StatusBadRequest,
+		fmt.Errorf("%w: some-rule", app_handlers.ErrPasswordChangePolicyViolation):        http.StatusBadRequest,
+		app_handlers.ErrPasswordChangeForbidden:                                           http.StatusForbidden,
+		app_handlers.ErrPasswordChangeInvalidPassword:                                     http.StatusForbidden,
+		&app_handlers.RetryAfterError{Err: app_handlers.ErrPasswordChangeTooManyAttempts}: http.StatusTooManyRequests,
+		errors.New("some-error"):                                                          http.StatusInternalServerError,
+	} {
+		// Arrange
+		app_handler_mock := &app_handlers.PasswordChangeHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewPasswordChangeHandler(app_handler_mock)
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, req)
+
+		// Assert
+		assert.Equal(t, expected, recorder.Code, app_err.Error())
+	}
+}
+
+func Test_PasswordChangeHandler_sets_retry_after_when_locked_out(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.PasswordChangeHandlerMock{
+		NextError: &app_handlers.RetryAfterError{Err: app_handlers.ErrPasswordChangeTooManyAttempts, RetryAfter: 90 * time.Second},
+	}
+	sut := NewPasswordChangeHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "90", recorder.Header().Get("Retry-After"))
+}
@@ -0,0 +1,45 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type PasswordForgotHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.PasswordForgotRequest, app_handlers.PasswordForgotResponse]
+}
+
+func NewPasswordForgotHandler(app_handler app_handlers.AppHandler[app_handlers.PasswordForgotRequest, app_handlers.PasswordForgotResponse]) *PasswordForgotHandler {
+	return &PasswordForgotHandler{
+		app_handler: app_handler,
+	}
+}
+
+func (h *PasswordForgotHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var req app_handlers.PasswordForgotRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	req.ClientIp = clientIp(r)
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrPasswordForgotValidationError) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, app_handlers.ErrPasswordForgotTooManyAttempts) {
+			setRetryAfter(w, err)
+			HttpError(w, err.Error(), http.StatusTooManyRequests)
+		} else {
+			HttpError(w, "error while sending reset link", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
@@ -0,0 +1,54 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PasswordForgotHandler_calls_app_handler_with_username(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.PasswordForgotHandlerMock{
+		NextResponse: &app_handlers.PasswordForgotResponse{},
+	}
+	sut := NewPasswordForgotHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"username":"some-user"}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "some-user", app_handler_mock.LastRequest.Username)
+	assert.Equal(t, "192.0.2.1", app_handler_mock.LastRequest.ClientIp)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_PasswordForgotHandler_maps_app_errors_to_status_codes(t *testing.T) {
+	for app_err, expected := range map[error]int{
+		app_handlers.ErrPasswordForgotValidationError: http.StatusBadRequest,
+		app_handlers.ErrPasswordForgotTooManyAttempts: http.StatusTooManyRequests,
+		errors.New("some-error"):                      http.StatusInternalServerError,
+	} {
+		// Arrange
+		app_handler_mock := &app_handlers.PasswordForgotHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewPasswordForgotHandler(app_handler_mock)
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, req)
+
+		// Assert
+		assert.Equal(t, expected, recorder.Code)
+	}
+}
@@ -0,0 +1,41 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type PasswordResetHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.PasswordResetRequest, app_handlers.PasswordResetResponse]
+}
+
+func NewPasswordResetHandler(app_handler app_handlers.AppHandler[app_handlers.PasswordResetRequest, app_handlers.PasswordResetResponse]) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		app_handler: app_handler,
+	}
+}
+
+func (h *PasswordResetHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var req app_handlers.PasswordResetRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrPasswordResetValidationError) || errors.Is(err, app_handlers.ErrPasswordResetInvalidToken) || errors.Is(err, app_handlers.ErrPasswordResetPolicyViolation) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else {
+			HttpError(w, app_handlers.ErrPasswordResetError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
@@ -0,0 +1,72 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PasswordResetHandler_returns_400_on_invalid_json_in_body(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.PasswordResetHandlerMock{}
+	sut := NewPasswordResetHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"token":`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.False(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_PasswordResetHandler_calls_app_handler_with_token_and_password(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.PasswordResetHandlerMock{
+		NextResponse: &app_handlers.PasswordResetResponse{},
+	}
+	sut := NewPasswordResetHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"token":"some-token","newPassword":"new-password"}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "some-token", app_handler_mock.LastRequest.Token)
+	assert.Equal(t, "new-password", app_handler_mock.LastRequest.NewPassword)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_PasswordResetHandler_maps_app_errors_to_status_codes(t *testing.T) {
+	for app_err, expected := range map[error]int{
+		app_handlers.ErrPasswordResetValidationError:                              http.StatusBadRequest,
+		app_handlers.ErrPasswordResetInvalidToken:                                 http.StatusBadRequest,
+		fmt.Errorf("%w: some-rule", app_handlers.ErrPasswordResetPolicyViolation): http.StatusBadRequest,
+		errors.New("some-error"):                                                  http.StatusInternalServerError,
+	} {
+		// Arrange
+		app_handler_mock := &app_handlers.PasswordResetHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewPasswordResetHandler(app_handler_mock)
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, req)
+
+		// Assert
+		assert.Equal(t, expected, recorder.Code)
+	}
+}
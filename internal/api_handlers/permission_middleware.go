@@ -0,0 +1,44 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type PermissionMiddleware struct {
+	role_permissions     *lib.RolePermissions
+	required_permissions []string
+}
+
+// NewPermissionMiddleware only lets callers through who hold all of the required
+// permissions, users through their roles and other callers through their scopes.
+// It reads the principal, so it has to run after one of the auth middlewares.
+func NewPermissionMiddleware(role_permissions *lib.RolePermissions, required_permissions ...string) AuthMiddleware {
+	return &PermissionMiddleware{
+		role_permissions:     role_permissions,
+		required_permissions: required_permissions,
+	}
+}
+
+func (m *PermissionMiddleware) GetHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := lib.PrincipalFromContext(r.Context())
+		if !ok {
+			log.Println("principal missing, the permission middleware needs an authenticated caller")
+			HttpErrorWithDescription(w, "unauthorized", "authentication is missing", http.StatusUnauthorized)
+			return
+		}
+
+		for _, permission := range m.required_permissions {
+			if !m.role_permissions.Grants(principal, permission) {
+				log.Printf("permission denied, %s with roles %v is missing permission %s\n", principal.Subject, principal.Roles, permission)
+				HttpErrorWithDescription(w, "insufficient_permission", fmt.Sprintf("caller needs permission %s", permission), http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
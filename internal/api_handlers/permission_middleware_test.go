@@ -0,0 +1,111 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRolePermissions(t *testing.T) *lib.RolePermissions {
+	role_permissions, err := lib.NewRolePermissions("some-issuer", map[string][]string{
+		"analyst": {"sum:compute"},
+		"admin":   {"sum:compute", "users:manage"},
+	})
+	require.Nil(t, err)
+
+	return role_permissions
+}
+
+func Test_PermissionMiddleware_returns_401_without_principal(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	sut := NewPermissionMiddleware(newTestRolePermissions(t), "sum:compute").GetHandler(http.HandlerFunc(next_func))
+	req := httptest.NewRequest("GET", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.JSONEq(t, `{"error":"unauthorized","error_description":"authentication is missing"}`, recorder.Body.String())
+}
+
+func Test_PermissionMiddleware_returns_403_when_roles_lack_permission(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	sut := NewPermissionMiddleware(newTestRolePermissions(t), "users:manage").GetHandler(http.HandlerFunc(next_func))
+	principal := &lib.Principal{Subject: "some-user", Issuer: "some-issuer", Scopes: []string{"users:manage"}, Roles: []string{"analyst"}}
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(lib.WithPrincipal(req.Context(), principal))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.False(t, called_next)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.JSONEq(t, `{"error":"insufficient_permission","error_description":"caller needs permission users:manage"}`, recorder.Body.String())
+	assert.Contains(t, recorder.Body.String(), "insufficient_permission")
+}
+
+func Test_PermissionMiddleware_calls_next_when_roles_grant_permissions(t *testing.T) {
+	// Arrange
+	called_next := false
+	next_func := func(w http.ResponseWriter, r *http.Request) {
+		called_next = true
+	}
+
+	sut := NewPermissionMiddleware(newTestRolePermissions(t), "sum:compute", "users:manage").GetHandler(http.HandlerFunc(next_func))
+	principal := &lib.Principal{Subject: "some-user", Issuer: "some-issuer", Roles: []string{"analyst", "admin"}}
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(lib.WithPrincipal(req.Context(), principal))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.True(t, called_next)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_PermissionMiddleware_checks_scopes_of_callers_without_roles(t *testing.T) {
+	for _, scopes := range [][]string{{"sum:compute"}, {"other-scope"}} {
+		// Arrange
+		called_next := false
+		next_func := func(w http.ResponseWriter, r *http.Request) {
+			called_next = true
+		}
+
+		sut := NewPermissionMiddleware(newTestRolePermissions(t), "sum:compute").GetHandler(http.HandlerFunc(next_func))
+		principal := &lib.Principal{Subject: "some-client", Scopes: scopes}
+		req := httptest.NewRequest("GET", "/", nil)
+		req = req.WithContext(lib.WithPrincipal(req.Context(), principal))
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.ServeHTTP(recorder, req)
+
+		// Assert
+		granted := scopes[0] == "sum:compute"
+		assert.Equal(t, granted, called_next)
+		if !granted {
+			assert.Equal(t, http.StatusForbidden, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type RefreshHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.RefreshRequest, app_handlers.AuthResponse]
+}
+
+func NewRefreshHandler(app_handler app_handlers.AppHandler[app_handlers.RefreshRequest, app_handlers.AuthResponse]) *RefreshHandler {
+	return &RefreshHandler{
+		app_handler: app_handler,
+	}
+}
+
+func (h *RefreshHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var req app_handlers.RefreshRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrRefreshValidationError) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, app_handlers.ErrRefreshInvalidToken) {
+			HttpError(w, err.Error(), http.StatusUnauthorized)
+		} else {
+			HttpError(w, "error while generating token", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
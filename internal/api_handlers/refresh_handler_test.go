@@ -0,0 +1,119 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RefreshHandler_returns_400_on_invalid_json_in_body(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.RefreshHandlerMock{}
+	sut := NewRefreshHandler(app_handler_mock)
+
+	body := strings.NewReader("invalid-json")
+	req := httptest.NewRequest("POST", "/", body)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.False(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_RefreshHandler_calls_app_handler_with_specified_refresh_token(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.RefreshHandlerMock{}
+	sut := NewRefreshHandler(app_handler_mock)
+
+	body := strings.NewReader(`{"refreshToken":"some-refresh-token"}`)
+	req := httptest.NewRequest("POST", "/", body)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.True(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, "some-refresh-token", app_handler_mock.LastRequest.RefreshToken)
+}
+
+func Test_RefreshHandler_returns_400_on_validation_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.RefreshHandlerMock{
+		NextError: app_handlers.ErrRefreshValidationError,
+	}
+	sut := NewRefreshHandler(app_handler_mock)
+
+	body := strings.NewReader(`{}`)
+	req := httptest.NewRequest("POST", "/", body)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_RefreshHandler_returns_401_on_invalid_refresh_token(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.RefreshHandlerMock{
+		NextError: app_handlers.ErrRefreshInvalidToken,
+	}
+	sut := NewRefreshHandler(app_handler_mock)
+
+	body := strings.NewReader(`{"refreshToken":"some-refresh-token"}`)
+	req := httptest.NewRequest("POST", "/", body)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func Test_RefreshHandler_returns_500_on_token_generation_failure(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.RefreshHandlerMock{
+		NextError: app_handlers.ErrRefreshTokenGenerationError,
+	}
+	sut := NewRefreshHandler(app_handler_mock)
+
+	body := strings.NewReader(`{"refreshToken":"some-refresh-token"}`)
+	req := httptest.NewRequest("POST", "/", body)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func Test_RefreshHandler_returns_new_tokens(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.RefreshHandlerMock{
+		NextResponse: &app_handlers.AuthResponse{Token: "some-token", RefreshToken: "next-refresh-token"},
+	}
+	sut := NewRefreshHandler(app_handler_mock)
+
+	body := strings.NewReader(`{"refreshToken":"some-refresh-token"}`)
+	req := httptest.NewRequest("POST", "/", body)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `{"token":"some-token","refreshToken":"next-refresh-token"}`, recorder.Body.String())
+}
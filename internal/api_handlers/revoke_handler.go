@@ -0,0 +1,50 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"coding_exercise/internal/lib"
+	"errors"
+	"net/http"
+)
+
+type RevokeHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.RevokeRequest, app_handlers.RevokeResponse]
+}
+
+func NewRevokeHandler(app_handler app_handlers.AppHandler[app_handlers.RevokeRequest, app_handlers.RevokeResponse]) *RevokeHandler {
+	return &RevokeHandler{
+		app_handler: app_handler,
+	}
+}
+
+// Handle reads the form encoded body defined by RFC 7009.
+func (h *RevokeHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	// the client auth middleware puts the client on the context
+	req := app_handlers.RevokeRequest{
+		Token:         r.PostForm.Get("token"),
+		TokenTypeHint: r.PostForm.Get("token_type_hint"),
+	}
+
+	if principal, ok := lib.PrincipalFromContext(r.Context()); ok {
+		req.ClientId = principal.Subject
+	}
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrRevokeValidationError) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else {
+			HttpError(w, "error while revoking token", http.StatusServiceUnavailable)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
@@ -0,0 +1,92 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"coding_exercise/internal/lib"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFormRequest(body string) *http.Request {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func Test_RevokeHandler_calls_app_handler_with_token_and_hint(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.RevokeHandlerMock{
+		NextResponse: &app_handlers.RevokeResponse{},
+	}
+	sut := NewRevokeHandler(app_handler_mock)
+
+	req := newFormRequest("token=some-token&token_type_hint=refresh_token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.True(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, "some-token", app_handler_mock.LastRequest.Token)
+	assert.Equal(t, "refresh_token", app_handler_mock.LastRequest.TokenTypeHint)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_RevokeHandler_calls_app_handler_with_authenticated_client(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.RevokeHandlerMock{
+		NextResponse: &app_handlers.RevokeResponse{},
+	}
+	sut := NewRevokeHandler(app_handler_mock)
+
+	req := newFormRequest("token=some-token")
+	req = req.WithContext(lib.WithPrincipal(req.Context(), (&lib.Client{Id: "some-client"}).Principal()))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.True(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, "some-client", app_handler_mock.LastRequest.ClientId)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_RevokeHandler_returns_400_on_validation_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.RevokeHandlerMock{
+		NextError: app_handlers.ErrRevokeValidationError,
+	}
+	sut := NewRevokeHandler(app_handler_mock)
+
+	req := newFormRequest("")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_RevokeHandler_returns_503_on_revocation_failure(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.RevokeHandlerMock{
+		NextError: app_handlers.ErrRevokeError,
+	}
+	sut := NewRevokeHandler(app_handler_mock)
+
+	req := newFormRequest("token=some-token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
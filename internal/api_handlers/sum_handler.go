@@ -25,7 +25,7 @@ func (h *SumHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.app_handler.Handle(req)
+	res, err := h.app_handler.Handle(r.Context(), req)
 
 	if err != nil {
 		log.Printf("unable to handle sum request: %s\n", err)
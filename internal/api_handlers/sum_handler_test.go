@@ -2,6 +2,7 @@ package api_handlers
 
 import (
 	"coding_exercise/internal/app_handlers"
+	"coding_exercise/internal/lib"
 	"encoding/json"
 	"errors"
 	"io"
@@ -113,3 +114,24 @@ func Test_SumHandler_returns_internalservererror_on_sumhandler_error(t *testing.
 	require.Nil(t, err)
 	assert.Contains(t, string(res), `{"error":`)
 }
+
+func Test_SumHandler_passes_request_context_to_app_handler(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.SumHandlerMock{
+		NextResponse: &app_handlers.SumResponse{},
+	}
+	sut := NewSumHandler(app_handler_mock)
+
+	body := strings.NewReader("[1]")
+	req := httptest.NewRequest("POST", "/", body)
+	req = req.WithContext(lib.WithPrincipal(req.Context(), &lib.Principal{Subject: "some-user"}))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	principal, ok := lib.PrincipalFromContext(app_handler_mock.LastContext)
+	require.True(t, ok)
+	assert.Equal(t, "some-user", principal.Subject)
+}
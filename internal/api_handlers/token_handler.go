@@ -0,0 +1,70 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+)
+
+type TokenHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.TokenRequest, app_handlers.TokenResponse]
+}
+
+func NewTokenHandler(app_handler app_handlers.AppHandler[app_handlers.TokenRequest, app_handlers.TokenResponse]) *TokenHandler {
+	return &TokenHandler{
+		app_handler: app_handler,
+	}
+}
+
+// Handle reads the form encoded token request of RFC 6749, clients authenticate
+// with basic auth or with client_id and client_secret in the body.
+func (h *TokenHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		HttpError(w, app_handlers.ErrTokenInvalidRequest.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := app_handlers.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		ClientId:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		Scope:        r.PostForm.Get("scope"),
+		Code:         r.PostForm.Get("code"),
+		RedirectUri:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+	}
+
+	client_id, client_secret, basic_auth := r.BasicAuth()
+	if basic_auth {
+		req.ClientId = unescapeCredential(client_id)
+		req.ClientSecret = unescapeCredential(client_secret)
+	}
+
+	// token responses must not be cached
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrTokenInvalidClient) {
+			if basic_auth {
+				w.Header().Set("WWW-Authenticate", `Basic realm="client"`)
+			}
+
+			HttpError(w, err.Error(), http.StatusUnauthorized)
+		} else if errors.Is(err, app_handlers.ErrTokenInvalidRequest) ||
+			errors.Is(err, app_handlers.ErrTokenInvalidGrant) ||
+			errors.Is(err, app_handlers.ErrTokenUnsupportedGrantType) ||
+			errors.Is(err, app_handlers.ErrTokenInvalidScope) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else {
+			HttpError(w, app_handlers.ErrTokenServerError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
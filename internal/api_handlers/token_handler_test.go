@@ -0,0 +1,150 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TokenHandler_calls_app_handler_with_form_values(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.TokenHandlerMock{
+		NextResponse: &app_handlers.TokenResponse{AccessToken: "some-token", TokenType: "Bearer", ExpiresIn: 3600},
+	}
+	sut := NewTokenHandler(app_handler_mock)
+
+	req := newFormRequest("grant_type=client_credentials&client_id=some-client&client_secret=some-secret&scope=some-scope")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, app_handlers.TokenRequest{
+		GrantType:    "client_credentials",
+		ClientId:     "some-client",
+		ClientSecret: "some-secret",
+		Scope:        "some-scope",
+	}, app_handler_mock.LastRequest)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"access_token":"some-token","token_type":"Bearer","expires_in":3600}`, recorder.Body.String())
+}
+
+func Test_TokenHandler_reads_client_credentials_from_basic_auth(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.TokenHandlerMock{
+		NextResponse: &app_handlers.TokenResponse{},
+	}
+	sut := NewTokenHandler(app_handler_mock)
+
+	req := newFormRequest("grant_type=client_credentials")
+	req.SetBasicAuth("some-client", "some-secret")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "some-client", app_handler_mock.LastRequest.ClientId)
+	assert.Equal(t, "some-secret", app_handler_mock.LastRequest.ClientSecret)
+}
+
+func Test_TokenHandler_reads_refresh_token(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.TokenHandlerMock{
+		NextResponse: &app_handlers.TokenResponse{},
+	}
+	sut := NewTokenHandler(app_handler_mock)
+
+	req := newFormRequest("grant_type=refresh_token&client_id=some-client&refresh_token=some-refresh-token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "refresh_token", app_handler_mock.LastRequest.GrantType)
+	assert.Equal(t, "some-refresh-token", app_handler_mock.LastRequest.RefreshToken)
+}
+
+func Test_TokenHandler_returns_401_on_invalid_client(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.TokenHandlerMock{
+		NextError: app_handlers.ErrTokenInvalidClient,
+	}
+	sut := NewTokenHandler(app_handler_mock)
+
+	req := newFormRequest("grant_type=client_credentials")
+	req.SetBasicAuth("some-client", "wrong-secret")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, `Basic realm="client"`, recorder.Header().Get("WWW-Authenticate"))
+	assert.JSONEq(t, `{"error":"invalid_client"}`, recorder.Body.String())
+}
+
+func Test_TokenHandler_returns_400_on_unsupported_grant_type(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.TokenHandlerMock{
+		NextError: app_handlers.ErrTokenUnsupportedGrantType,
+	}
+	sut := NewTokenHandler(app_handler_mock)
+
+	req := newFormRequest("grant_type=password")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.JSONEq(t, `{"error":"unsupported_grant_type"}`, recorder.Body.String())
+}
+
+func Test_TokenHandler_returns_400_on_invalid_grant(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.TokenHandlerMock{
+		NextError: app_handlers.ErrTokenInvalidGrant,
+	}
+	sut := NewTokenHandler(app_handler_mock)
+
+	req := newFormRequest("grant_type=authorization_code&client_id=some-client&code=some-code&redirect_uri=http%3A%2F%2Flocalhost%2Fcallback&code_verifier=some-verifier")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "some-code", app_handler_mock.LastRequest.Code)
+	assert.Equal(t, "http://localhost/callback", app_handler_mock.LastRequest.RedirectUri)
+	assert.Equal(t, "some-verifier", app_handler_mock.LastRequest.CodeVerifier)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.JSONEq(t, `{"error":"invalid_grant"}`, recorder.Body.String())
+}
+
+func Test_TokenHandler_returns_500_on_unknown_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.TokenHandlerMock{
+		NextError: errors.New("some-error"),
+	}
+	sut := NewTokenHandler(app_handler_mock)
+
+	req := newFormRequest("grant_type=client_credentials")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.JSONEq(t, `{"error":"server_error"}`, recorder.Body.String())
+}
@@ -0,0 +1,43 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+type UserCreateHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.UserCreateRequest, app_handlers.UserResponse]
+}
+
+func NewUserCreateHandler(app_handler app_handlers.AppHandler[app_handlers.UserCreateRequest, app_handlers.UserResponse]) *UserCreateHandler {
+	return &UserCreateHandler{
+		app_handler: app_handler,
+	}
+}
+
+func (h *UserCreateHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var req app_handlers.UserCreateRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrUserCreateValidationError) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, app_handlers.ErrUserCreateDuplicateUsername) {
+			HttpError(w, err.Error(), http.StatusConflict)
+		} else {
+			HttpError(w, app_handlers.ErrUserCreateError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
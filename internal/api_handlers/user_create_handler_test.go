@@ -0,0 +1,73 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserCreateHandler_returns_400_on_invalid_json_in_body(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.UserCreateHandlerMock{}
+	sut := NewUserCreateHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"username":`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.False(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_UserCreateHandler_calls_app_handler_and_returns_user(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.UserCreateHandlerMock{
+		NextResponse: &app_handlers.UserResponse{Id: "some-id", Username: "some-user"},
+	}
+	sut := NewUserCreateHandler(app_handler_mock)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"username":"some-user","password":"some-password","roles":["some-role"],"claims":{"name":"Some User"}}`))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, "some-user", app_handler_mock.LastRequest.Username)
+	assert.Equal(t, "some-password", app_handler_mock.LastRequest.Password)
+	assert.Equal(t, []string{"some-role"}, app_handler_mock.LastRequest.Roles)
+	assert.Equal(t, "Some User", app_handler_mock.LastRequest.Claims["name"])
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"id":"some-id"`)
+}
+
+func Test_UserCreateHandler_maps_app_errors_to_status_codes(t *testing.T) {
+	for app_err, expected := range map[error]int{
+		app_handlers.ErrUserCreateValidationError:   http.StatusBadRequest,
+		app_handlers.ErrUserCreateDuplicateUsername: http.StatusConflict,
+		errors.New("some-error"):                    http.StatusInternalServerError,
+	} {
+		// Arrange
+		app_handler_mock := &app_handlers.UserCreateHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewUserCreateHandler(app_handler_mock)
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, req)
+
+		// Assert
+		assert.Equal(t, expected, recorder.Code)
+	}
+}
@@ -0,0 +1,42 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type UserDeleteHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.UserDeleteRequest, app_handlers.UserDeleteResponse]
+}
+
+func NewUserDeleteHandler(app_handler app_handlers.AppHandler[app_handlers.UserDeleteRequest, app_handlers.UserDeleteResponse]) *UserDeleteHandler {
+	return &UserDeleteHandler{
+		app_handler: app_handler,
+	}
+}
+
+// Handle reads the id of the user from the {id} variable of the route.
+func (h *UserDeleteHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	req := app_handlers.UserDeleteRequest{
+		Id: mux.Vars(r)["id"],
+	}
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrUserDeleteValidationError) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, app_handlers.ErrUserDeleteUnknownUser) {
+			HttpError(w, err.Error(), http.StatusNotFound)
+		} else {
+			HttpError(w, app_handlers.ErrUserDeleteError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
@@ -0,0 +1,48 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserDeleteHandler_calls_app_handler_with_id_of_route(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.UserDeleteHandlerMock{
+		NextResponse: &app_handlers.UserDeleteResponse{},
+	}
+	sut := NewUserDeleteHandler(app_handler_mock)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, newUserRequest("DELETE", "some-id", ""))
+
+	// Assert
+	assert.Equal(t, "some-id", app_handler_mock.LastRequest.Id)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_UserDeleteHandler_maps_app_errors_to_status_codes(t *testing.T) {
+	for app_err, expected := range map[error]int{
+		app_handlers.ErrUserDeleteValidationError: http.StatusBadRequest,
+		app_handlers.ErrUserDeleteUnknownUser:     http.StatusNotFound,
+		errors.New("some-error"):                  http.StatusInternalServerError,
+	} {
+		// Arrange
+		app_handler_mock := &app_handlers.UserDeleteHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewUserDeleteHandler(app_handler_mock)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, newUserRequest("DELETE", "some-id", ""))
+
+		// Assert
+		assert.Equal(t, expected, recorder.Code)
+	}
+}
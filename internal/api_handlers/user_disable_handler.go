@@ -0,0 +1,47 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type UserDisableHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.UserDisableRequest, app_handlers.UserDisableResponse]
+	disabled    bool
+}
+
+// NewUserDisableHandler disables the user of the route, or enables it again when
+// disabled is false.
+func NewUserDisableHandler(app_handler app_handlers.AppHandler[app_handlers.UserDisableRequest, app_handlers.UserDisableResponse], disabled bool) *UserDisableHandler {
+	return &UserDisableHandler{
+		app_handler: app_handler,
+		disabled:    disabled,
+	}
+}
+
+// Handle reads the id of the user from the {id} variable of the route.
+func (h *UserDisableHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	req := app_handlers.UserDisableRequest{
+		Id:       mux.Vars(r)["id"],
+		Disabled: h.disabled,
+	}
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrUserDisableValidationError) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, app_handlers.ErrUserDisableUnknownUser) {
+			HttpError(w, err.Error(), http.StatusNotFound)
+		} else {
+			HttpError(w, app_handlers.ErrUserDisableError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
@@ -0,0 +1,58 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func newUserRequest(method string, id string, body string) *http.Request {
+	req := httptest.NewRequest(method, "/"+id, strings.NewReader(body))
+	return mux.SetURLVars(req, map[string]string{"id": id})
+}
+
+func Test_UserDisableHandler_calls_app_handler_with_id_of_route(t *testing.T) {
+	for _, disabled := range []bool{true, false} {
+		// Arrange
+		app_handler_mock := &app_handlers.UserDisableHandlerMock{
+			NextResponse: &app_handlers.UserDisableResponse{},
+		}
+		sut := NewUserDisableHandler(app_handler_mock, disabled)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, newUserRequest("POST", "some-id", ""))
+
+		// Assert
+		assert.Equal(t, "some-id", app_handler_mock.LastRequest.Id)
+		assert.Equal(t, disabled, app_handler_mock.LastRequest.Disabled)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	}
+}
+
+func Test_UserDisableHandler_maps_app_errors_to_status_codes(t *testing.T) {
+	for app_err, expected := range map[error]int{
+		app_handlers.ErrUserDisableValidationError: http.StatusBadRequest,
+		app_handlers.ErrUserDisableUnknownUser:     http.StatusNotFound,
+		errors.New("some-error"):                   http.StatusInternalServerError,
+	} {
+		// Arrange
+		app_handler_mock := &app_handlers.UserDisableHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewUserDisableHandler(app_handler_mock, true)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, newUserRequest("POST", "some-id", ""))
+
+		// Assert
+		assert.Equal(t, expected, recorder.Code)
+	}
+}
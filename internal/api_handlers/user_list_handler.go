@@ -0,0 +1,27 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"net/http"
+)
+
+type UserListHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.UserListRequest, app_handlers.UserListResponse]
+}
+
+func NewUserListHandler(app_handler app_handlers.AppHandler[app_handlers.UserListRequest, app_handlers.UserListResponse]) *UserListHandler {
+	return &UserListHandler{
+		app_handler: app_handler,
+	}
+}
+
+func (h *UserListHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	res, err := h.app_handler.Handle(r.Context(), app_handlers.UserListRequest{})
+
+	if err != nil {
+		HttpError(w, app_handlers.ErrUserListError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	HttpSuccess(w, res)
+}
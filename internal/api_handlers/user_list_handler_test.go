@@ -0,0 +1,50 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserListHandler_returns_users(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.UserListHandlerMock{
+		NextResponse: &app_handlers.UserListResponse{
+			Users: []app_handlers.UserResponse{{Id: "some-id", Username: "some-user"}},
+		},
+	}
+	sut := NewUserListHandler(app_handler_mock)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.True(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"username":"some-user"`)
+	assert.NotContains(t, recorder.Body.String(), "passwordHash")
+}
+
+func Test_UserListHandler_returns_500_on_error(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.UserListHandlerMock{
+		NextError: errors.New("some-error"),
+	}
+	sut := NewUserListHandler(app_handler_mock)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
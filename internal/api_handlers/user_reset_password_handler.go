@@ -0,0 +1,49 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type UserResetPasswordHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.UserResetPasswordRequest, app_handlers.UserResetPasswordResponse]
+}
+
+func NewUserResetPasswordHandler(app_handler app_handlers.AppHandler[app_handlers.UserResetPasswordRequest, app_handlers.UserResetPasswordResponse]) *UserResetPasswordHandler {
+	return &UserResetPasswordHandler{
+		app_handler: app_handler,
+	}
+}
+
+// Handle reads the new password from the body and the id of the user from the
+// {id} variable of the route.
+func (h *UserResetPasswordHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var req app_handlers.UserResetPasswordRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	req.Id = mux.Vars(r)["id"]
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrUserResetPasswordValidationError) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, app_handlers.ErrUserResetPasswordUnknownUser) {
+			HttpError(w, err.Error(), http.StatusNotFound)
+		} else {
+			HttpError(w, app_handlers.ErrUserResetPasswordError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
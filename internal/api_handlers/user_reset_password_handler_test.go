@@ -0,0 +1,63 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserResetPasswordHandler_returns_400_on_invalid_json_in_body(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.UserResetPasswordHandlerMock{}
+	sut := NewUserResetPasswordHandler(app_handler_mock)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, newUserRequest("POST", "some-id", `{"password":`))
+
+	// Assert
+	assert.False(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_UserResetPasswordHandler_calls_app_handler_with_id_of_route(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.UserResetPasswordHandlerMock{
+		NextResponse: &app_handlers.UserResetPasswordResponse{},
+	}
+	sut := NewUserResetPasswordHandler(app_handler_mock)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, newUserRequest("POST", "some-id", `{"id":"other-id","password":"new-password"}`))
+
+	// Assert
+	assert.Equal(t, "some-id", app_handler_mock.LastRequest.Id)
+	assert.Equal(t, "new-password", app_handler_mock.LastRequest.Password)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_UserResetPasswordHandler_maps_app_errors_to_status_codes(t *testing.T) {
+	for app_err, expected := range map[error]int{
+		app_handlers.ErrUserResetPasswordValidationError: http.StatusBadRequest,
+		app_handlers.ErrUserResetPasswordUnknownUser:     http.StatusNotFound,
+		errors.New("some-error"):                         http.StatusInternalServerError,
+	} {
+		// Arrange
+		app_handler_mock := &app_handlers.UserResetPasswordHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewUserResetPasswordHandler(app_handler_mock)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, newUserRequest("POST", "some-id", `{"password":"new-password"}`))
+
+		// Assert
+		assert.Equal(t, expected, recorder.Code)
+	}
+}
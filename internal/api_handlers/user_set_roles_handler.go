@@ -0,0 +1,49 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type UserSetRolesHandler struct {
+	app_handler app_handlers.AppHandler[app_handlers.UserSetRolesRequest, app_handlers.UserSetRolesResponse]
+}
+
+func NewUserSetRolesHandler(app_handler app_handlers.AppHandler[app_handlers.UserSetRolesRequest, app_handlers.UserSetRolesResponse]) *UserSetRolesHandler {
+	return &UserSetRolesHandler{
+		app_handler: app_handler,
+	}
+}
+
+// Handle reads the new roles from the body and the id of the user from the
+// {id} variable of the route.
+func (h *UserSetRolesHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	var req app_handlers.UserSetRolesRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		HttpError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	req.Id = mux.Vars(r)["id"]
+
+	res, err := h.app_handler.Handle(r.Context(), req)
+
+	if err != nil {
+		if errors.Is(err, app_handlers.ErrUserSetRolesValidationError) {
+			HttpError(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, app_handlers.ErrUserSetRolesUnknownUser) {
+			HttpError(w, err.Error(), http.StatusNotFound)
+		} else {
+			HttpError(w, app_handlers.ErrUserSetRolesError.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	HttpSuccess(w, res)
+}
@@ -0,0 +1,63 @@
+package api_handlers
+
+import (
+	"coding_exercise/internal/app_handlers"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserSetRolesHandler_returns_400_on_invalid_json_in_body(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.UserSetRolesHandlerMock{}
+	sut := NewUserSetRolesHandler(app_handler_mock)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, newUserRequest("PUT", "some-id", `{"roles":`))
+
+	// Assert
+	assert.False(t, app_handler_mock.HandleCalled)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func Test_UserSetRolesHandler_calls_app_handler_with_id_of_route(t *testing.T) {
+	// Arrange
+	app_handler_mock := &app_handlers.UserSetRolesHandlerMock{
+		NextResponse: &app_handlers.UserSetRolesResponse{},
+	}
+	sut := NewUserSetRolesHandler(app_handler_mock)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	sut.Handle(recorder, newUserRequest("PUT", "some-id", `{"id":"other-id","roles":["some-role"]}`))
+
+	// Assert
+	assert.Equal(t, "some-id", app_handler_mock.LastRequest.Id)
+	assert.Equal(t, []string{"some-role"}, app_handler_mock.LastRequest.Roles)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func Test_UserSetRolesHandler_maps_app_errors_to_status_codes(t *testing.T) {
+	for app_err, expected := range map[error]int{
+		app_handlers.ErrUserSetRolesValidationError: http.StatusBadRequest,
+		app_handlers.ErrUserSetRolesUnknownUser:     http.StatusNotFound,
+		errors.New("some-error"):                    http.StatusInternalServerError,
+	} {
+		// Arrange
+		app_handler_mock := &app_handlers.UserSetRolesHandlerMock{
+			NextError: app_err,
+		}
+		sut := NewUserSetRolesHandler(app_handler_mock)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		sut.Handle(recorder, newUserRequest("PUT", "some-id", `{"roles":["some-role"]}`))
+
+		// Assert
+		assert.Equal(t, expected, recorder.Code)
+	}
+}
@@ -0,0 +1,70 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+var (
+	ErrApiKeyIssueValidationError = errors.New("owner is empty or expiresIn is negative")
+	ErrApiKeyIssueError           = errors.New("error while issuing api key")
+)
+
+type ApiKeyIssueRequest struct {
+	Owner     string       `json:"owner"`
+	Scopes    []string     `json:"scopes"`
+	ExpiresIn lib.Duration `json:"expiresIn"`
+}
+
+// ApiKeyIssueResponse contains the key, it is only returned once.
+type ApiKeyIssueResponse struct {
+	Id        string    `json:"id"`
+	Key       string    `json:"key"`
+	Owner     string    `json:"owner"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ApiKeyIssueHandler issues api keys for an owner, it is meant for admins.
+type ApiKeyIssueHandler struct {
+	apiKeyStore lib.ApiKeyStore
+}
+
+func NewApiKeyIssueHandler(apiKeyStore lib.ApiKeyStore) AppHandler[ApiKeyIssueRequest, ApiKeyIssueResponse] {
+	return &ApiKeyIssueHandler{
+		apiKeyStore: apiKeyStore,
+	}
+}
+
+func (h *ApiKeyIssueHandler) Handle(ctx context.Context, request ApiKeyIssueRequest) (*ApiKeyIssueResponse, error) {
+	request.Owner = strings.TrimSpace(request.Owner)
+	if request.Owner == "" || request.ExpiresIn < 0 {
+		return nil, ErrApiKeyIssueValidationError
+	}
+
+	apiKey, key, err := h.apiKeyStore.Issue(request.Owner, request.Scopes, time.Duration(request.ExpiresIn))
+	if err != nil {
+		if errors.Is(err, lib.ErrApiKeyStoreValidationError) {
+			return nil, ErrApiKeyIssueValidationError
+		}
+
+		log.Printf("error while issuing api key for %s: %s", request.Owner, err)
+		return nil, ErrApiKeyIssueError
+	}
+
+	if principal, ok := lib.PrincipalFromContext(ctx); ok {
+		log.Printf("%s issued api key %s for %s", principal.Subject, apiKey.Id, apiKey.Owner)
+	}
+
+	return &ApiKeyIssueResponse{
+		Id:        apiKey.Id,
+		Key:       key,
+		Owner:     apiKey.Owner,
+		Scopes:    apiKey.Scopes,
+		ExpiresAt: apiKey.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type ApiKeyIssueHandlerMock struct {
+	HandleCalled bool
+	LastRequest  ApiKeyIssueRequest
+	NextResponse *ApiKeyIssueResponse
+	NextError    error
+}
+
+func (m *ApiKeyIssueHandlerMock) Handle(ctx context.Context, request ApiKeyIssueRequest) (*ApiKeyIssueResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
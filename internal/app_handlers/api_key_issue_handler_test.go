@@ -0,0 +1,67 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ApiKeyIssueHandler_Handle_returns_error_on_empty_owner(t *testing.T) {
+	// Arrange
+	api_key_store_mock := lib.ApiKeyStoreMock{}
+	sut := NewApiKeyIssueHandler(&api_key_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), ApiKeyIssueRequest{Owner: " "})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrApiKeyIssueValidationError))
+	assert.False(t, api_key_store_mock.IssueCalled)
+}
+
+func Test_ApiKeyIssueHandler_Handle_returns_key_for_owner(t *testing.T) {
+	// Arrange
+	expires_at := time.Now().Add(time.Hour)
+	api_key_store_mock := lib.ApiKeyStoreMock{
+		NextIssueResult: &lib.ApiKey{Id: "some-id", Owner: "some-owner", Scopes: []string{"some-scope"}, ExpiresAt: expires_at},
+		NextIssueKey:    "some-key",
+	}
+	sut := NewApiKeyIssueHandler(&api_key_store_mock)
+	req := ApiKeyIssueRequest{
+		Owner:     "some-owner",
+		Scopes:    []string{"some-scope"},
+		ExpiresIn: lib.Duration(time.Hour),
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "some-owner", api_key_store_mock.LastOwner)
+	assert.Equal(t, []string{"some-scope"}, api_key_store_mock.LastScopes)
+	assert.Equal(t, time.Hour, api_key_store_mock.LastLifetime)
+	assert.Equal(t, &ApiKeyIssueResponse{Id: "some-id", Key: "some-key", Owner: "some-owner", Scopes: []string{"some-scope"}, ExpiresAt: expires_at}, res)
+}
+
+func Test_ApiKeyIssueHandler_Handle_returns_error_when_issuing_fails(t *testing.T) {
+	// Arrange
+	api_key_store_mock := lib.ApiKeyStoreMock{
+		NextIssueError: errors.New("some-error"),
+	}
+	sut := NewApiKeyIssueHandler(&api_key_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), ApiKeyIssueRequest{Owner: "some-owner"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrApiKeyIssueError))
+}
@@ -0,0 +1,54 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"strings"
+)
+
+var (
+	ErrApiKeyRevokeValidationError = errors.New("api key id is empty")
+	ErrApiKeyRevokeUnknownKey      = errors.New("unknown api key")
+	ErrApiKeyRevokeError           = errors.New("error while revoking api key")
+)
+
+type ApiKeyRevokeRequest struct {
+	Id string
+}
+
+type ApiKeyRevokeResponse struct{}
+
+// ApiKeyRevokeHandler revokes api keys by their id, it is meant for admins.
+type ApiKeyRevokeHandler struct {
+	apiKeyStore lib.ApiKeyStore
+}
+
+func NewApiKeyRevokeHandler(apiKeyStore lib.ApiKeyStore) AppHandler[ApiKeyRevokeRequest, ApiKeyRevokeResponse] {
+	return &ApiKeyRevokeHandler{
+		apiKeyStore: apiKeyStore,
+	}
+}
+
+func (h *ApiKeyRevokeHandler) Handle(ctx context.Context, request ApiKeyRevokeRequest) (*ApiKeyRevokeResponse, error) {
+	request.Id = strings.TrimSpace(request.Id)
+	if request.Id == "" {
+		return nil, ErrApiKeyRevokeValidationError
+	}
+
+	if err := h.apiKeyStore.Revoke(request.Id); err != nil {
+		if errors.Is(err, lib.ErrApiKeyStoreUnknownKey) {
+			return nil, ErrApiKeyRevokeUnknownKey
+		}
+
+		log.Printf("error while revoking api key %s: %s", request.Id, err)
+		return nil, ErrApiKeyRevokeError
+	}
+
+	if principal, ok := lib.PrincipalFromContext(ctx); ok {
+		log.Printf("%s revoked api key %s", principal.Subject, request.Id)
+	}
+
+	return &ApiKeyRevokeResponse{}, nil
+}
@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type ApiKeyRevokeHandlerMock struct {
+	HandleCalled bool
+	LastRequest  ApiKeyRevokeRequest
+	NextResponse *ApiKeyRevokeResponse
+	NextError    error
+}
+
+func (m *ApiKeyRevokeHandlerMock) Handle(ctx context.Context, request ApiKeyRevokeRequest) (*ApiKeyRevokeResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
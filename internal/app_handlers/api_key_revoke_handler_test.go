@@ -0,0 +1,53 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ApiKeyRevokeHandler_Handle_returns_error_on_empty_id(t *testing.T) {
+	// Arrange
+	api_key_store_mock := lib.ApiKeyStoreMock{}
+	sut := NewApiKeyRevokeHandler(&api_key_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), ApiKeyRevokeRequest{})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrApiKeyRevokeValidationError))
+	assert.False(t, api_key_store_mock.RevokeCalled)
+}
+
+func Test_ApiKeyRevokeHandler_Handle_revokes_key_by_id(t *testing.T) {
+	// Arrange
+	api_key_store_mock := lib.ApiKeyStoreMock{}
+	sut := NewApiKeyRevokeHandler(&api_key_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), ApiKeyRevokeRequest{Id: "some-id"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, "some-id", api_key_store_mock.LastId)
+}
+
+func Test_ApiKeyRevokeHandler_Handle_returns_error_on_unknown_key(t *testing.T) {
+	// Arrange
+	api_key_store_mock := lib.ApiKeyStoreMock{
+		NextRevokeError: lib.ErrApiKeyStoreUnknownKey,
+	}
+	sut := NewApiKeyRevokeHandler(&api_key_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), ApiKeyRevokeRequest{Id: "some-id"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrApiKeyRevokeUnknownKey))
+}
@@ -2,52 +2,117 @@ package app_handlers
 
 import (
 	"coding_exercise/internal/lib"
+	"context"
 	"errors"
 	"log"
 	"strings"
 )
 
 var (
-	ErrAuthValidationError      = errors.New("username or password is empty")
-	ErrAuthTokenGenerationError = errors.New("error generating token")
+	ErrAuthValidationError             = errors.New("username or password is empty")
+	ErrAuthInvalidCredentials          = errors.New("invalid username or password")
+	ErrAuthCredentialVerificationError = errors.New("error verifying credentials")
+	ErrAuthTokenGenerationError        = errors.New("error generating token")
+	ErrAuthTooManyAttempts             = errors.New("too many failed logins, try again later")
+	ErrAuthOtpRequired                 = errors.New("one time password required")
+	ErrAuthInvalidOtp                  = errors.New("invalid one time password")
 )
 
 type AuthRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Otp      string `json:"otp"`
+	ClientIp string `json:"-"`
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken,omitempty"`
 }
 
 type AuthHandler struct {
-	oidcProvider lib.OidcProvider
+	oidcProvider      lib.OidcProvider
+	credentialStore   lib.CredentialStore
+	refreshTokenStore lib.RefreshTokenStore
+	loginThrottle     *lib.LoginThrottle
+	mfaStore          lib.MfaStore
+	lifetimes         lib.TokenLifetimes
 }
 
-func NewAuthHandler(oidcProvider lib.OidcProvider) AppHandler[AuthRequest, AuthResponse] {
+// NewAuthHandler issues tokens with the lifetime of the password grant.
+func NewAuthHandler(oidcProvider lib.OidcProvider, credentialStore lib.CredentialStore, refreshTokenStore lib.RefreshTokenStore, loginThrottle *lib.LoginThrottle, mfaStore lib.MfaStore, lifetimes lib.TokenLifetimes) AppHandler[AuthRequest, AuthResponse] {
 	return &AuthHandler{
-		oidcProvider: oidcProvider,
+		oidcProvider:      oidcProvider,
+		credentialStore:   credentialStore,
+		refreshTokenStore: refreshTokenStore,
+		loginThrottle:     loginThrottle,
+		mfaStore:          mfaStore,
+		lifetimes:         lifetimes,
 	}
 }
 
-func (h *AuthHandler) Handle(request AuthRequest) (*AuthResponse, error) {
+func (h *AuthHandler) Handle(ctx context.Context, request AuthRequest) (*AuthResponse, error) {
 	request.Username = strings.TrimSpace(request.Username)
 
 	if request.Username == "" || request.Password == "" {
 		return nil, ErrAuthValidationError
 	}
 
-	// todo: validate credentials
+	// locked out logins are rejected without checking the password, so guessing
+	// doesn't continue during the lockout. The attempt counts as failed until the
+	// credentials turn out to be valid.
+	if lockout := h.loginThrottle.TryAttempt(request.Username, request.ClientIp); lockout > 0 {
+		log.Printf("rejecting login of %s from %s, locked out for %s", request.Username, request.ClientIp, lockout)
+		return nil, &RetryAfterError{Err: ErrAuthTooManyAttempts, RetryAfter: lockout}
+	}
+
+	user, err := h.credentialStore.VerifyCredentials(request.Username, request.Password)
+	if err != nil {
+		if errors.Is(err, lib.ErrCredentialStoreInvalidCredentials) {
+			log.Printf("invalid credentials for %s from %s", request.Username, request.ClientIp)
+			return nil, ErrAuthInvalidCredentials
+		}
+
+		log.Printf("error while verifying credentials for %s: %s", request.Username, err)
+		h.loginThrottle.Forgive(request.Username, request.ClientIp)
+		return nil, ErrAuthCredentialVerificationError
+	}
+
+	// users who enrolled a second factor need the one time password as well, a
+	// wrong one counts as a failed login so the code can't be guessed either
+	amr := []string{"pwd"}
+	if h.mfaStore.IsEnrolled(user.Id) {
+		if request.Otp == "" {
+			h.loginThrottle.Forgive(request.Username, request.ClientIp)
+			return nil, ErrAuthOtpRequired
+		}
+
+		if err := h.mfaStore.Verify(user.Id, request.Otp); err != nil {
+			log.Printf("invalid one time password for %s from %s: %s", request.Username, request.ClientIp, err)
+			return nil, ErrAuthInvalidOtp
+		}
 
-	token, err := h.oidcProvider.GenerateToken(request.Username)
+		amr = append(amr, "otp")
+	}
+
+	h.loginThrottle.RecordSuccess(request.Username, request.ClientIp)
+
+	// the user's grants decide the scopes of the token, its id is the subject
+	token, err := h.oidcProvider.GenerateToken(user.Id, lib.TokenOptions{Scopes: user.Scopes, Lifetime: h.lifetimes.Lifetime("password", nil), Amr: amr, Roles: user.Roles})
 	if err != nil {
 		log.Printf("error while generating token for %s: %s", request.Username, err)
 		return nil, ErrAuthTokenGenerationError
 	}
 
+	refreshToken, err := h.refreshTokenStore.Issue(lib.RefreshGrant{Subject: user.Id, Scopes: user.Scopes, Amr: amr, Roles: user.Roles})
+	if err != nil {
+		log.Printf("error while issuing refresh token for %s: %s", request.Username, err)
+		return nil, ErrAuthTokenGenerationError
+	}
+
 	response := &AuthResponse{
-		Token: token,
+		Token:        token,
+		RefreshToken: refreshToken,
 	}
 
 	return response, nil
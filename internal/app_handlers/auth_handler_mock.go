@@ -1,5 +1,9 @@
 package app_handlers
 
+import (
+	"context"
+)
+
 type AuthHandlerMock struct {
 	HandleCalled bool
 	LastRequest  AuthRequest
@@ -7,7 +11,7 @@ type AuthHandlerMock struct {
 	NextError    error
 }
 
-func (m *AuthHandlerMock) Handle(request AuthRequest) (*AuthResponse, error) {
+func (m *AuthHandlerMock) Handle(ctx context.Context, request AuthRequest) (*AuthResponse, error) {
 	m.HandleCalled = true
 	m.LastRequest = request
 	return m.NextResponse, m.NextError
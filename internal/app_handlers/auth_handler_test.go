@@ -2,24 +2,34 @@ package app_handlers
 
 import (
 	"coding_exercise/internal/lib"
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func newTestLoginThrottle() *lib.LoginThrottle {
+	return lib.NewLoginThrottle(&lib.LoginAttemptStoreMock{}, &lib.LoginAttemptStoreMock{})
+}
+
 func Test_AuthHandler_Handle_returns_error_on_empty_username(t *testing.T) {
 	// Arrange
 	oidc_provider_mock := lib.OidcProviderMock{}
-	sut := NewAuthHandler(&oidc_provider_mock)
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
 	req := AuthRequest{
 		Username: "",
 		Password: "some-password",
 	}
 
 	// Act
-	res, err := sut.Handle(req)
+	res, err := sut.Handle(context.Background(), req)
 
 	// Assert
 	assert.Nil(t, res)
@@ -30,14 +40,18 @@ func Test_AuthHandler_Handle_returns_error_on_empty_username(t *testing.T) {
 func Test_AuthHandler_Handle_doesnt_allow_spaces_as_username(t *testing.T) {
 	// Arrange
 	oidc_provider_mock := lib.OidcProviderMock{}
-	sut := NewAuthHandler(&oidc_provider_mock)
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
 	req := AuthRequest{
 		Username: "  ",
 		Password: "some-password  ",
 	}
 
 	// Act
-	res, err := sut.Handle(req)
+	res, err := sut.Handle(context.Background(), req)
 
 	// Assert
 	assert.Nil(t, res)
@@ -48,14 +62,18 @@ func Test_AuthHandler_Handle_doesnt_allow_spaces_as_username(t *testing.T) {
 func Test_AuthHandler_Handle_returns_error_on_empty_password(t *testing.T) {
 	// Arrange
 	oidc_provider_mock := lib.OidcProviderMock{}
-	sut := NewAuthHandler(&oidc_provider_mock)
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
 	req := AuthRequest{
 		Username: "some-username",
 		Password: "",
 	}
 
 	// Act
-	res, err := sut.Handle(req)
+	res, err := sut.Handle(context.Background(), req)
 
 	// Assert
 	assert.Nil(t, res)
@@ -66,35 +84,43 @@ func Test_AuthHandler_Handle_returns_error_on_empty_password(t *testing.T) {
 func Test_AuthHandler_Handle_allows_spaces_as_password(t *testing.T) {
 	// Arrange
 	oidc_provider_mock := lib.OidcProviderMock{}
-	sut := NewAuthHandler(&oidc_provider_mock)
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
 	req := AuthRequest{
 		Username: "some-username",
 		Password: "    ",
 	}
 
 	// Act
-	res, err := sut.Handle(req)
+	res, err := sut.Handle(context.Background(), req)
 
 	// Assert
 	assert.NotNil(t, res)
 	assert.Nil(t, err)
 }
 
-func Test_AuthHandler_Handle_calls_oidc_provider_with_username(t *testing.T) {
+func Test_AuthHandler_Handle_calls_oidc_provider_with_user_id(t *testing.T) {
 	// Arrange
 	oidc_provider_mock := lib.OidcProviderMock{}
-	sut := NewAuthHandler(&oidc_provider_mock)
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Id: "some-id", Username: "some-username"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
 	req := AuthRequest{
 		Username: "some-username",
 		Password: "some-password",
 	}
 
 	// Act
-	_, err := sut.Handle(req)
+	_, err := sut.Handle(context.Background(), req)
 
 	// Assert
 	assert.Nil(t, err)
-	assert.Equal(t, "some-username", oidc_provider_mock.LastUsername)
+	assert.Equal(t, "some-id", oidc_provider_mock.LastUsername)
 }
 
 func Test_AuthHandler_Handle_returns_result_from_oidc_provider(t *testing.T) {
@@ -103,14 +129,18 @@ func Test_AuthHandler_Handle_returns_result_from_oidc_provider(t *testing.T) {
 		NextGenerateTokenResult: "some-token",
 		NextGenerateTokenError:  nil,
 	}
-	sut := NewAuthHandler(&oidc_provider_mock)
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
 	req := AuthRequest{
 		Username: "some-username",
 		Password: "some-password",
 	}
 
 	// Act
-	res, err := sut.Handle(req)
+	res, err := sut.Handle(context.Background(), req)
 
 	// Assert
 	assert.Nil(t, err)
@@ -123,17 +153,394 @@ func Test_AuthHandler_Handle_returns_error_when_token_generation_fails(t *testin
 	oidc_provider_mock := lib.OidcProviderMock{
 		NextGenerateTokenError: errors.New("some-error"),
 	}
-	sut := NewAuthHandler(&oidc_provider_mock)
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
 	req := AuthRequest{
 		Username: "some-username",
 		Password: "some-password",
 	}
 
 	// Act
-	res, err := sut.Handle(req)
+	res, err := sut.Handle(context.Background(), req)
 
 	// Assert
 	require.NotNil(t, err)
 	assert.Nil(t, res)
 	assert.True(t, errors.Is(err, ErrAuthTokenGenerationError))
 }
+
+func Test_AuthHandler_Handle_verifies_username_and_password(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: " some-username ",
+		Password: "some-password",
+	}
+
+	// Act
+	_, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, credential_store_mock.VerifyCredentialsCalled)
+	assert.Equal(t, "some-username", credential_store_mock.LastUsername)
+	assert.Equal(t, "some-password", credential_store_mock.LastPassword)
+}
+
+func Test_AuthHandler_Handle_returns_error_on_invalid_credentials(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsError: lib.ErrCredentialStoreInvalidCredentials,
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "wrong-password",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthInvalidCredentials))
+	assert.False(t, oidc_provider_mock.GenerateTokenCalled)
+}
+
+func Test_AuthHandler_Handle_returns_error_when_credential_verification_fails(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsError: errors.New("some-error"),
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "some-password",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthCredentialVerificationError))
+	assert.False(t, oidc_provider_mock.GenerateTokenCalled)
+}
+
+func Test_AuthHandler_Handle_returns_refresh_token_for_user_id(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Id: "some-id", Username: "some-username"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextIssueResult: "some-refresh-token",
+	}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "some-password",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "some-refresh-token", res.RefreshToken)
+	assert.Equal(t, "some-id", refresh_token_store_mock.LastGrant.Subject)
+}
+
+func Test_AuthHandler_Handle_returns_error_when_refresh_token_cannot_be_issued(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextIssueError: errors.New("some-error"),
+	}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "some-password",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthTokenGenerationError))
+}
+
+func Test_AuthHandler_Handle_issues_tokens_with_scopes_of_user(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextGenerateTokenResult: "some-token",
+	}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user", Scopes: []string{"some-scope"}},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{}, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-user",
+		Password: "some-password",
+	}
+
+	// Act
+	_, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"some-scope"}, oidc_provider_mock.LastTokenOptions.Scopes)
+	assert.Equal(t, []string{"some-scope"}, refresh_token_store_mock.LastGrant.Scopes)
+}
+
+func Test_AuthHandler_Handle_rejects_locked_out_login_without_verifying_password(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	ip_attempts_mock := lib.LoginAttemptStoreMock{NextTryAttemptResult: time.Minute}
+	login_throttle := lib.NewLoginThrottle(&lib.LoginAttemptStoreMock{}, &ip_attempts_mock)
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, login_throttle, &lib.MfaStoreMock{}, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "some-password",
+		ClientIp: "192.0.2.1",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthTooManyAttempts))
+	var retry_after_err *RetryAfterError
+	require.True(t, errors.As(err, &retry_after_err))
+	assert.Equal(t, time.Minute, retry_after_err.RetryAfter)
+	assert.Equal(t, "192.0.2.1", ip_attempts_mock.LastKey)
+	assert.False(t, credential_store_mock.VerifyCredentialsCalled)
+}
+
+func Test_AuthHandler_Handle_records_failed_login(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsError: lib.ErrCredentialStoreInvalidCredentials,
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	username_attempts_mock := lib.LoginAttemptStoreMock{}
+	ip_attempts_mock := lib.LoginAttemptStoreMock{}
+	login_throttle := lib.NewLoginThrottle(&username_attempts_mock, &ip_attempts_mock)
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, login_throttle, &lib.MfaStoreMock{}, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "wrong-password",
+		ClientIp: "192.0.2.1",
+	}
+
+	// Act
+	_, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrAuthInvalidCredentials))
+	assert.True(t, username_attempts_mock.TryAttemptCalled)
+	assert.Equal(t, "some-username", username_attempts_mock.LastKey)
+	assert.True(t, ip_attempts_mock.TryAttemptCalled)
+	assert.Equal(t, "192.0.2.1", ip_attempts_mock.LastKey)
+	assert.False(t, username_attempts_mock.ForgiveCalled)
+	assert.False(t, ip_attempts_mock.ForgiveCalled)
+}
+
+func Test_AuthHandler_Handle_resets_failed_logins_of_username_on_success(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-username"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	username_attempts_mock := lib.LoginAttemptStoreMock{}
+	ip_attempts_mock := lib.LoginAttemptStoreMock{}
+	login_throttle := lib.NewLoginThrottle(&username_attempts_mock, &ip_attempts_mock)
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, login_throttle, &lib.MfaStoreMock{}, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "some-password",
+		ClientIp: "192.0.2.1",
+	}
+
+	// Act
+	_, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, username_attempts_mock.ResetCalled)
+	assert.False(t, ip_attempts_mock.ResetCalled)
+	assert.True(t, ip_attempts_mock.ForgiveCalled)
+}
+
+func Test_AuthHandler_Handle_issues_tokens_with_password_amr_without_mfa(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-username"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	mfa_store_mock := lib.MfaStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &mfa_store_mock, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "some-password",
+	}
+
+	// Act
+	_, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.False(t, mfa_store_mock.VerifyCalled)
+	assert.Equal(t, []string{"pwd"}, oidc_provider_mock.LastTokenOptions.Amr)
+	assert.Equal(t, []string{"pwd"}, refresh_token_store_mock.LastGrant.Amr)
+}
+
+func Test_AuthHandler_Handle_issues_token_with_lifetime_of_password_grant(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-username"},
+	}
+	lifetimes := lib.TokenLifetimes{
+		Default: time.Hour,
+		Grants:  map[string]time.Duration{"password": 10 * time.Minute},
+	}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &lib.RefreshTokenStoreMock{}, newTestLoginThrottle(), &lib.MfaStoreMock{}, lifetimes)
+
+	// Act
+	_, err := sut.Handle(context.Background(), AuthRequest{Username: "some-username", Password: "some-password"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Minute, oidc_provider_mock.LastTokenOptions.Lifetime)
+}
+
+func Test_AuthHandler_Handle_issues_tokens_with_roles_of_user(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-username", Roles: []string{"analyst"}},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	mfa_store_mock := lib.MfaStoreMock{}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &mfa_store_mock, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "some-password",
+	}
+
+	// Act
+	_, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"analyst"}, oidc_provider_mock.LastTokenOptions.Roles)
+	assert.Equal(t, []string{"analyst"}, refresh_token_store_mock.LastGrant.Roles)
+}
+
+func Test_AuthHandler_Handle_returns_error_without_otp_of_enrolled_user(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-username"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	mfa_store_mock := lib.MfaStoreMock{NextIsEnrolledResult: true}
+	username_attempts_mock := lib.LoginAttemptStoreMock{}
+	login_throttle := lib.NewLoginThrottle(&username_attempts_mock, &lib.LoginAttemptStoreMock{})
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, login_throttle, &mfa_store_mock, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "some-password",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthOtpRequired))
+	assert.False(t, oidc_provider_mock.GenerateTokenCalled)
+	assert.True(t, username_attempts_mock.ForgiveCalled)
+}
+
+func Test_AuthHandler_Handle_records_failed_login_on_invalid_otp(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-username"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	mfa_store_mock := lib.MfaStoreMock{
+		NextIsEnrolledResult: true,
+		NextVerifyError:      lib.ErrMfaStoreInvalidCode,
+	}
+	username_attempts_mock := lib.LoginAttemptStoreMock{}
+	login_throttle := lib.NewLoginThrottle(&username_attempts_mock, &lib.LoginAttemptStoreMock{})
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, login_throttle, &mfa_store_mock, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "some-password",
+		Otp:      "123456",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthInvalidOtp))
+	assert.Equal(t, "123456", mfa_store_mock.LastCode)
+	assert.True(t, username_attempts_mock.TryAttemptCalled)
+	assert.False(t, username_attempts_mock.ResetCalled)
+	assert.False(t, oidc_provider_mock.GenerateTokenCalled)
+}
+
+func Test_AuthHandler_Handle_issues_tokens_with_otp_amr_for_enrolled_user(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Id: "some-id", Username: "some-username"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	mfa_store_mock := lib.MfaStoreMock{NextIsEnrolledResult: true}
+	sut := NewAuthHandler(&oidc_provider_mock, &credential_store_mock, &refresh_token_store_mock, newTestLoginThrottle(), &mfa_store_mock, lib.TokenLifetimes{})
+	req := AuthRequest{
+		Username: "some-username",
+		Password: "some-password",
+		Otp:      "123456",
+	}
+
+	// Act
+	_, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "some-id", mfa_store_mock.LastSubject)
+	assert.Equal(t, []string{"pwd", "otp"}, oidc_provider_mock.LastTokenOptions.Amr)
+	assert.Equal(t, []string{"pwd", "otp"}, refresh_token_store_mock.LastGrant.Amr)
+}
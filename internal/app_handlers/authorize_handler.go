@@ -0,0 +1,216 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAuthorizeInvalidClient      = errors.New("unknown client or redirect uri")
+	ErrAuthorizeLoginRequired      = errors.New("login required")
+	ErrAuthorizeInvalidCredentials = errors.New("invalid username or password")
+	ErrAuthorizeServerError        = errors.New("error while authorizing")
+	ErrAuthorizeTooManyAttempts    = errors.New("too many failed logins, try again later")
+	ErrAuthorizeOtpRequired        = errors.New("one time password required")
+	ErrAuthorizeInvalidOtp         = errors.New("invalid one time password")
+)
+
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientId            string
+	RedirectUri         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
+	Username            string
+	Password            string
+	Otp                 string
+	ClientIp            string
+}
+
+// AuthorizeResponse redirects the user agent back to the client, either with an
+// authorization code or with an error code of RFC 6749 section 4.1.2.1.
+type AuthorizeResponse struct {
+	RedirectUri string
+}
+
+// AuthorizeHandler is the authorization endpoint of the authorization code flow,
+// the user logs in with the same credentials as on the auth endpoint. Only PKCE
+// with S256 is supported, so an intercepted code is useless without the verifier.
+type AuthorizeHandler struct {
+	clientStore            lib.ClientStore
+	credentialStore        lib.CredentialStore
+	authorizationCodeStore lib.AuthorizationCodeStore
+	loginThrottle          *lib.LoginThrottle
+	mfaStore               lib.MfaStore
+	now                    func() time.Time
+}
+
+func NewAuthorizeHandler(clientStore lib.ClientStore, credentialStore lib.CredentialStore, authorizationCodeStore lib.AuthorizationCodeStore, loginThrottle *lib.LoginThrottle, mfaStore lib.MfaStore) AppHandler[AuthorizeRequest, AuthorizeResponse] {
+	return &AuthorizeHandler{
+		clientStore:            clientStore,
+		credentialStore:        credentialStore,
+		authorizationCodeStore: authorizationCodeStore,
+		loginThrottle:          loginThrottle,
+		mfaStore:               mfaStore,
+		now:                    time.Now,
+	}
+}
+
+func (h *AuthorizeHandler) Handle(ctx context.Context, request AuthorizeRequest) (*AuthorizeResponse, error) {
+	// without a valid client and redirect uri there is nowhere safe to redirect to
+	client, err := h.clientStore.GetClient(request.ClientId)
+	if err != nil {
+		if errors.Is(err, lib.ErrClientStoreInvalidClient) {
+			log.Printf("authorization requested for unknown client %s", request.ClientId)
+			return nil, ErrAuthorizeInvalidClient
+		}
+
+		log.Printf("error while loading client %s: %s", request.ClientId, err)
+		return nil, ErrAuthorizeServerError
+	}
+
+	if request.RedirectUri == "" && len(client.RedirectUris) == 1 {
+		request.RedirectUri = client.RedirectUris[0]
+	}
+
+	if !client.HasRedirectUri(request.RedirectUri) {
+		log.Printf("unregistered redirect uri for client %s: %s", client.Id, request.RedirectUri)
+		return nil, ErrAuthorizeInvalidClient
+	}
+
+	if request.ResponseType != "code" {
+		return redirectWithError(request, "unsupported_response_type")
+	}
+
+	if request.CodeChallenge == "" || request.CodeChallengeMethod != "S256" {
+		return redirectWithError(request, "invalid_request")
+	}
+
+	request.Username = strings.TrimSpace(request.Username)
+	if request.Username == "" || request.Password == "" {
+		return nil, ErrAuthorizeLoginRequired
+	}
+
+	// the login form is throttled like the auth endpoint, it checks the same passwords
+	if lockout := h.loginThrottle.TryAttempt(request.Username, request.ClientIp); lockout > 0 {
+		log.Printf("rejecting login of %s from %s, locked out for %s", request.Username, request.ClientIp, lockout)
+		return nil, &RetryAfterError{Err: ErrAuthorizeTooManyAttempts, RetryAfter: lockout}
+	}
+
+	user, err := h.credentialStore.VerifyCredentials(request.Username, request.Password)
+	if err != nil {
+		if errors.Is(err, lib.ErrCredentialStoreInvalidCredentials) {
+			log.Printf("invalid credentials for %s from %s", request.Username, request.ClientIp)
+			return nil, ErrAuthorizeInvalidCredentials
+		}
+
+		log.Printf("error while verifying credentials for %s: %s", request.Username, err)
+		h.loginThrottle.Forgive(request.Username, request.ClientIp)
+		return nil, ErrAuthorizeServerError
+	}
+
+	amr := []string{"pwd"}
+	if h.mfaStore.IsEnrolled(user.Id) {
+		if request.Otp == "" {
+			h.loginThrottle.Forgive(request.Username, request.ClientIp)
+			return nil, ErrAuthorizeOtpRequired
+		}
+
+		if err := h.mfaStore.Verify(user.Id, request.Otp); err != nil {
+			log.Printf("invalid one time password for %s from %s: %s", request.Username, request.ClientIp, err)
+			return nil, ErrAuthorizeInvalidOtp
+		}
+
+		amr = append(amr, "otp")
+	}
+
+	h.loginThrottle.RecordSuccess(request.Username, request.ClientIp)
+
+	// the client only gets the scopes both the user and the client were granted,
+	// openid is always allowed and asks for an ID token
+	requested := strings.Fields(request.Scope)
+	openId := containsScope(requested, "openid")
+	scopes, err := grantedScopes(strings.Join(removeScope(requested, "openid"), " "), intersectScopes(user.Scopes, client.Scopes))
+	if err != nil {
+		return redirectWithError(request, "invalid_scope")
+	}
+
+	if openId {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+
+	code, err := h.authorizationCodeStore.Issue(lib.AuthorizationGrant{
+		ClientId:      client.Id,
+		RedirectUri:   request.RedirectUri,
+		Subject:       user.Id,
+		Scopes:        scopes,
+		CodeChallenge: request.CodeChallenge,
+		Nonce:         request.Nonce,
+		AuthTime:      h.now(),
+		Amr:           amr,
+		Roles:         user.Roles,
+	})
+	if err != nil {
+		log.Printf("error while issuing authorization code for %s: %s", request.Username, err)
+		return nil, ErrAuthorizeServerError
+	}
+
+	return redirectWith(request, url.Values{"code": {code}})
+}
+
+func redirectWithError(request AuthorizeRequest, code string) (*AuthorizeResponse, error) {
+	log.Printf("authorization request of client %s failed: %s", request.ClientId, code)
+	return redirectWith(request, url.Values{"error": {code}})
+}
+
+// redirectWith adds the parameters and the state to the query of the redirect uri,
+// keeping the query the uri was registered with.
+func redirectWith(request AuthorizeRequest, parameters url.Values) (*AuthorizeResponse, error) {
+	redirectUri, err := url.Parse(request.RedirectUri)
+	if err != nil {
+		log.Printf("unable to parse redirect uri %s: %s", request.RedirectUri, err)
+		return nil, ErrAuthorizeServerError
+	}
+
+	if request.State != "" {
+		parameters.Set("state", request.State)
+	}
+
+	query := redirectUri.Query()
+	for name, values := range parameters {
+		query[name] = values
+	}
+
+	redirectUri.RawQuery = query.Encode()
+	return &AuthorizeResponse{RedirectUri: redirectUri.String()}, nil
+}
+
+func intersectScopes(scopes []string, other []string) []string {
+	intersection := []string{}
+	for _, scope := range scopes {
+		if containsScope(other, scope) {
+			intersection = append(intersection, scope)
+		}
+	}
+
+	return intersection
+}
+
+func removeScope(scopes []string, scope string) []string {
+	remaining := []string{}
+	for _, other := range scopes {
+		if other != scope {
+			remaining = append(remaining, other)
+		}
+	}
+
+	return remaining
+}
@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type AuthorizeHandlerMock struct {
+	HandleCalled bool
+	LastRequest  AuthorizeRequest
+	NextResponse *AuthorizeResponse
+	NextError    error
+}
+
+func (m *AuthorizeHandlerMock) Handle(ctx context.Context, request AuthorizeRequest) (*AuthorizeResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
@@ -0,0 +1,335 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuthorizeRequest() AuthorizeRequest {
+	return AuthorizeRequest{
+		ResponseType:        "code",
+		ClientId:            "some-client",
+		RedirectUri:         "http://localhost/callback",
+		State:               "some-state",
+		CodeChallenge:       "some-challenge",
+		CodeChallengeMethod: "S256",
+		Username:            "some-user",
+		Password:            "some-password",
+	}
+}
+
+func newTestAuthorizeClientStore() *lib.ClientStoreMock {
+	return &lib.ClientStoreMock{
+		NextGetClientResult: &lib.Client{
+			Id:           "some-client",
+			Scopes:       []string{"some-scope", "other-scope"},
+			RedirectUris: []string{"http://localhost/callback"},
+		},
+	}
+}
+
+func Test_AuthorizeHandler_Handle_returns_error_on_unknown_client(t *testing.T) {
+	// Arrange
+	client_store_mock := lib.ClientStoreMock{
+		NextGetClientError: lib.ErrClientStoreInvalidClient,
+	}
+	credential_store_mock := lib.CredentialStoreMock{}
+	code_store_mock := lib.AuthorizationCodeStoreMock{}
+	sut := NewAuthorizeHandler(&client_store_mock, &credential_store_mock, &code_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), newTestAuthorizeRequest())
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthorizeInvalidClient))
+}
+
+func Test_AuthorizeHandler_Handle_returns_error_on_unregistered_redirect_uri(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{}
+	code_store_mock := lib.AuthorizationCodeStoreMock{}
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{})
+
+	req := newTestAuthorizeRequest()
+	req.RedirectUri = "http://attacker.example.com/callback"
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthorizeInvalidClient))
+	assert.False(t, credential_store_mock.VerifyCredentialsCalled)
+}
+
+func Test_AuthorizeHandler_Handle_redirects_with_error_without_code_challenge(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{}
+	code_store_mock := lib.AuthorizationCodeStoreMock{}
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{})
+
+	req := newTestAuthorizeRequest()
+	req.CodeChallengeMethod = "plain"
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "http://localhost/callback?error=invalid_request&state=some-state", res.RedirectUri)
+	assert.False(t, code_store_mock.IssueCalled)
+}
+
+func Test_AuthorizeHandler_Handle_redirects_with_error_on_unsupported_response_type(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{}
+	code_store_mock := lib.AuthorizationCodeStoreMock{}
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{})
+
+	req := newTestAuthorizeRequest()
+	req.ResponseType = "token"
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "http://localhost/callback?error=unsupported_response_type&state=some-state", res.RedirectUri)
+}
+
+func Test_AuthorizeHandler_Handle_returns_login_required_without_credentials(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{}
+	code_store_mock := lib.AuthorizationCodeStoreMock{}
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{})
+
+	req := newTestAuthorizeRequest()
+	req.Username = ""
+	req.Password = ""
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthorizeLoginRequired))
+	assert.False(t, credential_store_mock.VerifyCredentialsCalled)
+}
+
+func Test_AuthorizeHandler_Handle_returns_error_on_invalid_credentials(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsError: lib.ErrCredentialStoreInvalidCredentials,
+	}
+	code_store_mock := lib.AuthorizationCodeStoreMock{}
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), newTestAuthorizeRequest())
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthorizeInvalidCredentials))
+	assert.False(t, code_store_mock.IssueCalled)
+}
+
+func Test_AuthorizeHandler_Handle_redirects_with_code_and_state(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Id: "some-id", Username: "some-user", Scopes: []string{"some-scope", "user-scope"}},
+	}
+	code_store_mock := lib.AuthorizationCodeStoreMock{
+		NextIssueResult: "some-code",
+	}
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{})
+	sut.(*AuthorizeHandler).now = func() time.Time { return time.Unix(946684800, 0) }
+
+	// Act
+	res, err := sut.Handle(context.Background(), newTestAuthorizeRequest())
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "http://localhost/callback?code=some-code&state=some-state", res.RedirectUri)
+	assert.Equal(t, "some-client", code_store_mock.LastGrant.ClientId)
+	assert.Equal(t, "http://localhost/callback", code_store_mock.LastGrant.RedirectUri)
+	assert.Equal(t, "some-id", code_store_mock.LastGrant.Subject)
+	assert.Equal(t, []string{"some-scope"}, code_store_mock.LastGrant.Scopes)
+	assert.Equal(t, "some-challenge", code_store_mock.LastGrant.CodeChallenge)
+	assert.Equal(t, time.Unix(946684800, 0), code_store_mock.LastGrant.AuthTime)
+}
+
+func Test_AuthorizeHandler_Handle_allows_openid_scope_and_keeps_nonce(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user", Scopes: []string{"some-scope"}},
+	}
+	code_store_mock := lib.AuthorizationCodeStoreMock{
+		NextIssueResult: "some-code",
+	}
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{})
+
+	req := newTestAuthorizeRequest()
+	req.Scope = "openid some-scope"
+	req.Nonce = "some-nonce"
+
+	// Act
+	_, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"openid", "some-scope"}, code_store_mock.LastGrant.Scopes)
+	assert.Equal(t, "some-nonce", code_store_mock.LastGrant.Nonce)
+}
+
+func Test_AuthorizeHandler_Handle_redirects_with_error_on_scope_not_granted_to_user(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user", Scopes: []string{"some-scope"}},
+	}
+	code_store_mock := lib.AuthorizationCodeStoreMock{}
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, newTestLoginThrottle(), &lib.MfaStoreMock{})
+
+	req := newTestAuthorizeRequest()
+	req.Scope = "other-scope"
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	redirect_uri, err := url.Parse(res.RedirectUri)
+	require.Nil(t, err)
+	assert.Equal(t, "invalid_scope", redirect_uri.Query().Get("error"))
+	assert.False(t, code_store_mock.IssueCalled)
+}
+
+func Test_AuthorizeHandler_Handle_rejects_locked_out_login(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{}
+	code_store_mock := lib.AuthorizationCodeStoreMock{}
+	username_attempts_mock := lib.LoginAttemptStoreMock{NextTryAttemptResult: time.Minute}
+	login_throttle := lib.NewLoginThrottle(&username_attempts_mock, &lib.LoginAttemptStoreMock{})
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, login_throttle, &lib.MfaStoreMock{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), newTestAuthorizeRequest())
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthorizeTooManyAttempts))
+	assert.False(t, credential_store_mock.VerifyCredentialsCalled)
+	assert.False(t, code_store_mock.IssueCalled)
+}
+
+func Test_AuthorizeHandler_Handle_records_failed_login(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsError: lib.ErrCredentialStoreInvalidCredentials,
+	}
+	code_store_mock := lib.AuthorizationCodeStoreMock{}
+	username_attempts_mock := lib.LoginAttemptStoreMock{}
+	login_throttle := lib.NewLoginThrottle(&username_attempts_mock, &lib.LoginAttemptStoreMock{})
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, login_throttle, &lib.MfaStoreMock{})
+
+	// Act
+	_, err := sut.Handle(context.Background(), newTestAuthorizeRequest())
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrAuthorizeInvalidCredentials))
+	assert.True(t, username_attempts_mock.TryAttemptCalled)
+	assert.Equal(t, "some-user", username_attempts_mock.LastKey)
+}
+
+func Test_AuthorizeHandler_Handle_returns_error_without_otp_of_enrolled_user(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user", Scopes: []string{"some-scope"}},
+	}
+	code_store_mock := lib.AuthorizationCodeStoreMock{}
+	mfa_store_mock := lib.MfaStoreMock{NextIsEnrolledResult: true}
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, newTestLoginThrottle(), &mfa_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), newTestAuthorizeRequest())
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthorizeOtpRequired))
+	assert.False(t, code_store_mock.IssueCalled)
+}
+
+func Test_AuthorizeHandler_Handle_returns_error_on_invalid_otp(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user", Scopes: []string{"some-scope"}},
+	}
+	code_store_mock := lib.AuthorizationCodeStoreMock{}
+	mfa_store_mock := lib.MfaStoreMock{
+		NextIsEnrolledResult: true,
+		NextVerifyError:      lib.ErrMfaStoreInvalidCode,
+	}
+	username_attempts_mock := lib.LoginAttemptStoreMock{}
+	login_throttle := lib.NewLoginThrottle(&username_attempts_mock, &lib.LoginAttemptStoreMock{})
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, login_throttle, &mfa_store_mock)
+
+	req := newTestAuthorizeRequest()
+	req.Otp = "123456"
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrAuthorizeInvalidOtp))
+	assert.True(t, username_attempts_mock.TryAttemptCalled)
+	assert.False(t, code_store_mock.IssueCalled)
+}
+
+func Test_AuthorizeHandler_Handle_keeps_authentication_methods_and_roles_in_grant(t *testing.T) {
+	// Arrange
+	client_store_mock := newTestAuthorizeClientStore()
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Username: "some-user", Scopes: []string{"some-scope"}, Roles: []string{"analyst"}},
+	}
+	code_store_mock := lib.AuthorizationCodeStoreMock{
+		NextIssueResult: "some-code",
+	}
+	mfa_store_mock := lib.MfaStoreMock{NextIsEnrolledResult: true}
+	sut := NewAuthorizeHandler(client_store_mock, &credential_store_mock, &code_store_mock, newTestLoginThrottle(), &mfa_store_mock)
+
+	req := newTestAuthorizeRequest()
+	req.Otp = "123456"
+
+	// Act
+	_, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "123456", mfa_store_mock.LastCode)
+	assert.Equal(t, []string{"pwd", "otp"}, code_store_mock.LastGrant.Amr)
+	assert.Equal(t, []string{"analyst"}, code_store_mock.LastGrant.Roles)
+}
@@ -0,0 +1,61 @@
+package app_handlers
+
+import (
+	"context"
+	"strings"
+)
+
+type DiscoveryRequest struct{}
+
+// DiscoveryResponse is the OpenID Connect discovery document, the field names are
+// defined by the OpenID Connect Discovery 1.0 specification.
+type DiscoveryResponse struct {
+	Issuer                           string   `json:"issuer"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+	TokenEndpoint                    string   `json:"token_endpoint"`
+	TokenEndpointAuthMethods         []string `json:"token_endpoint_auth_methods_supported"`
+	JwksUri                          string   `json:"jwks_uri"`
+	RevocationEndpoint               string   `json:"revocation_endpoint"`
+	RevocationEndpointAuthMethods    []string `json:"revocation_endpoint_auth_methods_supported"`
+	IntrospectionEndpoint            string   `json:"introspection_endpoint"`
+	ResponseTypesSupported           []string `json:"response_types_supported"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IdTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	GrantTypesSupported              []string `json:"grant_types_supported"`
+	CodeChallengeMethodsSupported    []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                  []string `json:"claims_supported"`
+}
+
+type DiscoveryHandler struct {
+	document DiscoveryResponse
+}
+
+// NewDiscoveryHandler builds the document once, baseUrl is the url the service is
+// reachable on and is used to build the absolute endpoint urls.
+func NewDiscoveryHandler(issuer string, baseUrl string, signingAlgorithm string) AppHandler[DiscoveryRequest, DiscoveryResponse] {
+	baseUrl = strings.TrimSuffix(baseUrl, "/")
+
+	return &DiscoveryHandler{
+		document: DiscoveryResponse{
+			Issuer:                           issuer,
+			AuthorizationEndpoint:            baseUrl + "/authorize",
+			TokenEndpoint:                    baseUrl + "/token",
+			TokenEndpointAuthMethods:         []string{"client_secret_basic", "client_secret_post", "none"},
+			JwksUri:                          baseUrl + "/.well-known/jwks.json",
+			RevocationEndpoint:               baseUrl + "/revoke",
+			RevocationEndpointAuthMethods:    []string{"client_secret_basic", "none"},
+			IntrospectionEndpoint:            baseUrl + "/introspect",
+			ResponseTypesSupported:           []string{"code"},
+			SubjectTypesSupported:            []string{"public"},
+			IdTokenSigningAlgValuesSupported: []string{signingAlgorithm},
+			GrantTypesSupported:              []string{"authorization_code", "client_credentials", "refresh_token"},
+			CodeChallengeMethodsSupported:    []string{"S256"},
+			ClaimsSupported:                  []string{"iss", "sub", "iat", "nbf", "exp", "jti", "scope", "aud", "nonce", "auth_time", "amr", "roles", "preferred_username", "email", "name"},
+		},
+	}
+}
+
+func (h *DiscoveryHandler) Handle(ctx context.Context, request DiscoveryRequest) (*DiscoveryResponse, error) {
+	document := h.document
+	return &document, nil
+}
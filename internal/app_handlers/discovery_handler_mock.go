@@ -0,0 +1,16 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type DiscoveryHandlerMock struct {
+	HandleCalled bool
+	NextResponse *DiscoveryResponse
+	NextError    error
+}
+
+func (m *DiscoveryHandlerMock) Handle(ctx context.Context, request DiscoveryRequest) (*DiscoveryResponse, error) {
+	m.HandleCalled = true
+	return m.NextResponse, m.NextError
+}
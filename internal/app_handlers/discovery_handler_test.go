@@ -0,0 +1,57 @@
+package app_handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DiscoveryHandler_Handle_returns_issuer_and_algorithm(t *testing.T) {
+	// Arrange
+	sut := NewDiscoveryHandler("some-issuer", "https://auth.example.com", "EdDSA")
+
+	// Act
+	res, err := sut.Handle(context.Background(), DiscoveryRequest{})
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "some-issuer", res.Issuer)
+	assert.Equal(t, []string{"EdDSA"}, res.IdTokenSigningAlgValuesSupported)
+	assert.Contains(t, res.ClaimsSupported, "sub")
+}
+
+func Test_DiscoveryHandler_Handle_returns_claims_of_tokens_and_users(t *testing.T) {
+	// Arrange
+	sut := NewDiscoveryHandler("some-issuer", "https://auth.example.com", "EdDSA")
+
+	// Act
+	res, err := sut.Handle(context.Background(), DiscoveryRequest{})
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	for _, claim := range []string{"amr", "roles", "preferred_username", "email", "name"} {
+		assert.Contains(t, res.ClaimsSupported, claim)
+	}
+}
+
+func Test_DiscoveryHandler_Handle_returns_absolute_endpoints(t *testing.T) {
+	// Arrange
+	sut := NewDiscoveryHandler("some-issuer", "https://auth.example.com/", "HS512")
+
+	// Act
+	res, err := sut.Handle(context.Background(), DiscoveryRequest{})
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "https://auth.example.com/authorize", res.AuthorizationEndpoint)
+	assert.Equal(t, "https://auth.example.com/token", res.TokenEndpoint)
+	assert.Equal(t, "https://auth.example.com/.well-known/jwks.json", res.JwksUri)
+	assert.Equal(t, "https://auth.example.com/revoke", res.RevocationEndpoint)
+	assert.Equal(t, []string{"client_secret_basic", "none"}, res.RevocationEndpointAuthMethods)
+	assert.Equal(t, "https://auth.example.com/introspect", res.IntrospectionEndpoint)
+}
@@ -1,5 +1,25 @@
 package app_handlers
 
+import (
+	"context"
+	"time"
+)
+
 type AppHandler[T any, U any] interface {
-	Handle(request T) (*U, error)
+	Handle(ctx context.Context, request T) (*U, error)
+}
+
+// RetryAfterError tells the caller when the request can be tried again, it wraps
+// the error of the handler so errors.Is still matches it.
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
 }
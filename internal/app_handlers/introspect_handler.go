@@ -0,0 +1,70 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+)
+
+var (
+	ErrIntrospectValidationError = errors.New("token is empty")
+)
+
+type IntrospectRequest struct {
+	Token         string
+	TokenTypeHint string
+}
+
+// IntrospectResponse is serialized as defined by RFC 7662, the claims are top level
+// members next to active.
+type IntrospectResponse struct {
+	Active bool
+	Claims map[string]interface{}
+}
+
+func (r IntrospectResponse) MarshalJSON() ([]byte, error) {
+	document := map[string]interface{}{}
+	for name, value := range r.Claims {
+		document[name] = value
+	}
+
+	document["active"] = r.Active
+	return json.Marshal(document)
+}
+
+// IntrospectHandler tells clients which can't validate tokens themselves whether
+// a token is active. Tokens which don't validate are inactive, the reason is not
+// disclosed.
+type IntrospectHandler struct {
+	oidcProvider lib.OidcProvider
+}
+
+func NewIntrospectHandler(oidcProvider lib.OidcProvider) AppHandler[IntrospectRequest, IntrospectResponse] {
+	return &IntrospectHandler{
+		oidcProvider: oidcProvider,
+	}
+}
+
+func (h *IntrospectHandler) Handle(ctx context.Context, request IntrospectRequest) (*IntrospectResponse, error) {
+	request.Token = strings.TrimSpace(request.Token)
+
+	if request.Token == "" {
+		return nil, ErrIntrospectValidationError
+	}
+
+	claims, err := h.oidcProvider.ValidateToken(request.Token)
+	if err != nil {
+		log.Printf("introspected inactive token: %s", err)
+		return &IntrospectResponse{Active: false}, nil
+	}
+
+	response := &IntrospectResponse{
+		Active: true,
+		Claims: claims,
+	}
+
+	return response, nil
+}
@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type IntrospectHandlerMock struct {
+	HandleCalled bool
+	LastRequest  IntrospectRequest
+	NextResponse *IntrospectResponse
+	NextError    error
+}
+
+func (m *IntrospectHandlerMock) Handle(ctx context.Context, request IntrospectRequest) (*IntrospectResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
@@ -0,0 +1,76 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IntrospectHandler_Handle_returns_error_on_empty_token(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	sut := NewIntrospectHandler(&oidc_provider_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), IntrospectRequest{Token: " "})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrIntrospectValidationError))
+	assert.False(t, oidc_provider_mock.ValidateTokenCalled)
+}
+
+func Test_IntrospectHandler_Handle_returns_claims_of_active_token(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextValidateTokenResult: map[string]interface{}{"sub": "some-user", "iss": "some-issuer"},
+	}
+	sut := NewIntrospectHandler(&oidc_provider_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), IntrospectRequest{Token: "some-token"})
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "some-token", oidc_provider_mock.LastToken)
+	assert.True(t, res.Active)
+	assert.Equal(t, "some-user", res.Claims["sub"])
+}
+
+func Test_IntrospectHandler_Handle_returns_inactive_on_validation_error(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextValidateTokenError: lib.ErrJwtOidcProviderRevokedToken,
+	}
+	sut := NewIntrospectHandler(&oidc_provider_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), IntrospectRequest{Token: "some-token"})
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.False(t, res.Active)
+	assert.Nil(t, res.Claims)
+}
+
+func Test_IntrospectResponse_MarshalJSON_puts_claims_next_to_active(t *testing.T) {
+	// Arrange
+	sut := IntrospectResponse{
+		Active: true,
+		Claims: map[string]interface{}{"sub": "some-user", "active": false},
+	}
+
+	// Act
+	res, err := json.Marshal(sut)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"active":true,"sub":"some-user"}`, string(res))
+}
@@ -0,0 +1,23 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+)
+
+type JwksRequest struct{}
+
+type JwksHandler struct {
+	oidcProvider lib.OidcProvider
+}
+
+func NewJwksHandler(oidcProvider lib.OidcProvider) AppHandler[JwksRequest, lib.JwkSet] {
+	return &JwksHandler{
+		oidcProvider: oidcProvider,
+	}
+}
+
+func (h *JwksHandler) Handle(ctx context.Context, request JwksRequest) (*lib.JwkSet, error) {
+	jwks := h.oidcProvider.Jwks()
+	return &jwks, nil
+}
@@ -0,0 +1,17 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+)
+
+type JwksHandlerMock struct {
+	HandleCalled bool
+	NextResponse *lib.JwkSet
+	NextError    error
+}
+
+func (m *JwksHandlerMock) Handle(ctx context.Context, request JwksRequest) (*lib.JwkSet, error) {
+	m.HandleCalled = true
+	return m.NextResponse, m.NextError
+}
@@ -0,0 +1,30 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JwksHandler_Handle_returns_keys_from_oidc_provider(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextJwksResult: lib.JwkSet{
+			Keys: []lib.Jwk{{Kty: "OKP", Kid: "some-kid"}},
+		},
+	}
+	sut := NewJwksHandler(&oidc_provider_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), JwksRequest{})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, oidc_provider_mock.JwksCalled)
+	require.NotNil(t, res)
+	require.Len(t, res.Keys, 1)
+	assert.Equal(t, "some-kid", res.Keys[0].Kid)
+}
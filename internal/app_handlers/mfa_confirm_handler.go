@@ -0,0 +1,67 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"strings"
+)
+
+var (
+	ErrMfaConfirmValidationError = errors.New("one time password is empty")
+	ErrMfaConfirmForbidden       = errors.New("a token of a user login is required")
+	ErrMfaConfirmNoEnrollment    = errors.New("no enrollment to confirm")
+	ErrMfaConfirmInvalidOtp      = errors.New("invalid one time password")
+	ErrMfaConfirmError           = errors.New("error while confirming enrollment")
+)
+
+type MfaConfirmRequest struct {
+	Otp string `json:"otp"`
+}
+
+type MfaConfirmResponse struct {
+	Enrolled bool `json:"enrolled"`
+}
+
+// MfaConfirmHandler activates the pending enrollment of the user of the token, the
+// code proves the authenticator app has the secret.
+type MfaConfirmHandler struct {
+	mfaStore lib.MfaStore
+}
+
+func NewMfaConfirmHandler(mfaStore lib.MfaStore) AppHandler[MfaConfirmRequest, MfaConfirmResponse] {
+	return &MfaConfirmHandler{
+		mfaStore: mfaStore,
+	}
+}
+
+func (h *MfaConfirmHandler) Handle(ctx context.Context, request MfaConfirmRequest) (*MfaConfirmResponse, error) {
+	request.Otp = strings.TrimSpace(request.Otp)
+	if request.Otp == "" {
+		return nil, ErrMfaConfirmValidationError
+	}
+
+	principal, ok := lib.PrincipalFromContext(ctx)
+	if !ok || principal.Subject == "" || !principal.HasAmr("pwd") {
+		return nil, ErrMfaConfirmForbidden
+	}
+
+	if err := h.mfaStore.Confirm(principal.Subject, request.Otp); err != nil {
+		if errors.Is(err, lib.ErrMfaStoreNoEnrollment) {
+			return nil, ErrMfaConfirmNoEnrollment
+		}
+
+		if errors.Is(err, lib.ErrMfaStoreInvalidCode) {
+			log.Printf("invalid one time password confirming enrollment of %s", principal.Subject)
+			return nil, ErrMfaConfirmInvalidOtp
+		}
+
+		log.Printf("error while confirming enrollment of %s: %s", principal.Subject, err)
+		return nil, ErrMfaConfirmError
+	}
+
+	log.Printf("confirmed enrollment of %s", principal.Subject)
+
+	return &MfaConfirmResponse{Enrolled: true}, nil
+}
@@ -0,0 +1,20 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type MfaConfirmHandlerMock struct {
+	HandleCalled bool
+	LastContext  context.Context
+	LastRequest  MfaConfirmRequest
+	NextResponse *MfaConfirmResponse
+	NextError    error
+}
+
+func (m *MfaConfirmHandlerMock) Handle(ctx context.Context, request MfaConfirmRequest) (*MfaConfirmResponse, error) {
+	m.HandleCalled = true
+	m.LastContext = ctx
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
@@ -0,0 +1,75 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MfaConfirmHandler_Handle_returns_error_on_empty_otp(t *testing.T) {
+	// Arrange
+	mfa_store_mock := lib.MfaStoreMock{}
+	sut := NewMfaConfirmHandler(&mfa_store_mock)
+
+	// Act
+	res, err := sut.Handle(newTestMfaContext("pwd"), MfaConfirmRequest{Otp: " "})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrMfaConfirmValidationError))
+	assert.False(t, mfa_store_mock.ConfirmCalled)
+}
+
+func Test_MfaConfirmHandler_Handle_returns_error_for_token_of_client(t *testing.T) {
+	// Arrange
+	mfa_store_mock := lib.MfaStoreMock{}
+	sut := NewMfaConfirmHandler(&mfa_store_mock)
+
+	// Act
+	res, err := sut.Handle(newTestMfaContext(), MfaConfirmRequest{Otp: "123456"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrMfaConfirmForbidden))
+	assert.False(t, mfa_store_mock.ConfirmCalled)
+}
+
+func Test_MfaConfirmHandler_Handle_confirms_enrollment_of_subject(t *testing.T) {
+	// Arrange
+	mfa_store_mock := lib.MfaStoreMock{}
+	sut := NewMfaConfirmHandler(&mfa_store_mock)
+
+	// Act
+	res, err := sut.Handle(newTestMfaContext("pwd"), MfaConfirmRequest{Otp: "123456"})
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.True(t, res.Enrolled)
+	assert.Equal(t, "some-user", mfa_store_mock.LastSubject)
+	assert.Equal(t, "123456", mfa_store_mock.LastCode)
+}
+
+func Test_MfaConfirmHandler_Handle_maps_errors_of_mfa_store(t *testing.T) {
+	expected_errors := map[error]error{
+		lib.ErrMfaStoreNoEnrollment: ErrMfaConfirmNoEnrollment,
+		lib.ErrMfaStoreInvalidCode:  ErrMfaConfirmInvalidOtp,
+		errors.New("some-error"):    ErrMfaConfirmError,
+	}
+
+	for store_err, expected := range expected_errors {
+		// Arrange
+		mfa_store_mock := lib.MfaStoreMock{NextConfirmError: store_err}
+		sut := NewMfaConfirmHandler(&mfa_store_mock)
+
+		// Act
+		res, err := sut.Handle(newTestMfaContext("pwd"), MfaConfirmRequest{Otp: "123456"})
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, expected), store_err.Error())
+	}
+}
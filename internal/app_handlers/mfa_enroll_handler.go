@@ -0,0 +1,77 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+)
+
+var (
+	ErrMfaEnrollForbidden   = errors.New("a token of a user login is required")
+	ErrMfaEnrollOtpRequired = errors.New("already enrolled, log in with a one time password to enroll again")
+	ErrMfaEnrollError       = errors.New("error while enrolling")
+)
+
+type MfaEnrollRequest struct{}
+
+// MfaEnrollResponse is only returned once, the secret and recovery codes can't be
+// read again later.
+type MfaEnrollResponse struct {
+	Secret        string   `json:"secret"`
+	Uri           string   `json:"uri"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MfaEnrollHandler starts the enrollment of a TOTP second factor for the user of
+// the token, the enrollment is only used once it is confirmed with a code. The
+// username is the account shown in authenticator apps, the subject is an id.
+type MfaEnrollHandler struct {
+	mfaStore  lib.MfaStore
+	userStore lib.UserStore
+}
+
+func NewMfaEnrollHandler(mfaStore lib.MfaStore, userStore lib.UserStore) AppHandler[MfaEnrollRequest, MfaEnrollResponse] {
+	return &MfaEnrollHandler{
+		mfaStore:  mfaStore,
+		userStore: userStore,
+	}
+}
+
+func (h *MfaEnrollHandler) Handle(ctx context.Context, request MfaEnrollRequest) (*MfaEnrollResponse, error) {
+	principal, ok := lib.PrincipalFromContext(ctx)
+	if !ok || principal.Subject == "" || !principal.HasAmr("pwd") {
+		return nil, ErrMfaEnrollForbidden
+	}
+
+	// replacing the second factor needs the current one, a stolen password alone
+	// must not be enough to take it over
+	if h.mfaStore.IsEnrolled(principal.Subject) && !principal.HasAmr("otp") {
+		log.Printf("rejecting enrollment of %s without one time password", principal.Subject)
+		return nil, ErrMfaEnrollOtpRequired
+	}
+
+	user, err := h.userStore.Get(principal.Subject)
+	if err != nil {
+		if errors.Is(err, lib.ErrUserStoreUnknownUser) {
+			return nil, ErrMfaEnrollForbidden
+		}
+
+		log.Printf("error while loading user %s: %s", principal.Subject, err)
+		return nil, ErrMfaEnrollError
+	}
+
+	enrollment, err := h.mfaStore.Enroll(principal.Subject, user.Username)
+	if err != nil {
+		log.Printf("error while enrolling %s: %s", principal.Subject, err)
+		return nil, ErrMfaEnrollError
+	}
+
+	log.Printf("started enrollment of %s", principal.Subject)
+
+	return &MfaEnrollResponse{
+		Secret:        enrollment.Secret,
+		Uri:           enrollment.Uri,
+		RecoveryCodes: enrollment.RecoveryCodes,
+	}, nil
+}
@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type MfaEnrollHandlerMock struct {
+	HandleCalled bool
+	LastContext  context.Context
+	NextResponse *MfaEnrollResponse
+	NextError    error
+}
+
+func (m *MfaEnrollHandlerMock) Handle(ctx context.Context, request MfaEnrollRequest) (*MfaEnrollResponse, error) {
+	m.HandleCalled = true
+	m.LastContext = ctx
+	return m.NextResponse, m.NextError
+}
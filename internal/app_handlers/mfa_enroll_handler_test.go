@@ -0,0 +1,130 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMfaContext(amr ...string) context.Context {
+	return lib.WithPrincipal(context.Background(), &lib.Principal{Subject: "some-user", Amr: amr})
+}
+
+func newTestMfaUserStore() *lib.UserStoreMock {
+	return &lib.UserStoreMock{NextGetResult: &lib.User{Id: "some-user", Username: "some-username"}}
+}
+
+func Test_MfaEnrollHandler_Handle_returns_error_without_principal(t *testing.T) {
+	// Arrange
+	mfa_store_mock := lib.MfaStoreMock{}
+	sut := NewMfaEnrollHandler(&mfa_store_mock, newTestMfaUserStore())
+
+	// Act
+	res, err := sut.Handle(context.Background(), MfaEnrollRequest{})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrMfaEnrollForbidden))
+	assert.False(t, mfa_store_mock.EnrollCalled)
+}
+
+func Test_MfaEnrollHandler_Handle_returns_error_for_token_of_client(t *testing.T) {
+	// Arrange
+	mfa_store_mock := lib.MfaStoreMock{}
+	sut := NewMfaEnrollHandler(&mfa_store_mock, newTestMfaUserStore())
+
+	// Act
+	res, err := sut.Handle(newTestMfaContext(), MfaEnrollRequest{})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrMfaEnrollForbidden))
+	assert.False(t, mfa_store_mock.EnrollCalled)
+}
+
+func Test_MfaEnrollHandler_Handle_enrolls_subject_of_principal(t *testing.T) {
+	// Arrange
+	mfa_store_mock := lib.MfaStoreMock{
+		NextEnrollResult: &lib.MfaEnrollment{
+			Secret:        "some-secret",
+			Uri:           "otpauth://totp/some-uri",
+			RecoveryCodes: []string{"some-code"},
+		},
+	}
+	sut := NewMfaEnrollHandler(&mfa_store_mock, newTestMfaUserStore())
+
+	// Act
+	res, err := sut.Handle(newTestMfaContext("pwd"), MfaEnrollRequest{})
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "some-user", mfa_store_mock.LastSubject)
+	assert.Equal(t, "some-username", mfa_store_mock.LastAccount)
+	assert.Equal(t, "some-secret", res.Secret)
+	assert.Equal(t, "otpauth://totp/some-uri", res.Uri)
+	assert.Equal(t, []string{"some-code"}, res.RecoveryCodes)
+}
+
+func Test_MfaEnrollHandler_Handle_returns_error_for_unknown_user(t *testing.T) {
+	// Arrange
+	mfa_store_mock := lib.MfaStoreMock{}
+	user_store_mock := lib.UserStoreMock{NextGetError: lib.ErrUserStoreUnknownUser}
+	sut := NewMfaEnrollHandler(&mfa_store_mock, &user_store_mock)
+
+	// Act
+	res, err := sut.Handle(newTestMfaContext("pwd"), MfaEnrollRequest{})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrMfaEnrollForbidden))
+	assert.Equal(t, "some-user", user_store_mock.LastId)
+	assert.False(t, mfa_store_mock.EnrollCalled)
+}
+
+func Test_MfaEnrollHandler_Handle_requires_otp_to_enroll_again(t *testing.T) {
+	// Arrange
+	mfa_store_mock := lib.MfaStoreMock{NextIsEnrolledResult: true}
+	sut := NewMfaEnrollHandler(&mfa_store_mock, newTestMfaUserStore())
+
+	// Act
+	res, err := sut.Handle(newTestMfaContext("pwd"), MfaEnrollRequest{})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrMfaEnrollOtpRequired))
+	assert.False(t, mfa_store_mock.EnrollCalled)
+}
+
+func Test_MfaEnrollHandler_Handle_enrolls_again_with_otp(t *testing.T) {
+	// Arrange
+	mfa_store_mock := lib.MfaStoreMock{
+		NextIsEnrolledResult: true,
+		NextEnrollResult:     &lib.MfaEnrollment{},
+	}
+	sut := NewMfaEnrollHandler(&mfa_store_mock, newTestMfaUserStore())
+
+	// Act
+	_, err := sut.Handle(newTestMfaContext("pwd", "otp"), MfaEnrollRequest{})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, mfa_store_mock.EnrollCalled)
+}
+
+func Test_MfaEnrollHandler_Handle_returns_error_when_enrollment_fails(t *testing.T) {
+	// Arrange
+	mfa_store_mock := lib.MfaStoreMock{NextEnrollError: errors.New("some-error")}
+	sut := NewMfaEnrollHandler(&mfa_store_mock, newTestMfaUserStore())
+
+	// Act
+	res, err := sut.Handle(newTestMfaContext("pwd"), MfaEnrollRequest{})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrMfaEnrollError))
+}
@@ -0,0 +1,113 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+var (
+	ErrPasswordChangeValidationError = errors.New("current or new password is empty")
+	ErrPasswordChangeForbidden       = errors.New("only users who logged in with their password can change it")
+	ErrPasswordChangeInvalidPassword = errors.New("current password is invalid")
+	ErrPasswordChangeTooManyAttempts = errors.New("too many failed attempts, try again later")
+	ErrPasswordChangePolicyViolation = errors.New("new password is not allowed")
+	ErrPasswordChangeError           = errors.New("error while changing password")
+)
+
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+	ClientIp        string `json:"-"`
+}
+
+type PasswordChangeResponse struct{}
+
+// PasswordChangeHandler lets users change their own password. The current password
+// is checked like a login, so a stolen token alone can't be used to take over the
+// account, and the refresh tokens of the user are revoked afterwards.
+type PasswordChangeHandler struct {
+	credentialStore   lib.CredentialStore
+	userStore         lib.UserStore
+	passwordPolicy    *lib.PasswordPolicy
+	loginThrottle     *lib.LoginThrottle
+	refreshTokenStore lib.RefreshTokenStore
+}
+
+func NewPasswordChangeHandler(credentialStore lib.CredentialStore, userStore lib.UserStore, passwordPolicy *lib.PasswordPolicy, loginThrottle *lib.LoginThrottle, refreshTokenStore lib.RefreshTokenStore) AppHandler[PasswordChangeRequest, PasswordChangeResponse] {
+	return &PasswordChangeHandler{
+		credentialStore:   credentialStore,
+		userStore:         userStore,
+		passwordPolicy:    passwordPolicy,
+		loginThrottle:     loginThrottle,
+		refreshTokenStore: refreshTokenStore,
+	}
+}
+
+func (h *PasswordChangeHandler) Handle(ctx context.Context, request PasswordChangeRequest) (*PasswordChangeResponse, error) {
+	if request.CurrentPassword == "" || request.NewPassword == "" {
+		return nil, ErrPasswordChangeValidationError
+	}
+
+	principal, ok := lib.PrincipalFromContext(ctx)
+	if !ok || principal.Subject == "" || !principal.HasAmr("pwd") {
+		return nil, ErrPasswordChangeForbidden
+	}
+
+	user, err := h.userStore.Get(principal.Subject)
+	if err != nil {
+		if errors.Is(err, lib.ErrUserStoreUnknownUser) {
+			return nil, ErrPasswordChangeForbidden
+		}
+
+		log.Printf("error while loading user %s: %s", principal.Subject, err)
+		return nil, ErrPasswordChangeError
+	}
+
+	if lockout := h.loginThrottle.TryAttempt(user.Username, request.ClientIp); lockout > 0 {
+		log.Printf("rejecting password change of %s from %s, locked out for %s", user.Username, request.ClientIp, lockout)
+		return nil, &RetryAfterError{Err: ErrPasswordChangeTooManyAttempts, RetryAfter: lockout}
+	}
+
+	if _, err := h.credentialStore.VerifyCredentials(user.Username, request.CurrentPassword); err != nil {
+		if errors.Is(err, lib.ErrCredentialStoreInvalidCredentials) {
+			log.Printf("invalid current password of %s from %s", user.Username, request.ClientIp)
+			return nil, ErrPasswordChangeInvalidPassword
+		}
+
+		log.Printf("error while verifying credentials for %s: %s", user.Username, err)
+		h.loginThrottle.Forgive(user.Username, request.ClientIp)
+		return nil, ErrPasswordChangeError
+	}
+
+	h.loginThrottle.RecordSuccess(user.Username, request.ClientIp)
+
+	if err := h.passwordPolicy.Check(request.NewPassword, user); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPasswordChangePolicyViolation, err)
+	}
+
+	if err := setPassword(h.userStore, h.refreshTokenStore, user.Id, request.NewPassword); err != nil {
+		log.Printf("error while changing password of %s: %s", user.Id, err)
+		return nil, ErrPasswordChangeError
+	}
+
+	log.Printf("%s changed its password", user.Id)
+	return &PasswordChangeResponse{}, nil
+}
+
+// setPassword stores the hash of the new password and revokes the refresh tokens
+// of the user, so sessions which may belong to someone else end.
+func setPassword(userStore lib.UserStore, refreshTokenStore lib.RefreshTokenStore, id string, password string) error {
+	passwordHash, err := lib.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := userStore.SetPasswordHash(id, passwordHash); err != nil {
+		return err
+	}
+
+	return refreshTokenStore.RevokeSubject(id)
+}
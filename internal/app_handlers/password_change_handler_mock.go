@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type PasswordChangeHandlerMock struct {
+	HandleCalled bool
+	LastRequest  PasswordChangeRequest
+	NextResponse *PasswordChangeResponse
+	NextError    error
+}
+
+func (m *PasswordChangeHandlerMock) Handle(ctx context.Context, request PasswordChangeRequest) (*PasswordChangeResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
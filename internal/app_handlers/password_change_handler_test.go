@@ -0,0 +1,194 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestPasswordPolicy() *lib.PasswordPolicy {
+	return lib.NewPasswordPolicy(12, 5, []string{"password1234"})
+}
+
+func newTestPasswordChangeContext(amr ...string) context.Context {
+	return lib.WithPrincipal(context.Background(), &lib.Principal{Subject: "some-id", Amr: amr})
+}
+
+func newTestPasswordChangeRequest() PasswordChangeRequest {
+	return PasswordChangeRequest{
+		CurrentPassword: "some-password",
+		NewPassword:     "correct horse battery",
+		ClientIp:        "192.0.2.1",
+	}
+}
+
+func Test_PasswordChangeHandler_Handle_returns_error_on_empty_passwords(t *testing.T) {
+	for _, req := range []PasswordChangeRequest{
+		{CurrentPassword: "", NewPassword: "correct horse battery"},
+		{CurrentPassword: "some-password", NewPassword: ""},
+	} {
+		// Arrange
+		user_store_mock := lib.UserStoreMock{}
+		sut := NewPasswordChangeHandler(&lib.CredentialStoreMock{}, &user_store_mock, newTestPasswordPolicy(), newTestLoginThrottle(), &lib.RefreshTokenStoreMock{})
+
+		// Act
+		res, err := sut.Handle(newTestPasswordChangeContext("pwd"), req)
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrPasswordChangeValidationError))
+		assert.False(t, user_store_mock.GetCalled)
+	}
+}
+
+func Test_PasswordChangeHandler_Handle_returns_error_without_password_login(t *testing.T) {
+	for _, ctx := range []context.Context{
+		context.Background(),
+		newTestPasswordChangeContext(),
+	} {
+		// Arrange
+		user_store_mock := lib.UserStoreMock{}
+		sut := NewPasswordChangeHandler(&lib.CredentialStoreMock{}, &user_store_mock, newTestPasswordPolicy(), newTestLoginThrottle(), &lib.RefreshTokenStoreMock{})
+
+		// Act
+		res, err := sut.Handle(ctx, newTestPasswordChangeRequest())
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrPasswordChangeForbidden))
+		assert.False(t, user_store_mock.GetCalled)
+	}
+}
+
+func Test_PasswordChangeHandler_Handle_returns_error_on_unknown_user(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{
+		NextGetError: lib.ErrUserStoreUnknownUser,
+	}
+	sut := NewPasswordChangeHandler(&lib.CredentialStoreMock{}, &user_store_mock, newTestPasswordPolicy(), newTestLoginThrottle(), &lib.RefreshTokenStoreMock{})
+
+	// Act
+	res, err := sut.Handle(newTestPasswordChangeContext("pwd"), newTestPasswordChangeRequest())
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrPasswordChangeForbidden))
+	assert.Equal(t, "some-id", user_store_mock.LastId)
+}
+
+func Test_PasswordChangeHandler_Handle_records_failure_on_invalid_current_password(t *testing.T) {
+	// Arrange
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsError: lib.ErrCredentialStoreInvalidCredentials,
+	}
+	user_store_mock := lib.UserStoreMock{
+		NextGetResult: &lib.User{Id: "some-id", Username: "some-user"},
+	}
+	username_attempts_mock := lib.LoginAttemptStoreMock{}
+	login_throttle := lib.NewLoginThrottle(&username_attempts_mock, &lib.LoginAttemptStoreMock{})
+	sut := NewPasswordChangeHandler(&credential_store_mock, &user_store_mock, newTestPasswordPolicy(), login_throttle, &lib.RefreshTokenStoreMock{})
+
+	// Act
+	res, err := sut.Handle(newTestPasswordChangeContext("pwd"), newTestPasswordChangeRequest())
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrPasswordChangeInvalidPassword))
+	assert.Equal(t, "some-user", credential_store_mock.LastUsername)
+	assert.Equal(t, "some-password", credential_store_mock.LastPassword)
+	assert.True(t, username_attempts_mock.TryAttemptCalled)
+	assert.Equal(t, "some-user", username_attempts_mock.LastKey)
+	assert.False(t, user_store_mock.SetPasswordHashCalled)
+}
+
+func Test_PasswordChangeHandler_Handle_returns_retry_after_when_locked_out(t *testing.T) {
+	// Arrange
+	credential_store_mock := lib.CredentialStoreMock{}
+	user_store_mock := lib.UserStoreMock{
+		NextGetResult: &lib.User{Id: "some-id", Username: "some-user"},
+	}
+	login_throttle := lib.NewLoginThrottle(&lib.LoginAttemptStoreMock{NextTryAttemptResult: time.Minute}, &lib.LoginAttemptStoreMock{})
+	sut := NewPasswordChangeHandler(&credential_store_mock, &user_store_mock, newTestPasswordPolicy(), login_throttle, &lib.RefreshTokenStoreMock{})
+
+	// Act
+	res, err := sut.Handle(newTestPasswordChangeContext("pwd"), newTestPasswordChangeRequest())
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrPasswordChangeTooManyAttempts))
+	var retry_after_err *RetryAfterError
+	require.True(t, errors.As(err, &retry_after_err))
+	assert.Equal(t, time.Minute, retry_after_err.RetryAfter)
+	assert.False(t, credential_store_mock.VerifyCredentialsCalled)
+}
+
+func Test_PasswordChangeHandler_Handle_returns_error_on_policy_violation(t *testing.T) {
+	// Arrange
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Id: "some-id", Username: "some-user"},
+	}
+	user_store_mock := lib.UserStoreMock{
+		NextGetResult: &lib.User{Id: "some-id", Username: "some-user"},
+	}
+	sut := NewPasswordChangeHandler(&credential_store_mock, &user_store_mock, newTestPasswordPolicy(), newTestLoginThrottle(), &lib.RefreshTokenStoreMock{})
+	req := newTestPasswordChangeRequest()
+	req.NewPassword = "password1234"
+
+	// Act
+	res, err := sut.Handle(newTestPasswordChangeContext("pwd"), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrPasswordChangePolicyViolation))
+	assert.Contains(t, err.Error(), lib.ErrPasswordPolicyBreached.Error())
+	assert.False(t, user_store_mock.SetPasswordHashCalled)
+}
+
+func Test_PasswordChangeHandler_Handle_sets_new_password_and_revokes_refresh_tokens(t *testing.T) {
+	// Arrange
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Id: "some-id", Username: "some-user"},
+	}
+	user_store_mock := lib.UserStoreMock{
+		NextGetResult: &lib.User{Id: "some-id", Username: "some-user"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewPasswordChangeHandler(&credential_store_mock, &user_store_mock, newTestPasswordPolicy(), newTestLoginThrottle(), &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(newTestPasswordChangeContext("pwd"), newTestPasswordChangeRequest())
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, "some-id", user_store_mock.LastId)
+	assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(user_store_mock.LastPasswordHash), []byte("correct horse battery")))
+	assert.Equal(t, "some-id", refresh_token_store_mock.LastSubject)
+}
+
+func Test_PasswordChangeHandler_Handle_returns_error_on_store_error(t *testing.T) {
+	// Arrange
+	credential_store_mock := lib.CredentialStoreMock{
+		NextVerifyCredentialsResult: &lib.User{Id: "some-id", Username: "some-user"},
+	}
+	user_store_mock := lib.UserStoreMock{
+		NextGetResult:            &lib.User{Id: "some-id", Username: "some-user"},
+		NextSetPasswordHashError: errors.New("some-error"),
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewPasswordChangeHandler(&credential_store_mock, &user_store_mock, newTestPasswordPolicy(), newTestLoginThrottle(), &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(newTestPasswordChangeContext("pwd"), newTestPasswordChangeRequest())
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrPasswordChangeError))
+	assert.False(t, refresh_token_store_mock.RevokeSubjectCalled)
+}
@@ -0,0 +1,119 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrPasswordForgotValidationError = errors.New("username is empty")
+	ErrPasswordForgotTooManyAttempts = errors.New("too many password reset requests, try again later")
+)
+
+type PasswordForgotRequest struct {
+	Username string `json:"username"`
+	ClientIp string `json:"-"`
+}
+
+type PasswordForgotResponse struct{}
+
+// PasswordForgotHandler sends a link with a reset token to the email claim of the
+// user. It succeeds for unknown users as well, so it doesn't reveal which
+// usernames exist. The link is sent in the background, otherwise the time the mail
+// server takes would tell known usernames apart.
+type PasswordForgotHandler struct {
+	userStore          lib.UserStore
+	passwordResetStore lib.PasswordResetStore
+	notifier           lib.Notifier
+	resetUrl           string
+	throttle           *lib.LoginThrottle
+	run                func(task func())
+}
+
+// NewPasswordForgotHandler adds the token as token query parameter to resetUrl,
+// the page behind it posts the token and the new password to the reset endpoint.
+// Every request counts against the username and the client ip in throttle, known
+// or not, so neither mailboxes can be flooded nor usernames probed.
+func NewPasswordForgotHandler(userStore lib.UserStore, passwordResetStore lib.PasswordResetStore, notifier lib.Notifier, resetUrl string, throttle *lib.LoginThrottle) AppHandler[PasswordForgotRequest, PasswordForgotResponse] {
+	return &PasswordForgotHandler{
+		userStore:          userStore,
+		passwordResetStore: passwordResetStore,
+		notifier:           notifier,
+		resetUrl:           resetUrl,
+		throttle:           throttle,
+		run: func(task func()) {
+			go task()
+		},
+	}
+}
+
+func (h *PasswordForgotHandler) Handle(ctx context.Context, request PasswordForgotRequest) (*PasswordForgotResponse, error) {
+	request.Username = strings.TrimSpace(request.Username)
+	if request.Username == "" {
+		return nil, ErrPasswordForgotValidationError
+	}
+
+	if lockout := h.throttle.TryAttempt(request.Username, request.ClientIp); lockout > 0 {
+		log.Printf("rejecting password reset of %s from %s, locked out for %s", request.Username, request.ClientIp, lockout)
+		return nil, &RetryAfterError{Err: ErrPasswordForgotTooManyAttempts, RetryAfter: lockout}
+	}
+
+	// failures are only logged, the response is the same whether a link was sent or not
+	username := request.Username
+	h.run(func() {
+		if err := h.sendResetLink(username); err != nil {
+			log.Printf("no password reset link sent for %s: %s", username, err)
+		}
+	})
+
+	return &PasswordForgotResponse{}, nil
+}
+
+func (h *PasswordForgotHandler) sendResetLink(username string) error {
+	user, err := h.userStore.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	if user.Disabled {
+		return errors.New("user is disabled")
+	}
+
+	email, _ := user.Claims["email"].(string)
+	if email == "" {
+		return errors.New("user has no email claim")
+	}
+
+	link, err := url.Parse(h.resetUrl)
+	if err != nil {
+		return err
+	}
+
+	token, err := h.passwordResetStore.Issue(user.Id)
+	if err != nil {
+		return err
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	err = h.notifier.Notify(lib.Notification{
+		Recipient: email,
+		Subject:   "Reset your password",
+		Body: fmt.Sprintf("A password reset was requested for your account %s.\n\n"+
+			"Open the link below to choose a new password, it can only be used once and expires soon:\n%s\n\n"+
+			"If you didn't request it, you can ignore this message.", user.Username, link.String()),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("sent password reset link to %s", user.Id)
+	return nil
+}
@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type PasswordForgotHandlerMock struct {
+	HandleCalled bool
+	LastRequest  PasswordForgotRequest
+	NextResponse *PasswordForgotResponse
+	NextError    error
+}
+
+func (m *PasswordForgotHandlerMock) Handle(ctx context.Context, request PasswordForgotRequest) (*PasswordForgotResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
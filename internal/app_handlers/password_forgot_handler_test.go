@@ -0,0 +1,173 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPasswordForgotHandler sends the link before Handle returns, so the tests
+// can check it.
+func newTestPasswordForgotHandler(userStore lib.UserStore, passwordResetStore lib.PasswordResetStore, notifier lib.Notifier, resetUrl string, throttle *lib.LoginThrottle) AppHandler[PasswordForgotRequest, PasswordForgotResponse] {
+	sut := NewPasswordForgotHandler(userStore, passwordResetStore, notifier, resetUrl, throttle)
+	sut.(*PasswordForgotHandler).run = func(task func()) {
+		task()
+	}
+
+	return sut
+}
+
+// blockingNotifier waits for release, like a slow mail server.
+type blockingNotifier struct {
+	release chan struct{}
+	sent    chan lib.Notification
+}
+
+func (n *blockingNotifier) Notify(notification lib.Notification) error {
+	<-n.release
+	n.sent <- notification
+	return nil
+}
+
+func Test_PasswordForgotHandler_Handle_returns_error_on_empty_username(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{}
+	sut := newTestPasswordForgotHandler(&user_store_mock, &lib.PasswordResetStoreMock{}, &lib.NotifierMock{}, "https://example.com/reset", newTestLoginThrottle())
+
+	// Act
+	res, err := sut.Handle(context.Background(), PasswordForgotRequest{Username: " "})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrPasswordForgotValidationError))
+	assert.False(t, user_store_mock.GetByUsernameCalled)
+}
+
+func Test_PasswordForgotHandler_Handle_sends_reset_link_to_email_of_user(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{
+		NextGetByUsernameResult: &lib.User{Id: "some-id", Username: "some-user", Claims: map[string]interface{}{"email": "some-user@example.com"}},
+	}
+	password_reset_store_mock := lib.PasswordResetStoreMock{
+		NextIssueResult: "some-token",
+	}
+	notifier_mock := lib.NotifierMock{}
+	sut := newTestPasswordForgotHandler(&user_store_mock, &password_reset_store_mock, &notifier_mock, "https://example.com/reset?lang=en", newTestLoginThrottle())
+
+	// Act
+	res, err := sut.Handle(context.Background(), PasswordForgotRequest{Username: " some-user "})
+
+	// Assert
+	require.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, "some-user", user_store_mock.LastUsername)
+	assert.Equal(t, "some-id", password_reset_store_mock.LastSubject)
+	assert.Equal(t, "some-user@example.com", notifier_mock.LastNotification.Recipient)
+	assert.Contains(t, notifier_mock.LastNotification.Body, "https://example.com/reset?lang=en&token=some-token")
+}
+
+func Test_PasswordForgotHandler_Handle_succeeds_without_sending_link(t *testing.T) {
+	for name, user_store_mock := range map[string]lib.UserStoreMock{
+		"unknown user":  {NextGetByUsernameError: lib.ErrUserStoreUnknownUser},
+		"disabled user": {NextGetByUsernameResult: &lib.User{Id: "some-id", Disabled: true, Claims: map[string]interface{}{"email": "some-user@example.com"}}},
+		"without email": {NextGetByUsernameResult: &lib.User{Id: "some-id"}},
+	} {
+		// Arrange
+		password_reset_store_mock := lib.PasswordResetStoreMock{}
+		notifier_mock := lib.NotifierMock{}
+		sut := newTestPasswordForgotHandler(&user_store_mock, &password_reset_store_mock, &notifier_mock, "https://example.com/reset", newTestLoginThrottle())
+
+		// Act
+		res, err := sut.Handle(context.Background(), PasswordForgotRequest{Username: "some-user"})
+
+		// Assert
+		assert.Nil(t, err, name)
+		assert.NotNil(t, res, name)
+		assert.False(t, password_reset_store_mock.IssueCalled, name)
+		assert.False(t, notifier_mock.NotifyCalled, name)
+	}
+}
+
+func Test_PasswordForgotHandler_Handle_succeeds_when_notification_fails(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{
+		NextGetByUsernameResult: &lib.User{Id: "some-id", Claims: map[string]interface{}{"email": "some-user@example.com"}},
+	}
+	notifier_mock := lib.NotifierMock{
+		NextNotifyError: errors.New("some-error"),
+	}
+	sut := newTestPasswordForgotHandler(&user_store_mock, &lib.PasswordResetStoreMock{}, &notifier_mock, "https://example.com/reset", newTestLoginThrottle())
+
+	// Act
+	res, err := sut.Handle(context.Background(), PasswordForgotRequest{Username: "some-user"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.True(t, notifier_mock.NotifyCalled)
+}
+
+func Test_PasswordForgotHandler_Handle_does_not_wait_for_notification(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{
+		NextGetByUsernameResult: &lib.User{Id: "some-id", Claims: map[string]interface{}{"email": "some-user@example.com"}},
+	}
+	notifier := &blockingNotifier{release: make(chan struct{}), sent: make(chan lib.Notification, 1)}
+	sut := NewPasswordForgotHandler(&user_store_mock, &lib.PasswordResetStoreMock{}, notifier, "https://example.com/reset", newTestLoginThrottle())
+
+	// Act
+	res, err := sut.Handle(context.Background(), PasswordForgotRequest{Username: "some-user"})
+	close(notifier.release)
+	notification := <-notifier.sent
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, "some-user@example.com", notification.Recipient)
+}
+
+func Test_PasswordForgotHandler_Handle_counts_request_against_username_and_ip(t *testing.T) {
+	// Arrange
+	username_attempts_mock := lib.LoginAttemptStoreMock{}
+	ip_attempts_mock := lib.LoginAttemptStoreMock{}
+	throttle := lib.NewLoginThrottle(&username_attempts_mock, &ip_attempts_mock)
+	user_store_mock := lib.UserStoreMock{NextGetByUsernameError: lib.ErrUserStoreUnknownUser}
+	sut := newTestPasswordForgotHandler(&user_store_mock, &lib.PasswordResetStoreMock{}, &lib.NotifierMock{}, "https://example.com/reset", throttle)
+
+	// Act
+	_, err := sut.Handle(context.Background(), PasswordForgotRequest{Username: "some-user", ClientIp: "192.0.2.1"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, username_attempts_mock.TryAttemptCalled)
+	assert.Equal(t, "some-user", username_attempts_mock.LastKey)
+	assert.True(t, ip_attempts_mock.TryAttemptCalled)
+	assert.Equal(t, "192.0.2.1", ip_attempts_mock.LastKey)
+	assert.False(t, username_attempts_mock.ResetCalled)
+	assert.False(t, ip_attempts_mock.ForgiveCalled)
+}
+
+func Test_PasswordForgotHandler_Handle_rejects_throttled_request_without_sending_link(t *testing.T) {
+	// Arrange
+	throttle := lib.NewLoginThrottle(&lib.LoginAttemptStoreMock{NextTryAttemptResult: time.Minute}, &lib.LoginAttemptStoreMock{})
+	user_store_mock := lib.UserStoreMock{}
+	notifier_mock := lib.NotifierMock{}
+	sut := newTestPasswordForgotHandler(&user_store_mock, &lib.PasswordResetStoreMock{}, &notifier_mock, "https://example.com/reset", throttle)
+
+	// Act
+	res, err := sut.Handle(context.Background(), PasswordForgotRequest{Username: "some-user", ClientIp: "192.0.2.1"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrPasswordForgotTooManyAttempts))
+	var retry_after_err *RetryAfterError
+	require.True(t, errors.As(err, &retry_after_err))
+	assert.Equal(t, time.Minute, retry_after_err.RetryAfter)
+	assert.False(t, user_store_mock.GetByUsernameCalled)
+	assert.False(t, notifier_mock.NotifyCalled)
+}
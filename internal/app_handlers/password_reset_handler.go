@@ -0,0 +1,96 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var (
+	ErrPasswordResetValidationError = errors.New("token or new password is empty")
+	ErrPasswordResetInvalidToken    = errors.New("invalid or expired reset token")
+	ErrPasswordResetPolicyViolation = errors.New("new password is not allowed")
+	ErrPasswordResetError           = errors.New("error while resetting password")
+)
+
+type PasswordResetRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+type PasswordResetResponse struct{}
+
+// PasswordResetHandler sets a new password with a token of PasswordForgotHandler.
+// The token is only used up once the new password is accepted by the policy.
+type PasswordResetHandler struct {
+	userStore          lib.UserStore
+	passwordResetStore lib.PasswordResetStore
+	passwordPolicy     *lib.PasswordPolicy
+	refreshTokenStore  lib.RefreshTokenStore
+}
+
+func NewPasswordResetHandler(userStore lib.UserStore, passwordResetStore lib.PasswordResetStore, passwordPolicy *lib.PasswordPolicy, refreshTokenStore lib.RefreshTokenStore) AppHandler[PasswordResetRequest, PasswordResetResponse] {
+	return &PasswordResetHandler{
+		userStore:          userStore,
+		passwordResetStore: passwordResetStore,
+		passwordPolicy:     passwordPolicy,
+		refreshTokenStore:  refreshTokenStore,
+	}
+}
+
+func (h *PasswordResetHandler) Handle(ctx context.Context, request PasswordResetRequest) (*PasswordResetResponse, error) {
+	request.Token = strings.TrimSpace(request.Token)
+	if request.Token == "" || request.NewPassword == "" {
+		return nil, ErrPasswordResetValidationError
+	}
+
+	subject, err := h.passwordResetStore.Lookup(request.Token)
+	if err != nil {
+		if errors.Is(err, lib.ErrPasswordResetStoreInvalidToken) {
+			return nil, ErrPasswordResetInvalidToken
+		}
+
+		log.Printf("error while looking up reset token: %s", err)
+		return nil, ErrPasswordResetError
+	}
+
+	user, err := h.userStore.Get(subject)
+	if err != nil {
+		if errors.Is(err, lib.ErrUserStoreUnknownUser) {
+			return nil, ErrPasswordResetInvalidToken
+		}
+
+		log.Printf("error while loading user %s: %s", subject, err)
+		return nil, ErrPasswordResetError
+	}
+
+	if user.Disabled {
+		log.Printf("rejecting password reset of disabled user %s", subject)
+		return nil, ErrPasswordResetInvalidToken
+	}
+
+	if err := h.passwordPolicy.Check(request.NewPassword, user); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPasswordResetPolicyViolation, err)
+	}
+
+	// redeeming fails when the token was used concurrently
+	if _, err := h.passwordResetStore.Redeem(request.Token); err != nil {
+		if errors.Is(err, lib.ErrPasswordResetStoreInvalidToken) {
+			return nil, ErrPasswordResetInvalidToken
+		}
+
+		log.Printf("error while redeeming reset token of %s: %s", subject, err)
+		return nil, ErrPasswordResetError
+	}
+
+	if err := setPassword(h.userStore, h.refreshTokenStore, user.Id, request.NewPassword); err != nil {
+		log.Printf("error while resetting password of %s: %s", user.Id, err)
+		return nil, ErrPasswordResetError
+	}
+
+	log.Printf("%s reset its password with a reset token", user.Id)
+	return &PasswordResetResponse{}, nil
+}
@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type PasswordResetHandlerMock struct {
+	HandleCalled bool
+	LastRequest  PasswordResetRequest
+	NextResponse *PasswordResetResponse
+	NextError    error
+}
+
+func (m *PasswordResetHandlerMock) Handle(ctx context.Context, request PasswordResetRequest) (*PasswordResetResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
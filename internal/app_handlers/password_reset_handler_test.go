@@ -0,0 +1,133 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_PasswordResetHandler_Handle_returns_error_on_empty_token_or_password(t *testing.T) {
+	for _, req := range []PasswordResetRequest{
+		{Token: " ", NewPassword: "correct horse battery"},
+		{Token: "some-token", NewPassword: ""},
+	} {
+		// Arrange
+		password_reset_store_mock := lib.PasswordResetStoreMock{}
+		sut := NewPasswordResetHandler(&lib.UserStoreMock{}, &password_reset_store_mock, newTestPasswordPolicy(), &lib.RefreshTokenStoreMock{})
+
+		// Act
+		res, err := sut.Handle(context.Background(), req)
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrPasswordResetValidationError))
+		assert.False(t, password_reset_store_mock.LookupCalled)
+	}
+}
+
+func Test_PasswordResetHandler_Handle_returns_error_on_invalid_token(t *testing.T) {
+	for name, user_store_mock := range map[string]lib.UserStoreMock{
+		"deleted user":  {NextGetError: lib.ErrUserStoreUnknownUser},
+		"disabled user": {NextGetResult: &lib.User{Id: "some-id", Disabled: true}},
+	} {
+		// Arrange
+		password_reset_store_mock := lib.PasswordResetStoreMock{
+			NextLookupResult: "some-id",
+		}
+		sut := NewPasswordResetHandler(&user_store_mock, &password_reset_store_mock, newTestPasswordPolicy(), &lib.RefreshTokenStoreMock{})
+
+		// Act
+		res, err := sut.Handle(context.Background(), PasswordResetRequest{Token: "some-token", NewPassword: "correct horse battery"})
+
+		// Assert
+		assert.Nil(t, res, name)
+		assert.True(t, errors.Is(err, ErrPasswordResetInvalidToken), name)
+		assert.False(t, password_reset_store_mock.RedeemCalled, name)
+	}
+}
+
+func Test_PasswordResetHandler_Handle_returns_error_on_unknown_token(t *testing.T) {
+	// Arrange
+	password_reset_store_mock := lib.PasswordResetStoreMock{
+		NextLookupError: lib.ErrPasswordResetStoreInvalidToken,
+	}
+	user_store_mock := lib.UserStoreMock{}
+	sut := NewPasswordResetHandler(&user_store_mock, &password_reset_store_mock, newTestPasswordPolicy(), &lib.RefreshTokenStoreMock{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), PasswordResetRequest{Token: "some-token", NewPassword: "correct horse battery"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrPasswordResetInvalidToken))
+	assert.Equal(t, "some-token", password_reset_store_mock.LastToken)
+	assert.False(t, user_store_mock.GetCalled)
+}
+
+func Test_PasswordResetHandler_Handle_keeps_token_on_policy_violation(t *testing.T) {
+	// Arrange
+	password_reset_store_mock := lib.PasswordResetStoreMock{
+		NextLookupResult: "some-id",
+	}
+	user_store_mock := lib.UserStoreMock{
+		NextGetResult: &lib.User{Id: "some-id"},
+	}
+	sut := NewPasswordResetHandler(&user_store_mock, &password_reset_store_mock, newTestPasswordPolicy(), &lib.RefreshTokenStoreMock{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), PasswordResetRequest{Token: "some-token", NewPassword: "too-short"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrPasswordResetPolicyViolation))
+	assert.False(t, password_reset_store_mock.RedeemCalled)
+	assert.False(t, user_store_mock.SetPasswordHashCalled)
+}
+
+func Test_PasswordResetHandler_Handle_returns_error_when_token_was_used_concurrently(t *testing.T) {
+	// Arrange
+	password_reset_store_mock := lib.PasswordResetStoreMock{
+		NextLookupResult: "some-id",
+		NextRedeemError:  lib.ErrPasswordResetStoreInvalidToken,
+	}
+	user_store_mock := lib.UserStoreMock{
+		NextGetResult: &lib.User{Id: "some-id"},
+	}
+	sut := NewPasswordResetHandler(&user_store_mock, &password_reset_store_mock, newTestPasswordPolicy(), &lib.RefreshTokenStoreMock{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), PasswordResetRequest{Token: "some-token", NewPassword: "correct horse battery"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrPasswordResetInvalidToken))
+	assert.False(t, user_store_mock.SetPasswordHashCalled)
+}
+
+func Test_PasswordResetHandler_Handle_sets_new_password_and_revokes_refresh_tokens(t *testing.T) {
+	// Arrange
+	password_reset_store_mock := lib.PasswordResetStoreMock{
+		NextLookupResult: "some-id",
+		NextRedeemResult: "some-id",
+	}
+	user_store_mock := lib.UserStoreMock{
+		NextGetResult: &lib.User{Id: "some-id"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewPasswordResetHandler(&user_store_mock, &password_reset_store_mock, newTestPasswordPolicy(), &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), PasswordResetRequest{Token: "some-token", NewPassword: "correct horse battery"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.True(t, password_reset_store_mock.RedeemCalled)
+	assert.Equal(t, "some-id", user_store_mock.LastId)
+	assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(user_store_mock.LastPasswordHash), []byte("correct horse battery")))
+	assert.Equal(t, "some-id", refresh_token_store_mock.LastSubject)
+}
@@ -0,0 +1,77 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"strings"
+)
+
+var (
+	ErrRefreshValidationError      = errors.New("refresh token is empty")
+	ErrRefreshInvalidToken         = errors.New("invalid refresh token")
+	ErrRefreshTokenGenerationError = errors.New("error generating token")
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RefreshHandler exchanges a refresh token for a new access token, the refresh
+// token is rotated so the response contains the token to use next time.
+type RefreshHandler struct {
+	oidcProvider      lib.OidcProvider
+	refreshTokenStore lib.RefreshTokenStore
+	lifetimes         lib.TokenLifetimes
+}
+
+// NewRefreshHandler issues tokens with the lifetime of the refresh_token grant.
+func NewRefreshHandler(oidcProvider lib.OidcProvider, refreshTokenStore lib.RefreshTokenStore, lifetimes lib.TokenLifetimes) AppHandler[RefreshRequest, AuthResponse] {
+	return &RefreshHandler{
+		oidcProvider:      oidcProvider,
+		refreshTokenStore: refreshTokenStore,
+		lifetimes:         lifetimes,
+	}
+}
+
+func (h *RefreshHandler) Handle(ctx context.Context, request RefreshRequest) (*AuthResponse, error) {
+	request.RefreshToken = strings.TrimSpace(request.RefreshToken)
+
+	if request.RefreshToken == "" {
+		return nil, ErrRefreshValidationError
+	}
+
+	grant, refreshToken, err := h.refreshTokenStore.Rotate(request.RefreshToken)
+	if err != nil {
+		if errors.Is(err, lib.ErrRefreshTokenStoreInvalidToken) || errors.Is(err, lib.ErrRefreshTokenStoreReusedToken) {
+			return nil, ErrRefreshInvalidToken
+		}
+
+		log.Printf("error while rotating refresh token: %s", err)
+		return nil, ErrRefreshTokenGenerationError
+	}
+
+	// tokens of OAuth clients are refreshed at /token, where the client authenticates
+	if grant.ClientId != "" {
+		log.Printf("refresh token of client %s presented without client authentication", grant.ClientId)
+		if err := h.refreshTokenStore.Revoke(refreshToken); err != nil {
+			log.Printf("error while revoking refresh token of client %s: %s", grant.ClientId, err)
+		}
+
+		return nil, ErrRefreshInvalidToken
+	}
+
+	token, err := h.oidcProvider.GenerateToken(grant.Subject, lib.TokenOptions{Scopes: grant.Scopes, Lifetime: h.lifetimes.Lifetime("refresh_token", nil), Amr: grant.Amr, Roles: grant.Roles})
+	if err != nil {
+		log.Printf("error while generating token for %s: %s", grant.Subject, err)
+		return nil, ErrRefreshTokenGenerationError
+	}
+
+	response := &AuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}
+
+	return response, nil
+}
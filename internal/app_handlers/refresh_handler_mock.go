@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type RefreshHandlerMock struct {
+	HandleCalled bool
+	LastRequest  RefreshRequest
+	NextResponse *AuthResponse
+	NextError    error
+}
+
+func (m *RefreshHandlerMock) Handle(ctx context.Context, request RefreshRequest) (*AuthResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
@@ -0,0 +1,130 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RefreshHandler_Handle_returns_error_on_empty_refresh_token(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewRefreshHandler(&oidc_provider_mock, &refresh_token_store_mock, lib.TokenLifetimes{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), RefreshRequest{RefreshToken: "  "})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrRefreshValidationError))
+	assert.False(t, refresh_token_store_mock.RotateCalled)
+}
+
+func Test_RefreshHandler_Handle_returns_new_tokens_for_subject_of_grant(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextGenerateTokenResult: "some-token",
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextRotateGrant:  &lib.RefreshGrant{Subject: "some-username", Scopes: []string{"some-scope"}, Amr: []string{"pwd"}, Roles: []string{"analyst"}},
+		NextRotateResult: "next-refresh-token",
+	}
+	sut := NewRefreshHandler(&oidc_provider_mock, &refresh_token_store_mock, lib.TokenLifetimes{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), RefreshRequest{RefreshToken: "some-refresh-token"})
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "some-refresh-token", refresh_token_store_mock.LastToken)
+	assert.Equal(t, "some-username", oidc_provider_mock.LastUsername)
+	assert.Equal(t, []string{"some-scope"}, oidc_provider_mock.LastTokenOptions.Scopes)
+	assert.Equal(t, []string{"pwd"}, oidc_provider_mock.LastTokenOptions.Amr)
+	assert.Equal(t, []string{"analyst"}, oidc_provider_mock.LastTokenOptions.Roles)
+	assert.Equal(t, "some-token", res.Token)
+	assert.Equal(t, "next-refresh-token", res.RefreshToken)
+}
+
+func Test_RefreshHandler_Handle_returns_error_on_invalid_or_reused_refresh_token(t *testing.T) {
+	for _, store_err := range []error{lib.ErrRefreshTokenStoreInvalidToken, lib.ErrRefreshTokenStoreReusedToken} {
+		// Arrange
+		oidc_provider_mock := lib.OidcProviderMock{}
+		refresh_token_store_mock := lib.RefreshTokenStoreMock{
+			NextRotateError: store_err,
+		}
+		sut := NewRefreshHandler(&oidc_provider_mock, &refresh_token_store_mock, lib.TokenLifetimes{})
+
+		// Act
+		res, err := sut.Handle(context.Background(), RefreshRequest{RefreshToken: "some-refresh-token"})
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrRefreshInvalidToken))
+		assert.False(t, oidc_provider_mock.GenerateTokenCalled)
+	}
+}
+
+func Test_RefreshHandler_Handle_returns_error_when_token_generation_fails(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextGenerateTokenError: errors.New("some-error"),
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextRotateGrant: &lib.RefreshGrant{Subject: "some-username"},
+	}
+	sut := NewRefreshHandler(&oidc_provider_mock, &refresh_token_store_mock, lib.TokenLifetimes{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), RefreshRequest{RefreshToken: "some-refresh-token"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrRefreshTokenGenerationError))
+}
+
+func Test_RefreshHandler_Handle_revokes_refresh_token_of_oauth_client(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextRotateGrant:  &lib.RefreshGrant{Subject: "some-user", ClientId: "some-client"},
+		NextRotateResult: "next-refresh-token",
+	}
+	sut := NewRefreshHandler(&oidc_provider_mock, &refresh_token_store_mock, lib.TokenLifetimes{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), RefreshRequest{RefreshToken: "some-refresh-token"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrRefreshInvalidToken))
+	assert.True(t, refresh_token_store_mock.RevokeCalled)
+	assert.Equal(t, "next-refresh-token", refresh_token_store_mock.LastToken)
+	assert.False(t, oidc_provider_mock.GenerateTokenCalled)
+}
+
+func Test_RefreshHandler_Handle_issues_token_with_lifetime_of_refresh_token_grant(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextRotateGrant:  &lib.RefreshGrant{Subject: "some-user"},
+		NextRotateResult: "next-refresh-token",
+	}
+	lifetimes := lib.TokenLifetimes{
+		Grants: map[string]time.Duration{"refresh_token": 20 * time.Minute},
+	}
+	sut := NewRefreshHandler(&oidc_provider_mock, &refresh_token_store_mock, lifetimes)
+
+	// Act
+	_, err := sut.Handle(context.Background(), RefreshRequest{RefreshToken: "some-refresh-token"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 20*time.Minute, oidc_provider_mock.LastTokenOptions.Lifetime)
+}
@@ -0,0 +1,134 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRevokeValidationError = errors.New("token is empty")
+	ErrRevokeError           = errors.New("error revoking token")
+)
+
+// RevokeRequest carries the id of the authenticated client, it is empty when the
+// holder of a token revokes it without a client.
+type RevokeRequest struct {
+	Token         string
+	TokenTypeHint string
+	ClientId      string
+}
+
+type RevokeResponse struct{}
+
+// RevokeHandler implements RFC 7009 token revocation. Access tokens are revoked by
+// their jti, refresh tokens revoke their whole family. Invalid tokens are not an
+// error, there is nothing left to revoke. Tokens issued to a client can only be
+// revoked by that client, tokens of logins at /auth belong to no client and are
+// revoked by whoever holds them. Tokens of other clients are treated like invalid
+// ones as §2.1 asks, so clients can't probe for them.
+type RevokeHandler struct {
+	oidcProvider      lib.OidcProvider
+	revocationStore   lib.RevocationStore
+	refreshTokenStore lib.RefreshTokenStore
+}
+
+func NewRevokeHandler(oidcProvider lib.OidcProvider, revocationStore lib.RevocationStore, refreshTokenStore lib.RefreshTokenStore) AppHandler[RevokeRequest, RevokeResponse] {
+	return &RevokeHandler{
+		oidcProvider:      oidcProvider,
+		revocationStore:   revocationStore,
+		refreshTokenStore: refreshTokenStore,
+	}
+}
+
+func (h *RevokeHandler) Handle(ctx context.Context, request RevokeRequest) (*RevokeResponse, error) {
+	request.Token = strings.TrimSpace(request.Token)
+
+	if request.Token == "" {
+		return nil, ErrRevokeValidationError
+	}
+
+	// the hint only changes the order, a wrong hint must not prevent revocation
+	if request.TokenTypeHint == "refresh_token" {
+		found, err := h.revokeRefreshToken(request.Token, request.ClientId)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			h.revokeAccessToken(request.Token, request.ClientId)
+		}
+
+		return &RevokeResponse{}, nil
+	}
+
+	if !h.revokeAccessToken(request.Token, request.ClientId) {
+		if _, err := h.revokeRefreshToken(request.Token, request.ClientId); err != nil {
+			return nil, err
+		}
+	}
+
+	return &RevokeResponse{}, nil
+}
+
+// revokeRefreshToken is false for tokens which are not refresh tokens of the store,
+// refresh tokens the caller doesn't own are found but not revoked.
+func (h *RevokeHandler) revokeRefreshToken(token string, clientId string) (bool, error) {
+	grant, err := h.refreshTokenStore.Grant(token)
+	if errors.Is(err, lib.ErrRefreshTokenStoreInvalidToken) {
+		return false, nil
+	}
+
+	if err != nil {
+		log.Printf("error while looking up refresh token: %s", err)
+		return false, ErrRevokeError
+	}
+
+	if !mayRevoke(grant.ClientId, clientId) {
+		log.Printf("client %q tried to revoke refresh token of client %s for %s", clientId, grant.ClientId, grant.Subject)
+		return true, nil
+	}
+
+	err = h.refreshTokenStore.Revoke(token)
+	if err != nil && !errors.Is(err, lib.ErrRefreshTokenStoreInvalidToken) {
+		log.Printf("error while revoking refresh token: %s", err)
+		return false, ErrRevokeError
+	}
+
+	log.Printf("revoked refresh token of %s", grant.Subject)
+	return true, nil
+}
+
+// revokeAccessToken is false for tokens which are not access tokens of the provider,
+// access tokens the caller doesn't own are found but not revoked.
+func (h *RevokeHandler) revokeAccessToken(token string, clientId string) bool {
+	claims, err := h.oidcProvider.ValidateToken(token)
+	if err != nil {
+		return false
+	}
+
+	jti, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+	if jti == "" {
+		return false
+	}
+
+	issuedTo, _ := claims["client_id"].(string)
+	if !mayRevoke(issuedTo, clientId) {
+		log.Printf("client %q tried to revoke token %s of client %s for %v", clientId, jti, issuedTo, claims["sub"])
+		return true
+	}
+
+	h.revocationStore.Revoke(jti, time.Unix(int64(exp), 0))
+	log.Printf("revoked token %s of %v", jti, claims["sub"])
+	return true
+}
+
+// mayRevoke lets clients revoke the tokens issued to them, tokens without a client
+// come from logins at /auth and holding them is enough to revoke them.
+func mayRevoke(issuedTo string, clientId string) bool {
+	return issuedTo == "" || issuedTo == clientId
+}
@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type RevokeHandlerMock struct {
+	HandleCalled bool
+	LastRequest  RevokeRequest
+	NextResponse *RevokeResponse
+	NextError    error
+}
+
+func (m *RevokeHandlerMock) Handle(ctx context.Context, request RevokeRequest) (*RevokeResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
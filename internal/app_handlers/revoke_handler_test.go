@@ -0,0 +1,256 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RevokeHandler_Handle_returns_error_on_empty_token(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	revocation_store_mock := lib.RevocationStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), RevokeRequest{Token: " ", ClientId: "some-client"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrRevokeValidationError))
+}
+
+func Test_RevokeHandler_Handle_does_not_revoke_access_token_of_client_without_client(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextValidateTokenResult: map[string]interface{}{"jti": "some-jti", "exp": float64(946785845), "client_id": "some-client"},
+	}
+	revocation_store_mock := lib.RevocationStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), RevokeRequest{Token: "some-token"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.False(t, revocation_store_mock.RevokeCalled)
+}
+
+func Test_RevokeHandler_Handle_revokes_jti_of_access_token_until_it_expires(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextValidateTokenResult: map[string]interface{}{"jti": "some-jti", "exp": float64(946785845), "client_id": "some-client"},
+	}
+	revocation_store_mock := lib.RevocationStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), RevokeRequest{Token: "some-token", ClientId: "some-client"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, "some-token", oidc_provider_mock.LastToken)
+	assert.True(t, revocation_store_mock.RevokeCalled)
+	assert.Equal(t, "some-jti", revocation_store_mock.LastJti)
+	assert.Equal(t, time.Unix(946785845, 0), revocation_store_mock.LastExpiresAt)
+	assert.False(t, refresh_token_store_mock.RevokeCalled)
+}
+
+func Test_RevokeHandler_Handle_does_not_revoke_access_token_of_other_client(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextValidateTokenResult: map[string]interface{}{"jti": "some-jti", "exp": float64(946785845), "aud": "some-client", "client_id": "other-client"},
+	}
+	revocation_store_mock := lib.RevocationStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), RevokeRequest{Token: "some-token", ClientId: "some-client"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.False(t, revocation_store_mock.RevokeCalled)
+	assert.False(t, refresh_token_store_mock.GrantCalled)
+	assert.False(t, refresh_token_store_mock.RevokeCalled)
+}
+
+func Test_RevokeHandler_Handle_revokes_access_token_of_login(t *testing.T) {
+	for _, clientId := range []string{"", "some-client"} {
+		// Arrange
+		oidc_provider_mock := lib.OidcProviderMock{
+			NextValidateTokenResult: map[string]interface{}{"jti": "some-jti", "exp": float64(946785845), "aud": "some-audience"},
+		}
+		revocation_store_mock := lib.RevocationStoreMock{}
+		refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+		sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+		// Act
+		res, err := sut.Handle(context.Background(), RevokeRequest{Token: "some-token", ClientId: clientId})
+
+		// Assert
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.True(t, revocation_store_mock.RevokeCalled)
+		assert.Equal(t, "some-jti", revocation_store_mock.LastJti)
+	}
+}
+
+func Test_RevokeHandler_Handle_revokes_refresh_token_when_not_an_access_token(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextValidateTokenError: errors.New("some-error"),
+	}
+	revocation_store_mock := lib.RevocationStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextGrantResult: &lib.RefreshGrant{Subject: "some-user", ClientId: "some-client"},
+	}
+	sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), RevokeRequest{Token: "some-refresh-token", ClientId: "some-client"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.False(t, revocation_store_mock.RevokeCalled)
+	assert.True(t, refresh_token_store_mock.RevokeCalled)
+	assert.Equal(t, "some-refresh-token", refresh_token_store_mock.LastToken)
+}
+
+func Test_RevokeHandler_Handle_does_not_revoke_refresh_token_of_other_client(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextValidateTokenError: errors.New("some-error"),
+	}
+	revocation_store_mock := lib.RevocationStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextGrantResult: &lib.RefreshGrant{Subject: "some-user", ClientId: "other-client"},
+	}
+	sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), RevokeRequest{Token: "some-refresh-token", TokenTypeHint: "refresh_token", ClientId: "some-client"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.True(t, refresh_token_store_mock.GrantCalled)
+	assert.False(t, refresh_token_store_mock.RevokeCalled)
+	assert.False(t, oidc_provider_mock.ValidateTokenCalled)
+	assert.False(t, revocation_store_mock.RevokeCalled)
+}
+
+func Test_RevokeHandler_Handle_does_not_revoke_refresh_token_of_client_without_client(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextValidateTokenError: errors.New("some-error"),
+	}
+	revocation_store_mock := lib.RevocationStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextGrantResult: &lib.RefreshGrant{Subject: "some-user", ClientId: "some-client"},
+	}
+	sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), RevokeRequest{Token: "some-refresh-token"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.True(t, refresh_token_store_mock.GrantCalled)
+	assert.False(t, refresh_token_store_mock.RevokeCalled)
+}
+
+func Test_RevokeHandler_Handle_revokes_refresh_token_of_login(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextValidateTokenError: errors.New("some-error"),
+	}
+	revocation_store_mock := lib.RevocationStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextGrantResult: &lib.RefreshGrant{Subject: "some-user"},
+	}
+	sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), RevokeRequest{Token: "some-refresh-token"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.True(t, refresh_token_store_mock.RevokeCalled)
+	assert.Equal(t, "some-refresh-token", refresh_token_store_mock.LastToken)
+}
+
+func Test_RevokeHandler_Handle_revokes_access_token_with_wrong_refresh_token_hint(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextValidateTokenResult: map[string]interface{}{"jti": "some-jti", "exp": float64(946785845), "client_id": "some-client"},
+	}
+	revocation_store_mock := lib.RevocationStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextGrantError: lib.ErrRefreshTokenStoreInvalidToken,
+	}
+	sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), RevokeRequest{Token: "some-token", TokenTypeHint: "refresh_token", ClientId: "some-client"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.True(t, refresh_token_store_mock.GrantCalled)
+	assert.False(t, refresh_token_store_mock.RevokeCalled)
+	assert.True(t, revocation_store_mock.RevokeCalled)
+	assert.Equal(t, "some-jti", revocation_store_mock.LastJti)
+}
+
+func Test_RevokeHandler_Handle_succeeds_for_unknown_token(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextValidateTokenError: errors.New("some-error"),
+	}
+	revocation_store_mock := lib.RevocationStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextGrantError: lib.ErrRefreshTokenStoreInvalidToken,
+	}
+	sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), RevokeRequest{Token: "some-token", ClientId: "some-client"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.False(t, revocation_store_mock.RevokeCalled)
+	assert.False(t, refresh_token_store_mock.RevokeCalled)
+}
+
+func Test_RevokeHandler_Handle_returns_error_when_refresh_token_revocation_fails(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	revocation_store_mock := lib.RevocationStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextGrantResult: &lib.RefreshGrant{Subject: "some-user", ClientId: "some-client"},
+		NextRevokeError: errors.New("some-error"),
+	}
+	sut := NewRevokeHandler(&oidc_provider_mock, &revocation_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), RevokeRequest{Token: "some-refresh-token", TokenTypeHint: "refresh_token", ClientId: "some-client"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrRevokeError))
+}
@@ -2,9 +2,12 @@ package app_handlers
 
 import (
 	"bytes"
+	"coding_exercise/internal/lib"
+	"context"
 	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
+	"log"
 )
 
 type SumRequest interface{}
@@ -21,7 +24,11 @@ func NewSumHandler() AppHandler[SumRequest, SumResponse] {
 	return &SumHandler{}
 }
 
-func (h *SumHandler) Handle(request SumRequest) (*SumResponse, error) {
+func (h *SumHandler) Handle(ctx context.Context, request SumRequest) (*SumResponse, error) {
+	if principal, ok := lib.PrincipalFromContext(ctx); ok {
+		log.Printf("calculating sum for %s\n", principal.Subject)
+	}
+
 	sum := h.calculateSum(request)
 	sum_bytes, err := h.float64ToBytes(sum)
 	if err != nil {
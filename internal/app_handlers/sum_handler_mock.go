@@ -1,14 +1,20 @@
 package app_handlers
 
+import (
+	"context"
+)
+
 type SumHandlerMock struct {
 	HandleCalled bool
+	LastContext  context.Context
 	LastRequest  SumRequest
 	NextResponse *SumResponse
 	NextError    error
 }
 
-func (m *SumHandlerMock) Handle(req SumRequest) (*SumResponse, error) {
+func (m *SumHandlerMock) Handle(ctx context.Context, req SumRequest) (*SumResponse, error) {
 	m.HandleCalled = true
+	m.LastContext = ctx
 	m.LastRequest = req
 	return m.NextResponse, m.NextError
 }
@@ -1,6 +1,7 @@
 package app_handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -106,7 +107,7 @@ func Test_SumHandler_Handle_calculates_and_formats_sha256(t *testing.T) {
 	require.Nil(t, err)
 
 	// Act
-	res, err := sut.Handle(req)
+	res, err := sut.Handle(context.Background(), req)
 
 	// Assert
 	assert.Nil(t, err)
@@ -0,0 +1,293 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// the error messages are the error codes of RFC 6749 section 5.2
+var (
+	ErrTokenInvalidRequest       = errors.New("invalid_request")
+	ErrTokenInvalidClient        = errors.New("invalid_client")
+	ErrTokenInvalidGrant         = errors.New("invalid_grant")
+	ErrTokenUnsupportedGrantType = errors.New("unsupported_grant_type")
+	ErrTokenInvalidScope         = errors.New("invalid_scope")
+	ErrTokenServerError          = errors.New("server_error")
+)
+
+type TokenRequest struct {
+	GrantType    string
+	ClientId     string
+	ClientSecret string
+	Scope        string
+	Code         string
+	RedirectUri  string
+	CodeVerifier string
+	RefreshToken string
+}
+
+// TokenResponse is the access token response of RFC 6749 section 5.1.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IdToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// TokenHandler is the OAuth 2 token endpoint. The client_credentials grant issues
+// tokens with the client as subject, the authorization_code grant exchanges a code
+// from the authorization endpoint for tokens of the user and the refresh_token
+// grant rotates the refresh tokens issued with them.
+type TokenHandler struct {
+	oidcProvider           lib.OidcProvider
+	clientStore            lib.ClientStore
+	authorizationCodeStore lib.AuthorizationCodeStore
+	refreshTokenStore      lib.RefreshTokenStore
+	lifetimes              lib.TokenLifetimes
+}
+
+func NewTokenHandler(oidcProvider lib.OidcProvider, clientStore lib.ClientStore, authorizationCodeStore lib.AuthorizationCodeStore, refreshTokenStore lib.RefreshTokenStore, lifetimes lib.TokenLifetimes) AppHandler[TokenRequest, TokenResponse] {
+	return &TokenHandler{
+		oidcProvider:           oidcProvider,
+		clientStore:            clientStore,
+		authorizationCodeStore: authorizationCodeStore,
+		refreshTokenStore:      refreshTokenStore,
+		lifetimes:              lifetimes,
+	}
+}
+
+func (h *TokenHandler) Handle(ctx context.Context, request TokenRequest) (*TokenResponse, error) {
+	if request.GrantType == "" {
+		return nil, ErrTokenInvalidRequest
+	}
+
+	switch request.GrantType {
+	case "client_credentials":
+		return h.clientCredentials(request)
+	case "authorization_code":
+		return h.authorizationCode(request)
+	case "refresh_token":
+		return h.refreshToken(request)
+	default:
+		return nil, ErrTokenUnsupportedGrantType
+	}
+}
+
+func (h *TokenHandler) clientCredentials(request TokenRequest) (*TokenResponse, error) {
+	if request.ClientId == "" || request.ClientSecret == "" {
+		return nil, ErrTokenInvalidClient
+	}
+
+	client, err := h.clientStore.VerifyClient(request.ClientId, request.ClientSecret)
+	if err != nil {
+		if errors.Is(err, lib.ErrClientStoreInvalidClient) {
+			log.Printf("invalid client credentials for %s", request.ClientId)
+			return nil, ErrTokenInvalidClient
+		}
+
+		log.Printf("error while verifying client %s: %s", request.ClientId, err)
+		return nil, ErrTokenServerError
+	}
+
+	scopes, err := grantedScopes(request.Scope, client.Scopes)
+	if err != nil {
+		log.Printf("client %s requested scopes it was not granted: %s", client.Id, request.Scope)
+		return nil, err
+	}
+
+	lifetime := h.lifetimes.Lifetime(request.GrantType, client)
+	token, err := h.oidcProvider.GenerateToken(client.Id, lib.TokenOptions{Scopes: scopes, Lifetime: lifetime, ClientId: client.Id, ClientSubject: true})
+	if err != nil {
+		log.Printf("error while generating token for client %s: %s", client.Id, err)
+		return nil, ErrTokenServerError
+	}
+
+	response := &TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(lifetime / time.Second),
+		Scope:       strings.Join(scopes, " "),
+	}
+
+	return response, nil
+}
+
+func (h *TokenHandler) authorizationCode(request TokenRequest) (*TokenResponse, error) {
+	if request.Code == "" || request.RedirectUri == "" || request.CodeVerifier == "" {
+		return nil, ErrTokenInvalidRequest
+	}
+
+	client, err := h.authenticateClient(request)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := h.authorizationCodeStore.Redeem(request.Code)
+	if err != nil {
+		if errors.Is(err, lib.ErrAuthorizationCodeStoreInvalidCode) {
+			log.Printf("invalid authorization code presented by client %s", client.Id)
+			return nil, ErrTokenInvalidGrant
+		}
+
+		log.Printf("error while redeeming authorization code of client %s: %s", client.Id, err)
+		return nil, ErrTokenServerError
+	}
+
+	// the code is bound to the client, its redirect uri and the PKCE challenge
+	if grant.ClientId != client.Id || grant.RedirectUri != request.RedirectUri || !lib.VerifyCodeChallenge(request.CodeVerifier, grant.CodeChallenge) {
+		log.Printf("authorization code of client %s presented by client %s does not match", grant.ClientId, client.Id)
+		return nil, ErrTokenInvalidGrant
+	}
+
+	lifetime := h.lifetimes.Lifetime(request.GrantType, client)
+	token, err := h.oidcProvider.GenerateToken(grant.Subject, lib.TokenOptions{Scopes: grant.Scopes, Lifetime: lifetime, Amr: grant.Amr, Roles: grant.Roles, ClientId: client.Id})
+	if err != nil {
+		log.Printf("error while generating token for %s: %s", grant.Subject, err)
+		return nil, ErrTokenServerError
+	}
+
+	refreshToken, err := h.refreshTokenStore.Issue(lib.RefreshGrant{Subject: grant.Subject, ClientId: client.Id, Scopes: grant.Scopes, Amr: grant.Amr, Roles: grant.Roles})
+	if err != nil {
+		log.Printf("error while issuing refresh token for %s: %s", grant.Subject, err)
+		return nil, ErrTokenServerError
+	}
+
+	response := &TokenResponse{
+		AccessToken:  token,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(lifetime / time.Second),
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(grant.Scopes, " "),
+	}
+
+	if containsScope(grant.Scopes, "openid") {
+		response.IdToken, err = h.oidcProvider.GenerateIdToken(grant.Subject, lib.IdTokenOptions{
+			ClientId: client.Id,
+			Nonce:    grant.Nonce,
+			AuthTime: grant.AuthTime,
+			Amr:      grant.Amr,
+		})
+		if err != nil {
+			log.Printf("error while generating id token for %s: %s", grant.Subject, err)
+			return nil, ErrTokenServerError
+		}
+	}
+
+	return response, nil
+}
+
+// refreshToken issues tokens for the scopes of the original grant, refresh tokens
+// can only be redeemed by the client they were issued to.
+func (h *TokenHandler) refreshToken(request TokenRequest) (*TokenResponse, error) {
+	if request.RefreshToken == "" {
+		return nil, ErrTokenInvalidRequest
+	}
+
+	client, err := h.authenticateClient(request)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, refreshToken, err := h.refreshTokenStore.Rotate(request.RefreshToken)
+	if err != nil {
+		if errors.Is(err, lib.ErrRefreshTokenStoreInvalidToken) || errors.Is(err, lib.ErrRefreshTokenStoreReusedToken) {
+			log.Printf("invalid refresh token presented by client %s", client.Id)
+			return nil, ErrTokenInvalidGrant
+		}
+
+		log.Printf("error while rotating refresh token of client %s: %s", client.Id, err)
+		return nil, ErrTokenServerError
+	}
+
+	// another client holds a stolen token, the family is revoked like on reuse
+	if grant.ClientId != client.Id {
+		log.Printf("refresh token of client %q presented by client %s", grant.ClientId, client.Id)
+		if err := h.refreshTokenStore.Revoke(refreshToken); err != nil {
+			log.Printf("error while revoking refresh token of client %q: %s", grant.ClientId, err)
+		}
+
+		return nil, ErrTokenInvalidGrant
+	}
+
+	lifetime := h.lifetimes.Lifetime(request.GrantType, client)
+	token, err := h.oidcProvider.GenerateToken(grant.Subject, lib.TokenOptions{Scopes: grant.Scopes, Lifetime: lifetime, Amr: grant.Amr, Roles: grant.Roles, ClientId: client.Id})
+	if err != nil {
+		log.Printf("error while generating token for %s: %s", grant.Subject, err)
+		return nil, ErrTokenServerError
+	}
+
+	response := &TokenResponse{
+		AccessToken:  token,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(lifetime / time.Second),
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(grant.Scopes, " "),
+	}
+
+	return response, nil
+}
+
+// authenticateClient verifies the secret of confidential clients, public clients
+// only identify themselves and rely on PKCE.
+func (h *TokenHandler) authenticateClient(request TokenRequest) (*lib.Client, error) {
+	if request.ClientId == "" {
+		return nil, ErrTokenInvalidClient
+	}
+
+	var client *lib.Client
+	var err error
+
+	if request.ClientSecret != "" {
+		client, err = h.clientStore.VerifyClient(request.ClientId, request.ClientSecret)
+	} else {
+		client, err = h.clientStore.GetClient(request.ClientId)
+		if err == nil && !client.IsPublic() {
+			err = lib.ErrClientStoreInvalidClient
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, lib.ErrClientStoreInvalidClient) {
+			log.Printf("invalid client credentials for %s", request.ClientId)
+			return nil, ErrTokenInvalidClient
+		}
+
+		log.Printf("error while verifying client %s: %s", request.ClientId, err)
+		return nil, ErrTokenServerError
+	}
+
+	return client, nil
+}
+
+// grantedScopes returns all allowed scopes when none are requested, requesting a
+// scope which is not allowed fails the whole request.
+func grantedScopes(requested string, allowed []string) ([]string, error) {
+	if strings.TrimSpace(requested) == "" {
+		return allowed, nil
+	}
+
+	scopes := strings.Fields(requested)
+	for _, scope := range scopes {
+		if !containsScope(allowed, scope) {
+			return nil, ErrTokenInvalidScope
+		}
+	}
+
+	return scopes, nil
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, allowed := range scopes {
+		if allowed == scope {
+			return true
+		}
+	}
+
+	return false
+}
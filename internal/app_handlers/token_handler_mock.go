@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type TokenHandlerMock struct {
+	HandleCalled bool
+	LastRequest  TokenRequest
+	NextResponse *TokenResponse
+	NextError    error
+}
+
+func (m *TokenHandlerMock) Handle(ctx context.Context, request TokenRequest) (*TokenResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
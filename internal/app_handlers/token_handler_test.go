@@ -0,0 +1,508 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TokenHandler_Handle_returns_error_on_missing_grant_type(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	client_store_mock := lib.ClientStoreMock{}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), TokenRequest{})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrTokenInvalidRequest))
+}
+
+func Test_TokenHandler_Handle_returns_error_on_unsupported_grant_type(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	client_store_mock := lib.ClientStoreMock{}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), TokenRequest{GrantType: "password"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrTokenUnsupportedGrantType))
+}
+
+func Test_TokenHandler_Handle_returns_error_on_invalid_client(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	client_store_mock := lib.ClientStoreMock{
+		NextVerifyClientError: lib.ErrClientStoreInvalidClient,
+	}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+	req := TokenRequest{
+		GrantType:    "client_credentials",
+		ClientId:     "some-client",
+		ClientSecret: "wrong-secret",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrTokenInvalidClient))
+	assert.False(t, oidc_provider_mock.GenerateTokenCalled)
+}
+
+func Test_TokenHandler_Handle_returns_error_on_missing_client_credentials(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	client_store_mock := lib.ClientStoreMock{}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), TokenRequest{GrantType: "client_credentials"})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrTokenInvalidClient))
+	assert.False(t, client_store_mock.VerifyClientCalled)
+}
+
+func Test_TokenHandler_Handle_issues_token_for_client_with_all_scopes(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextGenerateTokenResult: "some-token",
+	}
+	client_store_mock := lib.ClientStoreMock{
+		NextVerifyClientResult: &lib.Client{Id: "some-client", Scopes: []string{"some-scope", "other-scope"}},
+	}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+	req := TokenRequest{
+		GrantType:    "client_credentials",
+		ClientId:     "some-client",
+		ClientSecret: "some-secret",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "some-client", client_store_mock.LastId)
+	assert.Equal(t, "some-secret", client_store_mock.LastSecret)
+	assert.Equal(t, "some-client", oidc_provider_mock.LastUsername)
+	assert.True(t, oidc_provider_mock.LastTokenOptions.ClientSubject)
+	assert.Equal(t, "some-token", res.AccessToken)
+	assert.Equal(t, "Bearer", res.TokenType)
+	assert.Equal(t, int64(3600), res.ExpiresIn)
+	assert.Equal(t, "some-scope other-scope", res.Scope)
+}
+
+func Test_TokenHandler_Handle_uses_token_lifetime_of_client(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextGenerateTokenResult: "some-token",
+	}
+	client_store_mock := lib.ClientStoreMock{
+		NextVerifyClientResult: &lib.Client{Id: "some-client", TokenLifetime: lib.Duration(5 * time.Minute)},
+	}
+	lifetimes := lib.TokenLifetimes{
+		Default: time.Hour,
+		Grants:  map[string]time.Duration{"client_credentials": 15 * time.Minute},
+	}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &lib.RefreshTokenStoreMock{}, lifetimes)
+	req := TokenRequest{
+		GrantType:    "client_credentials",
+		ClientId:     "some-client",
+		ClientSecret: "some-secret",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, 5*time.Minute, oidc_provider_mock.LastTokenOptions.Lifetime)
+	assert.Equal(t, int64(300), res.ExpiresIn)
+}
+
+func Test_TokenHandler_Handle_issues_token_with_requested_scopes(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	client_store_mock := lib.ClientStoreMock{
+		NextVerifyClientResult: &lib.Client{Id: "some-client", Scopes: []string{"some-scope", "other-scope"}},
+	}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+	req := TokenRequest{
+		GrantType:    "client_credentials",
+		ClientId:     "some-client",
+		ClientSecret: "some-secret",
+		Scope:        "other-scope",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, []string{"other-scope"}, oidc_provider_mock.LastTokenOptions.Scopes)
+	assert.Equal(t, "some-client", oidc_provider_mock.LastTokenOptions.ClientId)
+	assert.Equal(t, "other-scope", res.Scope)
+}
+
+func Test_TokenHandler_Handle_returns_error_on_scope_not_granted_to_client(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	client_store_mock := lib.ClientStoreMock{
+		NextVerifyClientResult: &lib.Client{Id: "some-client", Scopes: []string{"some-scope"}},
+	}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+	req := TokenRequest{
+		GrantType:    "client_credentials",
+		ClientId:     "some-client",
+		ClientSecret: "some-secret",
+		Scope:        "some-scope other-scope",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrTokenInvalidScope))
+	assert.False(t, oidc_provider_mock.GenerateTokenCalled)
+}
+
+func Test_TokenHandler_Handle_returns_error_when_token_generation_fails(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextGenerateTokenError: errors.New("some-error"),
+	}
+	client_store_mock := lib.ClientStoreMock{
+		NextVerifyClientResult: &lib.Client{Id: "some-client"},
+	}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+	req := TokenRequest{
+		GrantType:    "client_credentials",
+		ClientId:     "some-client",
+		ClientSecret: "some-secret",
+	}
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrTokenServerError))
+}
+
+// the code verifier and challenge of the example in RFC 7636 appendix B
+const (
+	testCodeVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testCodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func newTestAuthorizationCodeRequest() TokenRequest {
+	return TokenRequest{
+		GrantType:    "authorization_code",
+		ClientId:     "some-client",
+		Code:         "some-code",
+		RedirectUri:  "http://localhost/callback",
+		CodeVerifier: testCodeVerifier,
+	}
+}
+
+func newTestAuthorizationCodeStore() *lib.AuthorizationCodeStoreMock {
+	return &lib.AuthorizationCodeStoreMock{
+		NextRedeemResult: &lib.AuthorizationGrant{
+			ClientId:      "some-client",
+			RedirectUri:   "http://localhost/callback",
+			Subject:       "some-user",
+			Scopes:        []string{"some-scope"},
+			CodeChallenge: testCodeChallenge,
+			Amr:           []string{"pwd", "otp"},
+			Roles:         []string{"analyst"},
+		},
+	}
+}
+
+func Test_TokenHandler_Handle_exchanges_authorization_code_of_public_client(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextGenerateTokenResult: "some-token",
+	}
+	client_store_mock := lib.ClientStoreMock{
+		NextGetClientResult: &lib.Client{Id: "some-client"},
+	}
+	code_store_mock := newTestAuthorizationCodeStore()
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextIssueResult: "some-refresh-token",
+	}
+	lifetimes := lib.TokenLifetimes{
+		Grants: map[string]time.Duration{"authorization_code": 30 * time.Minute},
+	}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, code_store_mock, &refresh_token_store_mock, lifetimes)
+
+	// Act
+	res, err := sut.Handle(context.Background(), newTestAuthorizationCodeRequest())
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "some-code", code_store_mock.LastCode)
+	assert.Equal(t, "some-user", oidc_provider_mock.LastUsername)
+	assert.Equal(t, []string{"some-scope"}, oidc_provider_mock.LastTokenOptions.Scopes)
+	assert.Equal(t, []string{"pwd", "otp"}, oidc_provider_mock.LastTokenOptions.Amr)
+	assert.Equal(t, []string{"analyst"}, oidc_provider_mock.LastTokenOptions.Roles)
+	assert.Equal(t, "some-client", oidc_provider_mock.LastTokenOptions.ClientId)
+	assert.Equal(t, lib.RefreshGrant{Subject: "some-user", ClientId: "some-client", Scopes: []string{"some-scope"}, Amr: []string{"pwd", "otp"}, Roles: []string{"analyst"}}, refresh_token_store_mock.LastGrant)
+	assert.Equal(t, "some-token", res.AccessToken)
+	assert.Equal(t, "some-refresh-token", res.RefreshToken)
+	assert.Equal(t, "some-scope", res.Scope)
+	assert.Equal(t, 30*time.Minute, oidc_provider_mock.LastTokenOptions.Lifetime)
+	assert.Equal(t, int64(1800), res.ExpiresIn)
+}
+
+func Test_TokenHandler_Handle_returns_error_on_wrong_code_verifier(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	client_store_mock := lib.ClientStoreMock{
+		NextGetClientResult: &lib.Client{Id: "some-client"},
+	}
+	code_store_mock := newTestAuthorizationCodeStore()
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, code_store_mock, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+
+	req := newTestAuthorizationCodeRequest()
+	req.CodeVerifier = "eBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrTokenInvalidGrant))
+	assert.False(t, oidc_provider_mock.GenerateTokenCalled)
+}
+
+func Test_TokenHandler_Handle_returns_error_on_code_of_other_client(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	client_store_mock := lib.ClientStoreMock{
+		NextGetClientResult: &lib.Client{Id: "other-client"},
+	}
+	code_store_mock := newTestAuthorizationCodeStore()
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, code_store_mock, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+
+	req := newTestAuthorizationCodeRequest()
+	req.ClientId = "other-client"
+
+	// Act
+	res, err := sut.Handle(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrTokenInvalidGrant))
+}
+
+func Test_TokenHandler_Handle_returns_error_on_redeemed_code(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	client_store_mock := lib.ClientStoreMock{
+		NextGetClientResult: &lib.Client{Id: "some-client"},
+	}
+	code_store_mock := lib.AuthorizationCodeStoreMock{
+		NextRedeemError: lib.ErrAuthorizationCodeStoreInvalidCode,
+	}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &code_store_mock, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), newTestAuthorizationCodeRequest())
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrTokenInvalidGrant))
+}
+
+func Test_TokenHandler_Handle_returns_error_on_confidential_client_without_secret(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	client_store_mock := lib.ClientStoreMock{
+		NextGetClientResult: &lib.Client{Id: "some-client", SecretHash: "some-hash"},
+	}
+	code_store_mock := newTestAuthorizationCodeStore()
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, code_store_mock, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), newTestAuthorizationCodeRequest())
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrTokenInvalidClient))
+	assert.False(t, code_store_mock.RedeemCalled)
+}
+
+func Test_TokenHandler_Handle_issues_id_token_for_openid_scope(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextGenerateTokenResult:   "some-token",
+		NextGenerateIdTokenResult: "some-id-token",
+	}
+	client_store_mock := lib.ClientStoreMock{
+		NextGetClientResult: &lib.Client{Id: "some-client"},
+	}
+	code_store_mock := newTestAuthorizationCodeStore()
+	auth_time := time.Now().Add(-time.Minute)
+	code_store_mock.NextRedeemResult.Scopes = []string{"openid", "some-scope"}
+	code_store_mock.NextRedeemResult.Nonce = "some-nonce"
+	code_store_mock.NextRedeemResult.AuthTime = auth_time
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, code_store_mock, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), newTestAuthorizationCodeRequest())
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "some-token", res.AccessToken)
+	assert.Equal(t, "some-id-token", res.IdToken)
+	assert.Equal(t, lib.IdTokenOptions{ClientId: "some-client", Nonce: "some-nonce", AuthTime: auth_time, Amr: []string{"pwd", "otp"}}, oidc_provider_mock.LastIdTokenOptions)
+}
+
+func Test_TokenHandler_Handle_issues_no_id_token_without_openid_scope(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{}
+	client_store_mock := lib.ClientStoreMock{
+		NextGetClientResult: &lib.Client{Id: "some-client"},
+	}
+	code_store_mock := newTestAuthorizationCodeStore()
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, code_store_mock, &lib.RefreshTokenStoreMock{}, lib.TokenLifetimes{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), newTestAuthorizationCodeRequest())
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Empty(t, res.IdToken)
+	assert.False(t, oidc_provider_mock.GenerateIdTokenCalled)
+}
+
+func newTestRefreshTokenRequest() TokenRequest {
+	return TokenRequest{
+		GrantType:    "refresh_token",
+		ClientId:     "some-client",
+		RefreshToken: "some-refresh-token",
+	}
+}
+
+func Test_TokenHandler_Handle_rotates_refresh_token_of_client(t *testing.T) {
+	// Arrange
+	oidc_provider_mock := lib.OidcProviderMock{
+		NextGenerateTokenResult: "some-token",
+	}
+	client_store_mock := lib.ClientStoreMock{
+		NextGetClientResult: &lib.Client{Id: "some-client"},
+	}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{
+		NextRotateGrant:  &lib.RefreshGrant{Subject: "some-user", ClientId: "some-client", Scopes: []string{"some-scope"}, Amr: []string{"pwd"}, Roles: []string{"analyst"}},
+		NextRotateResult: "next-refresh-token",
+	}
+	lifetimes := lib.TokenLifetimes{
+		Grants: map[string]time.Duration{"refresh_token": 20 * time.Minute},
+	}
+	sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &refresh_token_store_mock, lifetimes)
+
+	// Act
+	res, err := sut.Handle(context.Background(), newTestRefreshTokenRequest())
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, res)
+	assert.Equal(t, "some-refresh-token", refresh_token_store_mock.LastToken)
+	assert.Equal(t, "some-user", oidc_provider_mock.LastUsername)
+	assert.Equal(t, lib.TokenOptions{Scopes: []string{"some-scope"}, Lifetime: 20 * time.Minute, Amr: []string{"pwd"}, Roles: []string{"analyst"}, ClientId: "some-client"}, oidc_provider_mock.LastTokenOptions)
+	assert.Equal(t, "some-token", res.AccessToken)
+	assert.Equal(t, "next-refresh-token", res.RefreshToken)
+	assert.Equal(t, int64(1200), res.ExpiresIn)
+	assert.Equal(t, "some-scope", res.Scope)
+	assert.False(t, refresh_token_store_mock.RevokeCalled)
+}
+
+func Test_TokenHandler_Handle_revokes_refresh_token_of_other_client(t *testing.T) {
+	for _, owner := range []string{"other-client", ""} {
+		// Arrange
+		oidc_provider_mock := lib.OidcProviderMock{}
+		client_store_mock := lib.ClientStoreMock{
+			NextGetClientResult: &lib.Client{Id: "some-client"},
+		}
+		refresh_token_store_mock := lib.RefreshTokenStoreMock{
+			NextRotateGrant:  &lib.RefreshGrant{Subject: "some-user", ClientId: owner},
+			NextRotateResult: "next-refresh-token",
+		}
+		sut := NewTokenHandler(&oidc_provider_mock, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &refresh_token_store_mock, lib.TokenLifetimes{})
+
+		// Act
+		res, err := sut.Handle(context.Background(), newTestRefreshTokenRequest())
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrTokenInvalidGrant))
+		assert.True(t, refresh_token_store_mock.RevokeCalled)
+		assert.Equal(t, "next-refresh-token", refresh_token_store_mock.LastToken)
+		assert.False(t, oidc_provider_mock.GenerateTokenCalled)
+	}
+}
+
+func Test_TokenHandler_Handle_returns_error_on_invalid_refresh_token(t *testing.T) {
+	for store_err, expected := range map[error]error{
+		lib.ErrRefreshTokenStoreInvalidToken: ErrTokenInvalidGrant,
+		lib.ErrRefreshTokenStoreReusedToken:  ErrTokenInvalidGrant,
+		errors.New("some-error"):             ErrTokenServerError,
+	} {
+		// Arrange
+		client_store_mock := lib.ClientStoreMock{
+			NextGetClientResult: &lib.Client{Id: "some-client"},
+		}
+		refresh_token_store_mock := lib.RefreshTokenStoreMock{
+			NextRotateError: store_err,
+		}
+		sut := NewTokenHandler(&lib.OidcProviderMock{}, &client_store_mock, &lib.AuthorizationCodeStoreMock{}, &refresh_token_store_mock, lib.TokenLifetimes{})
+
+		// Act
+		res, err := sut.Handle(context.Background(), newTestRefreshTokenRequest())
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, expected))
+	}
+}
+
+func Test_TokenHandler_Handle_returns_error_on_refresh_without_token_or_client(t *testing.T) {
+	for expected, req := range map[error]TokenRequest{
+		ErrTokenInvalidRequest: {GrantType: "refresh_token", ClientId: "some-client"},
+		ErrTokenInvalidClient:  {GrantType: "refresh_token", RefreshToken: "some-refresh-token"},
+	} {
+		// Arrange
+		refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+		sut := NewTokenHandler(&lib.OidcProviderMock{}, &lib.ClientStoreMock{}, &lib.AuthorizationCodeStoreMock{}, &refresh_token_store_mock, lib.TokenLifetimes{})
+
+		// Act
+		res, err := sut.Handle(context.Background(), req)
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, expected))
+		assert.False(t, refresh_token_store_mock.RotateCalled)
+	}
+}
@@ -0,0 +1,95 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"strings"
+)
+
+var (
+	ErrUserCreateValidationError   = errors.New("username or password is empty or claims are reserved")
+	ErrUserCreateDuplicateUsername = errors.New("username is already taken")
+	ErrUserCreateError             = errors.New("error while creating user")
+)
+
+type UserCreateRequest struct {
+	Username string                 `json:"username"`
+	Password string                 `json:"password"`
+	Scopes   []string               `json:"scopes"`
+	Roles    []string               `json:"roles"`
+	Claims   map[string]interface{} `json:"claims"`
+}
+
+// UserResponse is a user without its password hash.
+type UserResponse struct {
+	Id       string                 `json:"id"`
+	Username string                 `json:"username"`
+	Scopes   []string               `json:"scopes"`
+	Roles    []string               `json:"roles"`
+	Claims   map[string]interface{} `json:"claims"`
+	Disabled bool                   `json:"disabled"`
+}
+
+// UserCreateHandler creates users with a new id, it is meant for admins.
+type UserCreateHandler struct {
+	userStore lib.UserStore
+}
+
+func NewUserCreateHandler(userStore lib.UserStore) AppHandler[UserCreateRequest, UserResponse] {
+	return &UserCreateHandler{
+		userStore: userStore,
+	}
+}
+
+func (h *UserCreateHandler) Handle(ctx context.Context, request UserCreateRequest) (*UserResponse, error) {
+	request.Username = strings.TrimSpace(request.Username)
+	if request.Username == "" || request.Password == "" {
+		return nil, ErrUserCreateValidationError
+	}
+
+	passwordHash, err := lib.HashPassword(request.Password)
+	if err != nil {
+		log.Printf("error while hashing password of %s: %s", request.Username, err)
+		return nil, ErrUserCreateError
+	}
+
+	user, err := h.userStore.Create(lib.User{
+		Username:     request.Username,
+		PasswordHash: passwordHash,
+		Scopes:       request.Scopes,
+		Roles:        request.Roles,
+		Claims:       request.Claims,
+	})
+	if err != nil {
+		if errors.Is(err, lib.ErrUserStoreDuplicateUsername) {
+			return nil, ErrUserCreateDuplicateUsername
+		}
+
+		if errors.Is(err, lib.ErrUserStoreValidationError) {
+			return nil, ErrUserCreateValidationError
+		}
+
+		log.Printf("error while creating user %s: %s", request.Username, err)
+		return nil, ErrUserCreateError
+	}
+
+	if principal, ok := lib.PrincipalFromContext(ctx); ok {
+		log.Printf("%s created user %s", principal.Subject, user.Id)
+	}
+
+	response := newUserResponse(*user)
+	return &response, nil
+}
+
+func newUserResponse(user lib.User) UserResponse {
+	return UserResponse{
+		Id:       user.Id,
+		Username: user.Username,
+		Scopes:   user.Scopes,
+		Roles:    user.Roles,
+		Claims:   user.Claims,
+		Disabled: user.Disabled,
+	}
+}
@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type UserCreateHandlerMock struct {
+	HandleCalled bool
+	LastRequest  UserCreateRequest
+	NextResponse *UserResponse
+	NextError    error
+}
+
+func (m *UserCreateHandlerMock) Handle(ctx context.Context, request UserCreateRequest) (*UserResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
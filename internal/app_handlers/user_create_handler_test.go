@@ -0,0 +1,89 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_UserCreateHandler_Handle_returns_error_on_empty_username_or_password(t *testing.T) {
+	for _, req := range []UserCreateRequest{
+		{Username: " ", Password: "some-password"},
+		{Username: "some-user", Password: ""},
+	} {
+		// Arrange
+		user_store_mock := lib.UserStoreMock{}
+		sut := NewUserCreateHandler(&user_store_mock)
+
+		// Act
+		res, err := sut.Handle(context.Background(), req)
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrUserCreateValidationError))
+		assert.False(t, user_store_mock.CreateCalled)
+	}
+}
+
+func Test_UserCreateHandler_Handle_creates_user_with_password_hash(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{
+		NextCreateResult: &lib.User{
+			Id:           "some-id",
+			Username:     "some-user",
+			PasswordHash: "some-hash",
+			Scopes:       []string{"sum:compute"},
+			Roles:        []string{"some-role"},
+			Claims:       map[string]interface{}{"name": "Some User"},
+		},
+	}
+	sut := NewUserCreateHandler(&user_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserCreateRequest{
+		Username: " some-user ",
+		Password: "some-password",
+		Scopes:   []string{"sum:compute"},
+		Roles:    []string{"some-role"},
+		Claims:   map[string]interface{}{"name": "Some User"},
+	})
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, &UserResponse{
+		Id:       "some-id",
+		Username: "some-user",
+		Scopes:   []string{"sum:compute"},
+		Roles:    []string{"some-role"},
+		Claims:   map[string]interface{}{"name": "Some User"},
+	}, res)
+	assert.Equal(t, "some-user", user_store_mock.LastUser.Username)
+	assert.Equal(t, []string{"some-role"}, user_store_mock.LastUser.Roles)
+	assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(user_store_mock.LastUser.PasswordHash), []byte("some-password")))
+}
+
+func Test_UserCreateHandler_Handle_maps_store_errors(t *testing.T) {
+	for store_err, expected := range map[error]error{
+		lib.ErrUserStoreDuplicateUsername: ErrUserCreateDuplicateUsername,
+		lib.ErrUserStoreValidationError:   ErrUserCreateValidationError,
+		errors.New("some-error"):          ErrUserCreateError,
+	} {
+		// Arrange
+		user_store_mock := lib.UserStoreMock{
+			NextCreateError: store_err,
+		}
+		sut := NewUserCreateHandler(&user_store_mock)
+
+		// Act
+		res, err := sut.Handle(context.Background(), UserCreateRequest{Username: "some-user", Password: "some-password"})
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, expected))
+	}
+}
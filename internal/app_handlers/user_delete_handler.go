@@ -0,0 +1,61 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"strings"
+)
+
+var (
+	ErrUserDeleteValidationError = errors.New("user id is empty")
+	ErrUserDeleteUnknownUser     = errors.New("unknown user")
+	ErrUserDeleteError           = errors.New("error while deleting user")
+)
+
+type UserDeleteRequest struct {
+	Id string
+}
+
+type UserDeleteResponse struct{}
+
+// UserDeleteHandler deletes users and revokes their refresh tokens, it is meant for admins.
+type UserDeleteHandler struct {
+	userStore         lib.UserStore
+	refreshTokenStore lib.RefreshTokenStore
+}
+
+func NewUserDeleteHandler(userStore lib.UserStore, refreshTokenStore lib.RefreshTokenStore) AppHandler[UserDeleteRequest, UserDeleteResponse] {
+	return &UserDeleteHandler{
+		userStore:         userStore,
+		refreshTokenStore: refreshTokenStore,
+	}
+}
+
+func (h *UserDeleteHandler) Handle(ctx context.Context, request UserDeleteRequest) (*UserDeleteResponse, error) {
+	request.Id = strings.TrimSpace(request.Id)
+	if request.Id == "" {
+		return nil, ErrUserDeleteValidationError
+	}
+
+	if err := h.userStore.Delete(request.Id); err != nil {
+		if errors.Is(err, lib.ErrUserStoreUnknownUser) {
+			return nil, ErrUserDeleteUnknownUser
+		}
+
+		log.Printf("error while deleting user %s: %s", request.Id, err)
+		return nil, ErrUserDeleteError
+	}
+
+	if err := h.refreshTokenStore.RevokeSubject(request.Id); err != nil {
+		log.Printf("error while revoking refresh tokens of %s: %s", request.Id, err)
+		return nil, ErrUserDeleteError
+	}
+
+	if principal, ok := lib.PrincipalFromContext(ctx); ok {
+		log.Printf("%s deleted user %s", principal.Subject, request.Id)
+	}
+
+	return &UserDeleteResponse{}, nil
+}
@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type UserDeleteHandlerMock struct {
+	HandleCalled bool
+	LastRequest  UserDeleteRequest
+	NextResponse *UserDeleteResponse
+	NextError    error
+}
+
+func (m *UserDeleteHandlerMock) Handle(ctx context.Context, request UserDeleteRequest) (*UserDeleteResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
@@ -0,0 +1,60 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserDeleteHandler_Handle_returns_error_on_empty_id(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{}
+	sut := NewUserDeleteHandler(&user_store_mock, &lib.RefreshTokenStoreMock{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserDeleteRequest{})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrUserDeleteValidationError))
+	assert.False(t, user_store_mock.DeleteCalled)
+}
+
+func Test_UserDeleteHandler_Handle_deletes_user_and_revokes_refresh_tokens(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewUserDeleteHandler(&user_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserDeleteRequest{Id: "some-id"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, "some-id", user_store_mock.LastId)
+	assert.Equal(t, "some-id", refresh_token_store_mock.LastSubject)
+}
+
+func Test_UserDeleteHandler_Handle_maps_store_errors(t *testing.T) {
+	for store_err, expected := range map[error]error{
+		lib.ErrUserStoreUnknownUser: ErrUserDeleteUnknownUser,
+		errors.New("some-error"):    ErrUserDeleteError,
+	} {
+		// Arrange
+		user_store_mock := lib.UserStoreMock{
+			NextDeleteError: store_err,
+		}
+		sut := NewUserDeleteHandler(&user_store_mock, &lib.RefreshTokenStoreMock{})
+
+		// Act
+		res, err := sut.Handle(context.Background(), UserDeleteRequest{Id: "some-id"})
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, expected))
+	}
+}
@@ -0,0 +1,66 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"strings"
+)
+
+var (
+	ErrUserDisableValidationError = errors.New("user id is empty")
+	ErrUserDisableUnknownUser     = errors.New("unknown user")
+	ErrUserDisableError           = errors.New("error while disabling user")
+)
+
+type UserDisableRequest struct {
+	Id       string
+	Disabled bool
+}
+
+type UserDisableResponse struct{}
+
+// UserDisableHandler disables or enables users, it is meant for admins. The refresh
+// tokens of disabled users are revoked, so they are logged out once their access
+// tokens expire.
+type UserDisableHandler struct {
+	userStore         lib.UserStore
+	refreshTokenStore lib.RefreshTokenStore
+}
+
+func NewUserDisableHandler(userStore lib.UserStore, refreshTokenStore lib.RefreshTokenStore) AppHandler[UserDisableRequest, UserDisableResponse] {
+	return &UserDisableHandler{
+		userStore:         userStore,
+		refreshTokenStore: refreshTokenStore,
+	}
+}
+
+func (h *UserDisableHandler) Handle(ctx context.Context, request UserDisableRequest) (*UserDisableResponse, error) {
+	request.Id = strings.TrimSpace(request.Id)
+	if request.Id == "" {
+		return nil, ErrUserDisableValidationError
+	}
+
+	if err := h.userStore.SetDisabled(request.Id, request.Disabled); err != nil {
+		if errors.Is(err, lib.ErrUserStoreUnknownUser) {
+			return nil, ErrUserDisableUnknownUser
+		}
+
+		log.Printf("error while disabling user %s: %s", request.Id, err)
+		return nil, ErrUserDisableError
+	}
+
+	if request.Disabled {
+		if err := h.refreshTokenStore.RevokeSubject(request.Id); err != nil {
+			log.Printf("error while revoking refresh tokens of %s: %s", request.Id, err)
+			return nil, ErrUserDisableError
+		}
+	}
+
+	if principal, ok := lib.PrincipalFromContext(ctx); ok {
+		log.Printf("%s set disabled of user %s to %t", principal.Subject, request.Id, request.Disabled)
+	}
+
+	return &UserDisableResponse{}, nil
+}
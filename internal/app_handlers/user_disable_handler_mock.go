@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type UserDisableHandlerMock struct {
+	HandleCalled bool
+	LastRequest  UserDisableRequest
+	NextResponse *UserDisableResponse
+	NextError    error
+}
+
+func (m *UserDisableHandlerMock) Handle(ctx context.Context, request UserDisableRequest) (*UserDisableResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
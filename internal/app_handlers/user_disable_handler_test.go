@@ -0,0 +1,80 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserDisableHandler_Handle_returns_error_on_empty_id(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{}
+	sut := NewUserDisableHandler(&user_store_mock, &lib.RefreshTokenStoreMock{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserDisableRequest{Disabled: true})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrUserDisableValidationError))
+	assert.False(t, user_store_mock.SetDisabledCalled)
+}
+
+func Test_UserDisableHandler_Handle_disables_user_and_revokes_refresh_tokens(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewUserDisableHandler(&user_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserDisableRequest{Id: "some-id", Disabled: true})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, "some-id", user_store_mock.LastId)
+	assert.True(t, user_store_mock.LastDisabled)
+	assert.Equal(t, "some-id", refresh_token_store_mock.LastSubject)
+}
+
+func Test_UserDisableHandler_Handle_enables_user_without_revoking(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewUserDisableHandler(&user_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserDisableRequest{Id: "some-id", Disabled: false})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.True(t, user_store_mock.SetDisabledCalled)
+	assert.False(t, user_store_mock.LastDisabled)
+	assert.False(t, refresh_token_store_mock.RevokeSubjectCalled)
+}
+
+func Test_UserDisableHandler_Handle_maps_store_errors(t *testing.T) {
+	for store_err, expected := range map[error]error{
+		lib.ErrUserStoreUnknownUser: ErrUserDisableUnknownUser,
+		errors.New("some-error"):    ErrUserDisableError,
+	} {
+		// Arrange
+		user_store_mock := lib.UserStoreMock{
+			NextSetDisabledError: store_err,
+		}
+		refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+		sut := NewUserDisableHandler(&user_store_mock, &refresh_token_store_mock)
+
+		// Act
+		res, err := sut.Handle(context.Background(), UserDisableRequest{Id: "some-id", Disabled: true})
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, expected))
+		assert.False(t, refresh_token_store_mock.RevokeSubjectCalled)
+	}
+}
@@ -0,0 +1,47 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+)
+
+var (
+	ErrUserListError = errors.New("error while listing users")
+)
+
+type UserListRequest struct{}
+
+type UserListResponse struct {
+	Users []UserResponse `json:"users"`
+}
+
+// UserListHandler lists the users without their password hashes, it is meant for admins.
+type UserListHandler struct {
+	userStore lib.UserStore
+}
+
+func NewUserListHandler(userStore lib.UserStore) AppHandler[UserListRequest, UserListResponse] {
+	return &UserListHandler{
+		userStore: userStore,
+	}
+}
+
+func (h *UserListHandler) Handle(ctx context.Context, request UserListRequest) (*UserListResponse, error) {
+	users, err := h.userStore.List()
+	if err != nil {
+		log.Printf("error while listing users: %s", err)
+		return nil, ErrUserListError
+	}
+
+	response := &UserListResponse{
+		Users: make([]UserResponse, 0, len(users)),
+	}
+
+	for _, user := range users {
+		response.Users = append(response.Users, newUserResponse(user))
+	}
+
+	return response, nil
+}
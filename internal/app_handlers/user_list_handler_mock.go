@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type UserListHandlerMock struct {
+	HandleCalled bool
+	LastRequest  UserListRequest
+	NextResponse *UserListResponse
+	NextError    error
+}
+
+func (m *UserListHandlerMock) Handle(ctx context.Context, request UserListRequest) (*UserListResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
@@ -0,0 +1,61 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_UserListHandler_Handle_returns_users_without_password_hash(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{
+		NextListResult: []lib.User{
+			{Id: "some-id", Username: "some-user", PasswordHash: "some-hash"},
+			{Id: "other-id", Username: "other-user", PasswordHash: "other-hash", Disabled: true},
+		},
+	}
+	sut := NewUserListHandler(&user_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserListRequest{})
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, []UserResponse{
+		{Id: "some-id", Username: "some-user"},
+		{Id: "other-id", Username: "other-user", Disabled: true},
+	}, res.Users)
+}
+
+func Test_UserListHandler_Handle_returns_empty_list(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{}
+	sut := NewUserListHandler(&user_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserListRequest{})
+
+	// Assert
+	require.Nil(t, err)
+	assert.NotNil(t, res.Users)
+	assert.Empty(t, res.Users)
+}
+
+func Test_UserListHandler_Handle_returns_error_on_store_error(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{
+		NextListError: errors.New("some-error"),
+	}
+	sut := NewUserListHandler(&user_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserListRequest{})
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrUserListError))
+}
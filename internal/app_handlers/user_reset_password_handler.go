@@ -0,0 +1,70 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"strings"
+)
+
+var (
+	ErrUserResetPasswordValidationError = errors.New("user id or password is empty")
+	ErrUserResetPasswordUnknownUser     = errors.New("unknown user")
+	ErrUserResetPasswordError           = errors.New("error while resetting password")
+)
+
+type UserResetPasswordRequest struct {
+	Id       string `json:"-"`
+	Password string `json:"password"`
+}
+
+type UserResetPasswordResponse struct{}
+
+// UserResetPasswordHandler sets a new password for a user, it is meant for admins.
+// The refresh tokens of the user are revoked, as the old password may be known
+// to someone else.
+type UserResetPasswordHandler struct {
+	userStore         lib.UserStore
+	refreshTokenStore lib.RefreshTokenStore
+}
+
+func NewUserResetPasswordHandler(userStore lib.UserStore, refreshTokenStore lib.RefreshTokenStore) AppHandler[UserResetPasswordRequest, UserResetPasswordResponse] {
+	return &UserResetPasswordHandler{
+		userStore:         userStore,
+		refreshTokenStore: refreshTokenStore,
+	}
+}
+
+func (h *UserResetPasswordHandler) Handle(ctx context.Context, request UserResetPasswordRequest) (*UserResetPasswordResponse, error) {
+	request.Id = strings.TrimSpace(request.Id)
+	if request.Id == "" || request.Password == "" {
+		return nil, ErrUserResetPasswordValidationError
+	}
+
+	passwordHash, err := lib.HashPassword(request.Password)
+	if err != nil {
+		log.Printf("error while hashing password of %s: %s", request.Id, err)
+		return nil, ErrUserResetPasswordError
+	}
+
+	if err := h.userStore.SetPasswordHash(request.Id, passwordHash); err != nil {
+		if errors.Is(err, lib.ErrUserStoreUnknownUser) {
+			return nil, ErrUserResetPasswordUnknownUser
+		}
+
+		log.Printf("error while resetting password of %s: %s", request.Id, err)
+		return nil, ErrUserResetPasswordError
+	}
+
+	if err := h.refreshTokenStore.RevokeSubject(request.Id); err != nil {
+		log.Printf("error while revoking refresh tokens of %s: %s", request.Id, err)
+		return nil, ErrUserResetPasswordError
+	}
+
+	if principal, ok := lib.PrincipalFromContext(ctx); ok {
+		log.Printf("%s reset password of user %s", principal.Subject, request.Id)
+	}
+
+	return &UserResetPasswordResponse{}, nil
+}
@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type UserResetPasswordHandlerMock struct {
+	HandleCalled bool
+	LastRequest  UserResetPasswordRequest
+	NextResponse *UserResetPasswordResponse
+	NextError    error
+}
+
+func (m *UserResetPasswordHandlerMock) Handle(ctx context.Context, request UserResetPasswordRequest) (*UserResetPasswordResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
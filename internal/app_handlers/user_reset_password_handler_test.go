@@ -0,0 +1,69 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_UserResetPasswordHandler_Handle_returns_error_on_empty_id_or_password(t *testing.T) {
+	for _, req := range []UserResetPasswordRequest{
+		{Id: "", Password: "some-password"},
+		{Id: "some-id", Password: ""},
+	} {
+		// Arrange
+		user_store_mock := lib.UserStoreMock{}
+		sut := NewUserResetPasswordHandler(&user_store_mock, &lib.RefreshTokenStoreMock{})
+
+		// Act
+		res, err := sut.Handle(context.Background(), req)
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrUserResetPasswordValidationError))
+		assert.False(t, user_store_mock.SetPasswordHashCalled)
+	}
+}
+
+func Test_UserResetPasswordHandler_Handle_sets_password_hash_and_revokes_refresh_tokens(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewUserResetPasswordHandler(&user_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserResetPasswordRequest{Id: "some-id", Password: "new-password"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, "some-id", user_store_mock.LastId)
+	assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(user_store_mock.LastPasswordHash), []byte("new-password")))
+	assert.Equal(t, "some-id", refresh_token_store_mock.LastSubject)
+}
+
+func Test_UserResetPasswordHandler_Handle_maps_store_errors(t *testing.T) {
+	for store_err, expected := range map[error]error{
+		lib.ErrUserStoreUnknownUser: ErrUserResetPasswordUnknownUser,
+		errors.New("some-error"):    ErrUserResetPasswordError,
+	} {
+		// Arrange
+		user_store_mock := lib.UserStoreMock{
+			NextSetPasswordHashError: store_err,
+		}
+		refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+		sut := NewUserResetPasswordHandler(&user_store_mock, &refresh_token_store_mock)
+
+		// Act
+		res, err := sut.Handle(context.Background(), UserResetPasswordRequest{Id: "some-id", Password: "new-password"})
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, expected))
+		assert.False(t, refresh_token_store_mock.RevokeSubjectCalled)
+	}
+}
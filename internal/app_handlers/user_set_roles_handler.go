@@ -0,0 +1,73 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"log"
+	"strings"
+)
+
+var (
+	ErrUserSetRolesValidationError = errors.New("user id or a role is empty")
+	ErrUserSetRolesUnknownUser     = errors.New("unknown user")
+	ErrUserSetRolesError           = errors.New("error while setting roles")
+)
+
+type UserSetRolesRequest struct {
+	Id    string   `json:"-"`
+	Roles []string `json:"roles"`
+}
+
+type UserSetRolesResponse struct{}
+
+// UserSetRolesHandler replaces the roles of a user, it is meant for admins. The
+// refresh tokens of the user are revoked, as they carry the previous roles.
+type UserSetRolesHandler struct {
+	userStore         lib.UserStore
+	refreshTokenStore lib.RefreshTokenStore
+}
+
+func NewUserSetRolesHandler(userStore lib.UserStore, refreshTokenStore lib.RefreshTokenStore) AppHandler[UserSetRolesRequest, UserSetRolesResponse] {
+	return &UserSetRolesHandler{
+		userStore:         userStore,
+		refreshTokenStore: refreshTokenStore,
+	}
+}
+
+func (h *UserSetRolesHandler) Handle(ctx context.Context, request UserSetRolesRequest) (*UserSetRolesResponse, error) {
+	request.Id = strings.TrimSpace(request.Id)
+	if request.Id == "" {
+		return nil, ErrUserSetRolesValidationError
+	}
+
+	roles := []string{}
+	for _, role := range request.Roles {
+		role = strings.TrimSpace(role)
+		if role == "" {
+			return nil, ErrUserSetRolesValidationError
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err := h.userStore.SetRoles(request.Id, roles); err != nil {
+		if errors.Is(err, lib.ErrUserStoreUnknownUser) {
+			return nil, ErrUserSetRolesUnknownUser
+		}
+
+		log.Printf("error while setting roles of %s: %s", request.Id, err)
+		return nil, ErrUserSetRolesError
+	}
+
+	if err := h.refreshTokenStore.RevokeSubject(request.Id); err != nil {
+		log.Printf("error while revoking refresh tokens of %s: %s", request.Id, err)
+		return nil, ErrUserSetRolesError
+	}
+
+	if principal, ok := lib.PrincipalFromContext(ctx); ok {
+		log.Printf("%s set roles of user %s to %v", principal.Subject, request.Id, roles)
+	}
+
+	return &UserSetRolesResponse{}, nil
+}
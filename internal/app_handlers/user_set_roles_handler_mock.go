@@ -0,0 +1,18 @@
+package app_handlers
+
+import (
+	"context"
+)
+
+type UserSetRolesHandlerMock struct {
+	HandleCalled bool
+	LastRequest  UserSetRolesRequest
+	NextResponse *UserSetRolesResponse
+	NextError    error
+}
+
+func (m *UserSetRolesHandlerMock) Handle(ctx context.Context, request UserSetRolesRequest) (*UserSetRolesResponse, error) {
+	m.HandleCalled = true
+	m.LastRequest = request
+	return m.NextResponse, m.NextError
+}
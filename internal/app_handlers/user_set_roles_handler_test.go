@@ -0,0 +1,82 @@
+package app_handlers
+
+import (
+	"coding_exercise/internal/lib"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserSetRolesHandler_Handle_returns_error_on_empty_id_or_role(t *testing.T) {
+	for _, req := range []UserSetRolesRequest{
+		{Id: "", Roles: []string{"some-role"}},
+		{Id: "some-id", Roles: []string{"some-role", " "}},
+	} {
+		// Arrange
+		user_store_mock := lib.UserStoreMock{}
+		sut := NewUserSetRolesHandler(&user_store_mock, &lib.RefreshTokenStoreMock{})
+
+		// Act
+		res, err := sut.Handle(context.Background(), req)
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, ErrUserSetRolesValidationError))
+		assert.False(t, user_store_mock.SetRolesCalled)
+	}
+}
+
+func Test_UserSetRolesHandler_Handle_sets_roles_and_revokes_refresh_tokens(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{}
+	refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+	sut := NewUserSetRolesHandler(&user_store_mock, &refresh_token_store_mock)
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserSetRolesRequest{Id: "some-id", Roles: []string{" some-role", "other-role"}})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, "some-id", user_store_mock.LastId)
+	assert.Equal(t, []string{"some-role", "other-role"}, user_store_mock.LastRoles)
+	assert.Equal(t, "some-id", refresh_token_store_mock.LastSubject)
+}
+
+func Test_UserSetRolesHandler_Handle_clears_roles(t *testing.T) {
+	// Arrange
+	user_store_mock := lib.UserStoreMock{}
+	sut := NewUserSetRolesHandler(&user_store_mock, &lib.RefreshTokenStoreMock{})
+
+	// Act
+	res, err := sut.Handle(context.Background(), UserSetRolesRequest{Id: "some-id"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, res)
+	assert.Equal(t, []string{}, user_store_mock.LastRoles)
+}
+
+func Test_UserSetRolesHandler_Handle_maps_store_errors(t *testing.T) {
+	for store_err, expected := range map[error]error{
+		lib.ErrUserStoreUnknownUser: ErrUserSetRolesUnknownUser,
+		errors.New("some-error"):    ErrUserSetRolesError,
+	} {
+		// Arrange
+		user_store_mock := lib.UserStoreMock{
+			NextSetRolesError: store_err,
+		}
+		refresh_token_store_mock := lib.RefreshTokenStoreMock{}
+		sut := NewUserSetRolesHandler(&user_store_mock, &refresh_token_store_mock)
+
+		// Act
+		res, err := sut.Handle(context.Background(), UserSetRolesRequest{Id: "some-id", Roles: []string{"some-role"}})
+
+		// Assert
+		assert.Nil(t, res)
+		assert.True(t, errors.Is(err, expected))
+		assert.False(t, refresh_token_store_mock.RevokeSubjectCalled)
+	}
+}
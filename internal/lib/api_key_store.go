@@ -0,0 +1,51 @@
+package lib
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrApiKeyStoreInvalidKey      = errors.New("invalid api key")
+	ErrApiKeyStoreUnknownKey      = errors.New("unknown api key")
+	ErrApiKeyStoreValidationError = errors.New("api key needs an owner and a lifetime")
+)
+
+// DefaultApiKeyLifetime is used for keys which are issued without a lifetime.
+const DefaultApiKeyLifetime = 90 * 24 * time.Hour
+
+// ApiKey describes what an api key was issued for, the key itself is only known to
+// the caller it was issued to.
+type ApiKey struct {
+	Id        string    `json:"id"`
+	Owner     string    `json:"owner"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Principal is the caller of a request authenticated with the key, the owner is
+// the subject.
+func (k *ApiKey) Principal() *Principal {
+	return &Principal{
+		Subject: k.Owner,
+		Scopes:  k.Scopes,
+		Amr:     []string{},
+		Client:  true,
+		Claims: map[string]interface{}{
+			"sub":        k.Owner,
+			"scope":      strings.Join(k.Scopes, " "),
+			"exp":        k.ExpiresAt.Unix(),
+			"api_key_id": k.Id,
+		},
+	}
+}
+
+// ApiKeyStore issues api keys for integrations which can only send a static
+// header. Keys are revoked by their id, so the key itself doesn't have to be known.
+type ApiKeyStore interface {
+	Issue(owner string, scopes []string, lifetime time.Duration) (*ApiKey, string, error)
+	Verify(key string) (*ApiKey, error)
+	Revoke(id string) error
+}
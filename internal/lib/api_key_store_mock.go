@@ -0,0 +1,46 @@
+package lib
+
+import (
+	"time"
+)
+
+type ApiKeyStoreMock struct {
+	IssueCalled  bool
+	VerifyCalled bool
+	RevokeCalled bool
+
+	LastOwner    string
+	LastScopes   []string
+	LastLifetime time.Duration
+	LastKey      string
+	LastId       string
+
+	NextIssueResult *ApiKey
+	NextIssueKey    string
+	NextIssueError  error
+
+	NextVerifyResult *ApiKey
+	NextVerifyError  error
+
+	NextRevokeError error
+}
+
+func (m *ApiKeyStoreMock) Issue(owner string, scopes []string, lifetime time.Duration) (*ApiKey, string, error) {
+	m.IssueCalled = true
+	m.LastOwner = owner
+	m.LastScopes = scopes
+	m.LastLifetime = lifetime
+	return m.NextIssueResult, m.NextIssueKey, m.NextIssueError
+}
+
+func (m *ApiKeyStoreMock) Verify(key string) (*ApiKey, error) {
+	m.VerifyCalled = true
+	m.LastKey = key
+	return m.NextVerifyResult, m.NextVerifyError
+}
+
+func (m *ApiKeyStoreMock) Revoke(id string) error {
+	m.RevokeCalled = true
+	m.LastId = id
+	return m.NextRevokeError
+}
@@ -0,0 +1,24 @@
+package lib
+
+// NewAsymmetricOidcProvider creates a provider which signs tokens with an RSA, ECDSA
+// or Ed25519 private key, the public key is derived from the PEM encoded private key.
+func NewAsymmetricOidcProvider(algorithm string, privateKeyPem []byte, issuer string) (OidcProvider, error) {
+	key, err := ParsePrivateKeyPem(algorithm, privateKeyPem)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewJwtOidcProvider(NewKeyRing(key, DefaultKeyRetention), issuer), nil
+}
+
+// NewAsymmetricOidcVerifier creates a provider from a PEM encoded public key, so
+// services verifying the tokens don't have to hold the signing key. It can validate
+// tokens but GenerateToken will always fail.
+func NewAsymmetricOidcVerifier(algorithm string, publicKeyPem []byte, issuer string) (OidcProvider, error) {
+	key, err := ParsePublicKeyPem(algorithm, publicKeyPem)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewJwtOidcProvider(NewKeyRing(key, DefaultKeyRetention), issuer), nil
+}
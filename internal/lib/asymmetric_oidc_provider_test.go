@@ -0,0 +1,334 @@
+package lib
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateTestKey(t *testing.T, algorithm string) crypto.Signer {
+	var key crypto.Signer
+	var err error
+
+	switch algorithm {
+	case "RS256":
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ES384":
+		key, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "EdDSA":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		t.Fatalf("no test key for algorithm %s", algorithm)
+	}
+
+	require.Nil(t, err)
+	return key
+}
+
+func privateKeyToPem(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicKeyToPem(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func Test_AsymmetricOidcProvider_GenerateToken_generates_a_valid_token_for_each_algorithm(t *testing.T) {
+	for _, algorithm := range []string{"RS256", "ES256", "EdDSA"} {
+		// Arrange
+		key := generateTestKey(t, algorithm)
+		sut, err := NewAsymmetricOidcProvider(algorithm, privateKeyToPem(t, key), "some-issuer")
+		require.Nil(t, err, algorithm)
+
+		// Act
+		token, err := sut.GenerateToken("some-user", TokenOptions{})
+		require.Nil(t, err, algorithm)
+
+		claims, err := sut.ValidateToken(token)
+
+		// Assert
+		assert.Nil(t, err, algorithm)
+		require.NotEmpty(t, claims, algorithm)
+		assert.Equal(t, "some-user", claims["sub"], algorithm)
+		assert.Equal(t, "some-issuer", claims["iss"], algorithm)
+	}
+}
+
+func Test_AsymmetricOidcProvider_GenerateToken_returns_error_on_empty_username(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "ES256")
+	sut, err := NewAsymmetricOidcProvider("ES256", privateKeyToPem(t, key), "some-issuer")
+	require.Nil(t, err)
+
+	// Act
+	_, err = sut.GenerateToken("", TokenOptions{})
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrJwtOidcProviderValidationError))
+}
+
+func Test_AsymmetricOidcProvider_GenerateToken_generates_a_token_with_1h_expiration(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "ES256")
+	sut, err := NewAsymmetricOidcProvider("ES256", privateKeyToPem(t, key), "some-issuer")
+	require.Nil(t, err)
+
+	now, _ := time.Parse(time.RFC3339, "2000-01-02T03:04:05.00Z")
+	now_1h, _ := time.Parse(time.RFC3339, "2000-01-02T04:04:05.00Z")
+	sut.(*JwtOidcProvider).now = func() time.Time {
+		return now
+	}
+
+	// Act
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, float64(now.Unix()), claims["nbf"])
+	assert.Equal(t, float64(now.Unix()), claims["iat"])
+	assert.Equal(t, float64(now_1h.Unix()), claims["exp"])
+}
+
+func Test_AsymmetricOidcVerifier_ValidateToken_accepts_token_signed_by_matching_private_key(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "RS256")
+	signer, err := NewAsymmetricOidcProvider("RS256", privateKeyToPem(t, key), "some-issuer")
+	require.Nil(t, err)
+
+	sut, err := NewAsymmetricOidcVerifier("RS256", publicKeyToPem(t, key), "some-issuer")
+	require.Nil(t, err)
+
+	token, err := signer.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "some-user", claims["sub"])
+}
+
+func Test_AsymmetricOidcVerifier_GenerateToken_returns_error_without_private_key(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "EdDSA")
+	sut, err := NewAsymmetricOidcVerifier("EdDSA", publicKeyToPem(t, key), "some-issuer")
+	require.Nil(t, err)
+
+	// Act
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+
+	// Assert
+	assert.Empty(t, token)
+	assert.True(t, errors.Is(err, ErrJwtOidcProviderMissingSigningKey))
+}
+
+func Test_AsymmetricOidcProvider_ValidateToken_returns_error_on_token_signed_by_other_key(t *testing.T) {
+	// Arrange
+	sut, err := NewAsymmetricOidcProvider("ES256", privateKeyToPem(t, generateTestKey(t, "ES256")), "some-issuer")
+	require.Nil(t, err)
+
+	other, err := NewAsymmetricOidcProvider("ES256", privateKeyToPem(t, generateTestKey(t, "ES256")), "some-issuer")
+	require.Nil(t, err)
+
+	token, err := other.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Nil(t, claims)
+}
+
+func Test_AsymmetricOidcProvider_ValidateToken_returns_error_on_hmac_signed_token(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "RS256")
+	sut, err := NewAsymmetricOidcVerifier("RS256", publicKeyToPem(t, key), "some-issuer")
+	require.Nil(t, err)
+
+	// an HS512 token signed with the public key as secret must not be accepted
+	claims := &jwt.StandardClaims{Issuer: "some-issuer", Subject: "some-user"}
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
+	hmac.Header["kid"] = sut.Jwks().Keys[0].Kid
+	token, err := hmac.SignedString(publicKeyToPem(t, key))
+	require.Nil(t, err)
+
+	// Act
+	res, err := sut.ValidateToken(token)
+
+	// Assert
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid signing method")
+	assert.Nil(t, res)
+}
+
+func Test_AsymmetricOidcProvider_ValidateToken_returns_error_on_invalid_issuer(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "ES256")
+	signer, err := NewAsymmetricOidcProvider("ES256", privateKeyToPem(t, key), "some-issuer")
+	require.Nil(t, err)
+
+	sut, err := NewAsymmetricOidcVerifier("ES256", publicKeyToPem(t, key), "another-issuer")
+	require.Nil(t, err)
+
+	token, err := signer.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown issuer")
+	assert.Nil(t, claims)
+}
+
+func Test_AsymmetricOidcProvider_ValidateToken_returns_error_on_expired_token(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "ES256")
+	sut, err := NewAsymmetricOidcProvider("ES256", privateKeyToPem(t, key), "some-issuer")
+	require.Nil(t, err)
+
+	now, _ := time.Parse(time.RFC3339, "2000-01-02T03:04:05.00Z")
+	sut.(*JwtOidcProvider).now = func() time.Time {
+		return now
+	}
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// validate 10h later
+	sut.(*JwtOidcProvider).now = func() time.Time {
+		return now.Add(10 * time.Hour)
+	}
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Nil(t, claims)
+}
+
+func Test_NewAsymmetricOidcProvider_returns_error_on_unsupported_algorithm(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "ES256")
+
+	// Act
+	sut, err := NewAsymmetricOidcProvider("HS512", privateKeyToPem(t, key), "some-issuer")
+
+	// Assert
+	assert.Nil(t, sut)
+	assert.True(t, errors.Is(err, ErrSigningKeyUnsupportedAlgorithm))
+}
+
+func Test_NewAsymmetricOidcProvider_returns_error_when_key_does_not_match_algorithm(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "EdDSA")
+
+	// Act
+	sut, err := NewAsymmetricOidcProvider("RS256", privateKeyToPem(t, key), "some-issuer")
+
+	// Assert
+	assert.Nil(t, sut)
+	assert.True(t, errors.Is(err, ErrSigningKeyInvalidKey))
+}
+
+func Test_NewAsymmetricOidcProvider_returns_error_when_curve_does_not_match_algorithm(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "ES384")
+
+	// Act
+	sut, err := NewAsymmetricOidcProvider("ES256", privateKeyToPem(t, key), "some-issuer")
+
+	// Assert
+	assert.Nil(t, sut)
+	assert.True(t, errors.Is(err, ErrSigningKeyInvalidCurve))
+}
+
+func Test_NewAsymmetricOidcProvider_returns_error_on_invalid_pem(t *testing.T) {
+	// Act
+	sut, err := NewAsymmetricOidcProvider("ES256", []byte("not a pem"), "some-issuer")
+
+	// Assert
+	assert.Nil(t, sut)
+	assert.True(t, errors.Is(err, ErrSigningKeyInvalidPem))
+}
+
+func Test_AsymmetricOidcProvider_GenerateToken_sets_kid_of_published_key(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "ES256")
+	sut, err := NewAsymmetricOidcProvider("ES256", privateKeyToPem(t, key), "some-issuer")
+	require.Nil(t, err)
+
+	// Act
+	tokenString, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+	require.Nil(t, err)
+
+	// Assert
+	jwks := sut.Jwks()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, jwks.Keys[0].Kid, token.Header["kid"])
+	assert.Equal(t, "ES256", jwks.Keys[0].Alg)
+}
+
+func Test_AsymmetricOidcVerifier_Jwks_publishes_the_public_key(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "EdDSA")
+	signer, err := NewAsymmetricOidcProvider("EdDSA", privateKeyToPem(t, key), "some-issuer")
+	require.Nil(t, err)
+
+	// Act
+	sut, err := NewAsymmetricOidcVerifier("EdDSA", publicKeyToPem(t, key), "some-issuer")
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, signer.Jwks(), sut.Jwks())
+}
+
+func Test_AsymmetricOidcProvider_ValidateToken_returns_error_on_unknown_kid(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "ES256")
+	sut, err := NewAsymmetricOidcProvider("ES256", privateKeyToPem(t, key), "some-issuer")
+	require.Nil(t, err)
+
+	claims := &jwt.StandardClaims{Issuer: "some-issuer", Subject: "some-user"}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = "some-other-kid"
+	tokenString, err := token.SignedString(key)
+	require.Nil(t, err)
+
+	// Act
+	res, err := sut.ValidateToken(tokenString)
+
+	// Assert
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown key id")
+	assert.Nil(t, res)
+}
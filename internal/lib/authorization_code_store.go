@@ -0,0 +1,30 @@
+package lib
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAuthorizationCodeStoreInvalidCode = errors.New("invalid authorization code")
+)
+
+// AuthorizationGrant is what the user consented to at the authorization endpoint,
+// it is handed to the client which exchanges the code for tokens.
+type AuthorizationGrant struct {
+	ClientId      string
+	RedirectUri   string
+	Subject       string
+	Scopes        []string
+	CodeChallenge string
+	Nonce         string
+	AuthTime      time.Time
+	Amr           []string
+	Roles         []string
+}
+
+// AuthorizationCodeStore issues short lived, single use authorization codes.
+type AuthorizationCodeStore interface {
+	Issue(grant AuthorizationGrant) (string, error)
+	Redeem(code string) (*AuthorizationGrant, error)
+}
@@ -0,0 +1,27 @@
+package lib
+
+type AuthorizationCodeStoreMock struct {
+	IssueCalled  bool
+	RedeemCalled bool
+
+	LastGrant AuthorizationGrant
+	LastCode  string
+
+	NextIssueResult string
+	NextIssueError  error
+
+	NextRedeemResult *AuthorizationGrant
+	NextRedeemError  error
+}
+
+func (m *AuthorizationCodeStoreMock) Issue(grant AuthorizationGrant) (string, error) {
+	m.IssueCalled = true
+	m.LastGrant = grant
+	return m.NextIssueResult, m.NextIssueError
+}
+
+func (m *AuthorizationCodeStoreMock) Redeem(code string) (*AuthorizationGrant, error) {
+	m.RedeemCalled = true
+	m.LastCode = code
+	return m.NextRedeemResult, m.NextRedeemError
+}
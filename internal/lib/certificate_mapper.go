@@ -0,0 +1,35 @@
+package lib
+
+import (
+	"crypto/x509"
+	"errors"
+)
+
+var (
+	ErrCertificateMapperUnknownIdentity = errors.New("certificate does not belong to a known identity")
+)
+
+// The types of certificate identities, the name of an identity is only matched
+// against the certificate names of its type.
+const (
+	CertificateIdentityTypeUri   = "uri"
+	CertificateIdentityTypeDns   = "dns"
+	CertificateIdentityTypeEmail = "email"
+	CertificateIdentityTypeCn    = "cn"
+)
+
+// CertificateIdentity is a caller authenticating with a client certificate, the
+// name is matched against the URI, DNS or email SANs or the common name of the
+// certificate, depending on the type. Otherwise a certificate with a DNS SAN of
+// some-service could claim the identity configured for the common name.
+type CertificateIdentity struct {
+	Type   string   `json:"type"`
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CertificateMapper maps a verified client certificate to the principal of the
+// caller, the certificate chain has to be verified before.
+type CertificateMapper interface {
+	Principal(certificate *x509.Certificate) (*Principal, error)
+}
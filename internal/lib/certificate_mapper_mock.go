@@ -0,0 +1,20 @@
+package lib
+
+import (
+	"crypto/x509"
+)
+
+type CertificateMapperMock struct {
+	PrincipalCalled bool
+
+	LastCertificate *x509.Certificate
+
+	NextPrincipalResult *Principal
+	NextPrincipalError  error
+}
+
+func (m *CertificateMapperMock) Principal(certificate *x509.Certificate) (*Principal, error) {
+	m.PrincipalCalled = true
+	m.LastCertificate = certificate
+	return m.NextPrincipalResult, m.NextPrincipalError
+}
@@ -0,0 +1,42 @@
+package lib
+
+// reservedClaims are set by the provider and can't be overridden by a ClaimsEnricher.
+var reservedClaims = map[string]bool{
+	"iss":       true,
+	"sub":       true,
+	"aud":       true,
+	"exp":       true,
+	"nbf":       true,
+	"iat":       true,
+	"jti":       true,
+	"scope":     true,
+	"nonce":     true,
+	"auth_time": true,
+	"azp":       true,
+	"amr":       true,
+	"acr":       true,
+	"roles":     true,
+	"client_id": true,
+	"gty":       true,
+
+	"preferred_username": true,
+}
+
+// enricherClaims are reserved for the ClaimsEnricher itself, the claims of a user
+// can't set them but the enricher derives them from the user.
+var enricherClaims = map[string]bool{
+	"preferred_username": true,
+}
+
+// ClaimsEnricher adds claims about the user to issued tokens, for example the
+// email, name or tenant of a user. It is only asked for tokens of users, subjects
+// it doesn't know about get no additional claims.
+type ClaimsEnricher interface {
+	Claims(subject string) (map[string]interface{}, error)
+}
+
+// IsReservedClaim is true for the registered claims and the claims the provider
+// sets itself.
+func IsReservedClaim(name string) bool {
+	return reservedClaims[name]
+}
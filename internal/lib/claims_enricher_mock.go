@@ -0,0 +1,15 @@
+package lib
+
+type ClaimsEnricherMock struct {
+	ClaimsCalled bool
+	LastSubject  string
+
+	NextClaimsResult map[string]interface{}
+	NextClaimsError  error
+}
+
+func (m *ClaimsEnricherMock) Claims(subject string) (map[string]interface{}, error) {
+	m.ClaimsCalled = true
+	m.LastSubject = subject
+	return m.NextClaimsResult, m.NextClaimsError
+}
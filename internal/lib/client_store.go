@@ -0,0 +1,68 @@
+package lib
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrClientStoreInvalidClient = errors.New("invalid client id or secret")
+)
+
+// Client is a service which authenticates itself with a client id and secret,
+// for example a gateway introspecting tokens. Public clients, like browser and CLI
+// apps, can't keep a secret and have none. Without a token lifetime the lifetime
+// of the grant is used.
+type Client struct {
+	Id            string   `json:"id"`
+	SecretHash    string   `json:"secretHash"`
+	Scopes        []string `json:"scopes"`
+	RedirectUris  []string `json:"redirectUris"`
+	TokenLifetime Duration `json:"tokenLifetime"`
+}
+
+// IsPublic is true for clients without a secret, they must use PKCE.
+func (c *Client) IsPublic() bool {
+	return c.SecretHash == ""
+}
+
+// Principal is the client authenticated with its secret, or the public client
+// identified by its id, the client id is the subject.
+func (c *Client) Principal() *Principal {
+	scopes := c.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return &Principal{
+		Subject: c.Id,
+		Scopes:  scopes,
+		Amr:     []string{},
+		Roles:   []string{},
+		Client:  true,
+		Claims: map[string]interface{}{
+			"sub":       c.Id,
+			"client_id": c.Id,
+			"scope":     strings.Join(scopes, " "),
+		},
+	}
+}
+
+// HasRedirectUri only allows registered redirect uris, they must match exactly.
+func (c *Client) HasRedirectUri(redirectUri string) bool {
+	for _, registered := range c.RedirectUris {
+		if registered == redirectUri {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientStore verifies client credentials, ErrClientStoreInvalidClient is returned
+// for unknown clients as well as wrong secrets. Public clients can't be verified,
+// GetClient returns them without checking a secret.
+type ClientStore interface {
+	GetClient(id string) (*Client, error)
+	VerifyClient(id string, secret string) (*Client, error)
+}
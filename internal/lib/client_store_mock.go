@@ -0,0 +1,28 @@
+package lib
+
+type ClientStoreMock struct {
+	GetClientCalled    bool
+	VerifyClientCalled bool
+
+	LastId     string
+	LastSecret string
+
+	NextGetClientResult *Client
+	NextGetClientError  error
+
+	NextVerifyClientResult *Client
+	NextVerifyClientError  error
+}
+
+func (m *ClientStoreMock) GetClient(id string) (*Client, error) {
+	m.GetClientCalled = true
+	m.LastId = id
+	return m.NextGetClientResult, m.NextGetClientError
+}
+
+func (m *ClientStoreMock) VerifyClient(id string, secret string) (*Client, error) {
+	m.VerifyClientCalled = true
+	m.LastId = id
+	m.LastSecret = secret
+	return m.NextVerifyClientResult, m.NextVerifyClientError
+}
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var (
+	ErrCompositeOidcProviderUnknownIssuer = errors.New("issuer is not trusted")
+)
+
+// CompositeOidcProvider trusts several issuers at once, for example this service
+// and the company IdP during a migration. The unverified iss claim only selects the
+// provider, which then validates the token including its issuer. Tokens are issued
+// and keys published by the local provider.
+type CompositeOidcProvider struct {
+	local     OidcProvider
+	providers map[string]OidcProvider
+}
+
+// NewCompositeOidcProvider trusts the local provider for issuer and every external
+// provider for the issuer it is mapped to.
+func NewCompositeOidcProvider(issuer string, local OidcProvider, external map[string]OidcProvider) OidcProvider {
+	providers := map[string]OidcProvider{}
+	for externalIssuer, provider := range external {
+		providers[externalIssuer] = provider
+	}
+
+	providers[issuer] = local
+
+	return &CompositeOidcProvider{
+		local:     local,
+		providers: providers,
+	}
+}
+
+func (p *CompositeOidcProvider) GenerateToken(subject string, options TokenOptions) (string, error) {
+	return p.local.GenerateToken(subject, options)
+}
+
+func (p *CompositeOidcProvider) GenerateIdToken(subject string, options IdTokenOptions) (string, error) {
+	return p.local.GenerateIdToken(subject, options)
+}
+
+func (p *CompositeOidcProvider) ValidateToken(tokenString string) (map[string]interface{}, error) {
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(tokenString, claims); err != nil {
+		return nil, unwrapParseError(err)
+	}
+
+	issuer, _ := claims["iss"].(string)
+	provider, ok := p.providers[issuer]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCompositeOidcProviderUnknownIssuer, issuer)
+	}
+
+	return provider.ValidateToken(tokenString)
+}
+
+func (p *CompositeOidcProvider) Jwks() JwkSet {
+	return p.local.Jwks()
+}
@@ -0,0 +1,100 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUnsignedTestToken is enough for the composite, the providers it dispatches to
+// are mocks.
+func newUnsignedTestToken(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.Nil(t, err)
+	return token
+}
+
+func Test_CompositeOidcProvider_ValidateToken_dispatches_on_issuer(t *testing.T) {
+	// Arrange
+	local := &OidcProviderMock{}
+	external := &OidcProviderMock{NextValidateTokenResult: map[string]interface{}{"sub": "some-user"}}
+	sut := NewCompositeOidcProvider("some-issuer", local, map[string]OidcProvider{"external-issuer": external})
+	token := newUnsignedTestToken(t, jwt.MapClaims{"iss": "external-issuer"})
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "some-user", claims["sub"])
+	assert.True(t, external.ValidateTokenCalled)
+	assert.Equal(t, token, external.LastToken)
+	assert.False(t, local.ValidateTokenCalled)
+}
+
+func Test_CompositeOidcProvider_ValidateToken_dispatches_local_issuer_to_local_provider(t *testing.T) {
+	// Arrange
+	local := &OidcProviderMock{NextValidateTokenError: ErrJwtOidcProviderInvalidToken}
+	external := &OidcProviderMock{}
+	sut := NewCompositeOidcProvider("some-issuer", local, map[string]OidcProvider{"external-issuer": external})
+
+	// Act
+	claims, err := sut.ValidateToken(newUnsignedTestToken(t, jwt.MapClaims{"iss": "some-issuer"}))
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.Equal(t, ErrJwtOidcProviderInvalidToken, err)
+	assert.True(t, local.ValidateTokenCalled)
+	assert.False(t, external.ValidateTokenCalled)
+}
+
+func Test_CompositeOidcProvider_ValidateToken_rejects_unknown_issuer(t *testing.T) {
+	for _, claims := range []jwt.MapClaims{{"iss": "other-issuer"}, {"sub": "some-user"}, {"iss": 42}} {
+		// Arrange
+		local := &OidcProviderMock{}
+		sut := NewCompositeOidcProvider("some-issuer", local, map[string]OidcProvider{})
+
+		// Act
+		result, err := sut.ValidateToken(newUnsignedTestToken(t, claims))
+
+		// Assert
+		assert.Nil(t, result)
+		assert.True(t, errors.Is(err, ErrCompositeOidcProviderUnknownIssuer))
+		assert.False(t, local.ValidateTokenCalled)
+	}
+}
+
+func Test_CompositeOidcProvider_ValidateToken_returns_error_on_malformed_token(t *testing.T) {
+	// Arrange
+	local := &OidcProviderMock{}
+	sut := NewCompositeOidcProvider("some-issuer", local, map[string]OidcProvider{})
+
+	// Act
+	claims, err := sut.ValidateToken("some-token")
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.True(t, errors.Is(err, ErrJwtOidcProviderMalformedToken))
+	assert.False(t, local.ValidateTokenCalled)
+}
+
+func Test_CompositeOidcProvider_GenerateToken_uses_local_provider(t *testing.T) {
+	// Arrange
+	local := &OidcProviderMock{NextGenerateTokenResult: "some-token"}
+	external := &OidcProviderMock{}
+	sut := NewCompositeOidcProvider("some-issuer", local, map[string]OidcProvider{"external-issuer": external})
+
+	// Act
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	sut.Jwks()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "some-token", token)
+	assert.True(t, local.JwksCalled)
+	assert.False(t, external.GenerateTokenCalled)
+	assert.False(t, external.JwksCalled)
+}
@@ -0,0 +1,29 @@
+package lib
+
+import (
+	"errors"
+)
+
+var (
+	ErrCredentialStoreInvalidCredentials = errors.New("invalid username or password")
+)
+
+// User has the claims which are added to its tokens, like email, name or tenant,
+// its roles are added as the roles claim.
+// The id is the subject of its tokens, so it stays the same when the username changes.
+type User struct {
+	Id              string                 `json:"id"`
+	Username        string                 `json:"username"`
+	PasswordHash    string                 `json:"passwordHash"`
+	PasswordHistory []string               `json:"passwordHistory,omitempty"`
+	Scopes          []string               `json:"scopes"`
+	Roles           []string               `json:"roles"`
+	Claims          map[string]interface{} `json:"claims"`
+	Disabled        bool                   `json:"disabled"`
+}
+
+// CredentialStore verifies a username and password, ErrCredentialStoreInvalidCredentials
+// is returned for unknown and disabled users as well as wrong passwords.
+type CredentialStore interface {
+	VerifyCredentials(username string, password string) (*User, error)
+}
@@ -0,0 +1,18 @@
+package lib
+
+type CredentialStoreMock struct {
+	VerifyCredentialsCalled bool
+
+	LastUsername string
+	LastPassword string
+
+	NextVerifyCredentialsResult *User
+	NextVerifyCredentialsError  error
+}
+
+func (m *CredentialStoreMock) VerifyCredentials(username string, password string) (*User, error) {
+	m.VerifyCredentialsCalled = true
+	m.LastUsername = username
+	m.LastPassword = password
+	return m.NextVerifyCredentialsResult, m.NextVerifyCredentialsError
+}
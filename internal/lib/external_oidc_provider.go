@@ -0,0 +1,339 @@
+package lib
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var (
+	ErrExternalOidcProviderCannotSign       = errors.New("tokens of an external issuer can only be validated")
+	ErrExternalOidcProviderDiscoveryError   = errors.New("unable to load discovery document")
+	ErrExternalOidcProviderJwksError        = errors.New("unable to load jwks")
+	ErrExternalOidcProviderUnknownKey       = errors.New("unknown key id")
+	ErrExternalOidcProviderInvalidAlgorithm = errors.New("signing algorithm is not allowed")
+	ErrExternalOidcProviderMissingExpiry    = errors.New("token has no expiry")
+)
+
+const (
+	// DefaultJwksCacheLifetime is how long the keys of an external issuer are used
+	// before they are loaded again.
+	DefaultJwksCacheLifetime = time.Hour
+
+	// DefaultJwksRefreshInterval limits how often tokens with an unknown kid can
+	// trigger loading the keys, so they can't be used to flood the issuer.
+	DefaultJwksRefreshInterval = time.Minute
+
+	// maxExternalResponseSize limits the discovery document and jwks which are read.
+	maxExternalResponseSize = 1 << 20
+)
+
+// externalKey is a key of the jwks of an external issuer, the algorithm is empty
+// when the issuer doesn't restrict the key to one.
+type externalKey struct {
+	algorithm string
+	publicKey crypto.PublicKey
+}
+
+// ExternalOidcProvider validates tokens of another OpenID Connect issuer, for
+// example the company IdP. The keys are found through the discovery document of
+// the issuer and cached, a token with an unknown kid loads them again in case the
+// issuer rotated its keys.
+type ExternalOidcProvider struct {
+	issuer          string
+	audience        string
+	algorithms      map[string]bool
+	httpClient      *http.Client
+	cacheLifetime   time.Duration
+	refreshInterval time.Duration
+	leeway          time.Duration
+	now             func() time.Time
+
+	// the keys are loaded without holding the mutex, refreshing is closed once the
+	// load in flight is done so other callers wait for it instead of loading again
+	mutex       sync.Mutex
+	jwksUri     string
+	keys        map[string]externalKey
+	refreshedAt time.Time
+	refreshing  chan struct{}
+}
+
+type ExternalOidcProviderOption func(p *ExternalOidcProvider)
+
+// WithExternalAudience only accepts tokens the issuer issued for audience.
+func WithExternalAudience(audience string) ExternalOidcProviderOption {
+	return func(p *ExternalOidcProvider) {
+		p.audience = audience
+	}
+}
+
+// WithAllowedAlgorithms pins the algorithms tokens may be signed with, RS256 is
+// allowed by default as every OpenID Connect issuer supports it.
+func WithAllowedAlgorithms(algorithms ...string) ExternalOidcProviderOption {
+	return func(p *ExternalOidcProvider) {
+		p.algorithms = map[string]bool{}
+		for _, algorithm := range algorithms {
+			p.algorithms[algorithm] = true
+		}
+	}
+}
+
+// WithHttpClient changes the client the discovery document and jwks are loaded with.
+func WithHttpClient(httpClient *http.Client) ExternalOidcProviderOption {
+	return func(p *ExternalOidcProvider) {
+		p.httpClient = httpClient
+	}
+}
+
+// WithJwksCacheLifetime changes how long the keys of the issuer are cached.
+func WithJwksCacheLifetime(cacheLifetime time.Duration) ExternalOidcProviderOption {
+	return func(p *ExternalOidcProvider) {
+		p.cacheLifetime = cacheLifetime
+	}
+}
+
+// WithExternalLeeway accepts tokens which expired or became valid within the
+// leeway, as the clock of the issuer may drift slightly.
+func WithExternalLeeway(leeway time.Duration) ExternalOidcProviderOption {
+	return func(p *ExternalOidcProvider) {
+		p.leeway = leeway
+	}
+}
+
+func NewExternalOidcProvider(issuer string, options ...ExternalOidcProviderOption) OidcProvider {
+	provider := &ExternalOidcProvider{
+		issuer:          issuer,
+		algorithms:      map[string]bool{"RS256": true},
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+		cacheLifetime:   DefaultJwksCacheLifetime,
+		refreshInterval: DefaultJwksRefreshInterval,
+		now:             time.Now,
+	}
+
+	for _, option := range options {
+		option(provider)
+	}
+
+	return provider
+}
+
+func (p *ExternalOidcProvider) GenerateToken(subject string, options TokenOptions) (string, error) {
+	return "", ErrExternalOidcProviderCannotSign
+}
+
+func (p *ExternalOidcProvider) GenerateIdToken(subject string, options IdTokenOptions) (string, error) {
+	return "", ErrExternalOidcProviderCannotSign
+}
+
+func (p *ExternalOidcProvider) ValidateToken(tokenString string) (map[string]interface{}, error) {
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// the algorithm is checked before anything else, a token must not pick it
+		algorithm := token.Method.Alg()
+		if !p.algorithms[algorithm] {
+			return nil, fmt.Errorf("%w: %s", ErrExternalOidcProviderInvalidAlgorithm, algorithm)
+		}
+
+		if token.Claims.(jwt.MapClaims)["iss"] != p.issuer {
+			return nil, fmt.Errorf("%w: %v", ErrJwtOidcProviderUnknownIssuer, token.Claims.(jwt.MapClaims)["iss"])
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.key(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if key.algorithm != "" && key.algorithm != algorithm {
+			return nil, fmt.Errorf("%w: %s is not the algorithm of key %s", ErrExternalOidcProviderInvalidAlgorithm, algorithm, kid)
+		}
+
+		if err := checkPublicKey(algorithm, key.publicKey); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, kid)
+		}
+
+		return key.publicKey, nil
+	})
+
+	if err != nil {
+		return nil, unwrapParseError(err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, ErrJwtOidcProviderInvalidToken
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, ErrExternalOidcProviderMissingExpiry
+	}
+
+	if err := verifyTimeClaims(claims, p.now(), p.leeway); err != nil {
+		return nil, err
+	}
+
+	if p.audience != "" && !claims.VerifyAudience(p.audience, true) {
+		return nil, ErrJwtOidcProviderInvalidAudience
+	}
+
+	return claims, nil
+}
+
+// Jwks is empty, this service doesn't publish the keys of other issuers.
+func (p *ExternalOidcProvider) Jwks() JwkSet {
+	return JwkSet{Keys: []Jwk{}}
+}
+
+// key returns the cached key, the keys are loaded again when the cache expired or
+// the kid is unknown, but not more often than the refresh interval. Callers with a
+// known kid don't wait for the issuer while the cache is fresh.
+func (p *ExternalOidcProvider) key(kid string) (*externalKey, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	if !now.Before(p.refreshedAt.Add(p.cacheLifetime)) {
+		p.refresh(now)
+	}
+
+	key, ok := p.lookup(kid)
+	if !ok && !now.Before(p.refreshedAt.Add(p.refreshInterval)) {
+		p.refresh(now)
+		key, ok = p.lookup(kid)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrExternalOidcProviderUnknownKey, kid)
+	}
+
+	return &key, nil
+}
+
+// lookup allows tokens without kid when the issuer has a single key.
+func (p *ExternalOidcProvider) lookup(kid string) (externalKey, bool) {
+	if key, ok := p.keys[kid]; ok {
+		return key, true
+	}
+
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	return externalKey{}, false
+}
+
+// refresh keeps the previous keys when loading fails, so tokens signed with known
+// keys are still accepted while the issuer is unavailable. It is called with the
+// mutex held and releases it while loading, callers which come in meanwhile wait
+// for the load in flight.
+func (p *ExternalOidcProvider) refresh(now time.Time) {
+	if p.refreshing != nil {
+		refreshing := p.refreshing
+		p.mutex.Unlock()
+		<-refreshing
+		p.mutex.Lock()
+		return
+	}
+
+	refreshing := make(chan struct{})
+	p.refreshing = refreshing
+	p.refreshedAt = now
+	jwksUri := p.jwksUri
+
+	p.mutex.Unlock()
+	keys, jwksUri, err := p.loadKeys(jwksUri)
+	p.mutex.Lock()
+
+	p.refreshing = nil
+	close(refreshing)
+
+	if err != nil {
+		log.Printf("unable to load keys of %s: %s", p.issuer, err)
+		return
+	}
+
+	p.jwksUri = jwksUri
+	p.keys = keys
+}
+
+// loadKeys discovers the jwks uri when it isn't known yet and returns it with the keys.
+func (p *ExternalOidcProvider) loadKeys(jwksUri string) (map[string]externalKey, string, error) {
+	if jwksUri == "" {
+		discovered, err := p.discover()
+		if err != nil {
+			return nil, "", err
+		}
+
+		jwksUri = discovered
+	}
+
+	var jwks JwkSet
+	if err := p.getJson(jwksUri, &jwks); err != nil {
+		return nil, jwksUri, fmt.Errorf("%w: %s", ErrExternalOidcProviderJwksError, err)
+	}
+
+	keys := map[string]externalKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			log.Printf("ignoring key %s of %s: %s", jwk.Kid, p.issuer, err)
+			continue
+		}
+
+		keys[jwk.Kid] = externalKey{algorithm: jwk.Alg, publicKey: publicKey}
+	}
+
+	return keys, jwksUri, nil
+}
+
+// discover reads the jwks_uri of the discovery document, the issuer of the document
+// must match exactly as required by OpenID Connect Discovery section 4.3.
+func (p *ExternalOidcProvider) discover() (string, error) {
+	var document struct {
+		Issuer  string `json:"issuer"`
+		JwksUri string `json:"jwks_uri"`
+	}
+
+	if err := p.getJson(strings.TrimSuffix(p.issuer, "/")+"/.well-known/openid-configuration", &document); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrExternalOidcProviderDiscoveryError, err)
+	}
+
+	if document.Issuer != p.issuer {
+		return "", fmt.Errorf("%w: issuer %s does not match", ErrExternalOidcProviderDiscoveryError, document.Issuer)
+	}
+
+	if document.JwksUri == "" {
+		return "", fmt.Errorf("%w: jwks_uri is missing", ErrExternalOidcProviderDiscoveryError)
+	}
+
+	return document.JwksUri, nil
+}
+
+func (p *ExternalOidcProvider) getJson(url string, target interface{}) error {
+	res, err := p.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, url)
+	}
+
+	return json.NewDecoder(io.LimitReader(res.Body, maxExternalResponseSize)).Decode(target)
+}
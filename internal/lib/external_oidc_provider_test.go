@@ -0,0 +1,421 @@
+package lib
+
+import (
+	"crypto"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIdp serves the discovery document and jwks of an external issuer.
+type fakeIdp struct {
+	server       *httptest.Server
+	mutex        sync.Mutex
+	issuer       string
+	keys         []Jwk
+	jwksRequests int
+	// blocked jwks requests signal stalled and wait for release
+	stalled chan struct{}
+	release chan struct{}
+}
+
+func newFakeIdp(t *testing.T) *fakeIdp {
+	idp := &fakeIdp{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		idp.mutex.Lock()
+		defer idp.mutex.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   idp.issuer,
+			"jwks_uri": idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.mutex.Lock()
+		stalled, release := idp.stalled, idp.release
+		idp.mutex.Unlock()
+
+		if release != nil {
+			stalled <- struct{}{}
+			<-release
+		}
+
+		idp.mutex.Lock()
+		defer idp.mutex.Unlock()
+
+		idp.jwksRequests++
+		json.NewEncoder(w).Encode(JwkSet{Keys: idp.keys})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	idp.issuer = idp.server.URL
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+// addKey publishes a new key of the IdP and returns it for signing tokens.
+func (idp *fakeIdp) addKey(t *testing.T, algorithm string, kid string) crypto.Signer {
+	key := generateTestKey(t, algorithm)
+	jwk, err := NewJwk(algorithm, key.Public())
+	require.Nil(t, err)
+	jwk.Kid = kid
+
+	idp.mutex.Lock()
+	defer idp.mutex.Unlock()
+
+	idp.keys = append(idp.keys, jwk)
+	return key
+}
+
+// block makes jwks requests wait until the returned function is called.
+func (idp *fakeIdp) block() (stalled <-chan struct{}, release func()) {
+	idp.mutex.Lock()
+	defer idp.mutex.Unlock()
+
+	idp.stalled = make(chan struct{}, 1)
+	idp.release = make(chan struct{})
+	return idp.stalled, func() { close(idp.release) }
+}
+
+func (idp *fakeIdp) requests() int {
+	idp.mutex.Lock()
+	defer idp.mutex.Unlock()
+
+	return idp.jwksRequests
+}
+
+func signExternalTestToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	require.Nil(t, err)
+	return signed
+}
+
+func newExternalTestClaims(issuer string) jwt.MapClaims {
+	now := time.Now().Unix()
+	return jwt.MapClaims{
+		"iss":   issuer,
+		"sub":   "some-user",
+		"aud":   "some-audience",
+		"iat":   now,
+		"exp":   now + 3600,
+		"scope": "sum:compute",
+	}
+}
+
+func Test_ExternalOidcProvider_ValidateToken_accepts_token_of_issuer(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	key := idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer)
+	token := signExternalTestToken(t, jwt.SigningMethodRS256, key, "key-1", newExternalTestClaims(idp.issuer))
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "some-user", claims["sub"])
+	assert.Equal(t, "sum:compute", claims["scope"])
+}
+
+func Test_ExternalOidcProvider_ValidateToken_caches_keys(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	key := idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer)
+	token := signExternalTestToken(t, jwt.SigningMethodRS256, key, "key-1", newExternalTestClaims(idp.issuer))
+
+	// Act
+	_, firstErr := sut.ValidateToken(token)
+	_, secondErr := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+	assert.Equal(t, 1, idp.requests())
+}
+
+func Test_ExternalOidcProvider_ValidateToken_loads_keys_again_after_cache_lifetime(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	key := idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer, WithJwksCacheLifetime(time.Minute))
+	token := signExternalTestToken(t, jwt.SigningMethodRS256, key, "key-1", newExternalTestClaims(idp.issuer))
+
+	_, err := sut.ValidateToken(token)
+	require.Nil(t, err)
+
+	now := time.Now()
+	sut.(*ExternalOidcProvider).now = func() time.Time {
+		return now.Add(time.Minute)
+	}
+
+	// Act
+	_, err = sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 2, idp.requests())
+}
+
+func Test_ExternalOidcProvider_ValidateToken_loads_keys_again_on_unknown_kid(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	key := idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer)
+
+	_, err := sut.ValidateToken(signExternalTestToken(t, jwt.SigningMethodRS256, key, "key-1", newExternalTestClaims(idp.issuer)))
+	require.Nil(t, err)
+
+	rotated := idp.addKey(t, "RS256", "key-2")
+	token := signExternalTestToken(t, jwt.SigningMethodRS256, rotated, "key-2", newExternalTestClaims(idp.issuer))
+
+	now := time.Now()
+	sut.(*ExternalOidcProvider).now = func() time.Time {
+		return now.Add(DefaultJwksRefreshInterval)
+	}
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "some-user", claims["sub"])
+	assert.Equal(t, 2, idp.requests())
+}
+
+func Test_ExternalOidcProvider_ValidateToken_limits_refreshes_on_unknown_kid(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer)
+
+	other := generateTestKey(t, "RS256")
+	token := signExternalTestToken(t, jwt.SigningMethodRS256, other, "other-key", newExternalTestClaims(idp.issuer))
+
+	// Act
+	_, firstErr := sut.ValidateToken(token)
+	_, secondErr := sut.ValidateToken(token)
+
+	// Assert
+	require.NotNil(t, firstErr)
+	require.NotNil(t, secondErr)
+	assert.Contains(t, secondErr.Error(), ErrExternalOidcProviderUnknownKey.Error())
+	assert.Equal(t, 1, idp.requests())
+}
+
+func Test_ExternalOidcProvider_ValidateToken_accepts_known_kid_while_keys_are_loading(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	key := idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer)
+
+	token := signExternalTestToken(t, jwt.SigningMethodRS256, key, "key-1", newExternalTestClaims(idp.issuer))
+	_, err := sut.ValidateToken(token)
+	require.Nil(t, err)
+
+	now := time.Now()
+	sut.(*ExternalOidcProvider).now = func() time.Time {
+		return now.Add(DefaultJwksRefreshInterval)
+	}
+
+	stalled, release := idp.block()
+	other := generateTestKey(t, "RS256")
+	unknown := signExternalTestToken(t, jwt.SigningMethodRS256, other, "other-key", newExternalTestClaims(idp.issuer))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sut.ValidateToken(unknown)
+	}()
+	<-stalled
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+	release()
+	wg.Wait()
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "some-user", claims["sub"])
+	assert.Equal(t, 2, idp.requests())
+}
+
+func Test_ExternalOidcProvider_ValidateToken_loads_keys_once_for_concurrent_unknown_kids(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer)
+
+	other := generateTestKey(t, "RS256")
+	token := signExternalTestToken(t, jwt.SigningMethodRS256, other, "other-key", newExternalTestClaims(idp.issuer))
+	stalled, release := idp.block()
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = sut.ValidateToken(token)
+		}(i)
+	}
+	<-stalled
+	release()
+	wg.Wait()
+
+	// Assert
+	for _, err := range errs {
+		require.NotNil(t, err)
+	}
+	assert.Equal(t, 1, idp.requests())
+}
+
+func Test_ExternalOidcProvider_ValidateToken_rejects_algorithm_which_is_not_allowed(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	key := idp.addKey(t, "ES256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer)
+	token := signExternalTestToken(t, jwt.SigningMethodES256, key, "key-1", newExternalTestClaims(idp.issuer))
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, claims)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), ErrExternalOidcProviderInvalidAlgorithm.Error())
+	assert.Equal(t, 0, idp.requests())
+}
+
+func Test_ExternalOidcProvider_ValidateToken_accepts_pinned_algorithm(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	key := idp.addKey(t, "ES256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer, WithAllowedAlgorithms("ES256"))
+	token := signExternalTestToken(t, jwt.SigningMethodES256, key, "key-1", newExternalTestClaims(idp.issuer))
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "some-user", claims["sub"])
+}
+
+func Test_ExternalOidcProvider_ValidateToken_rejects_unsigned_token(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer)
+	token := signExternalTestToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "key-1", newExternalTestClaims(idp.issuer))
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.NotNil(t, err)
+}
+
+func Test_ExternalOidcProvider_ValidateToken_rejects_token_of_other_issuer(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	key := idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer)
+	token := signExternalTestToken(t, jwt.SigningMethodRS256, key, "key-1", newExternalTestClaims("other-issuer"))
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, claims)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown issuer")
+}
+
+func Test_ExternalOidcProvider_ValidateToken_rejects_discovery_document_of_other_issuer(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	key := idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer)
+	token := signExternalTestToken(t, jwt.SigningMethodRS256, key, "key-1", newExternalTestClaims(idp.issuer))
+
+	idp.mutex.Lock()
+	idp.issuer = "other-issuer"
+	idp.mutex.Unlock()
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, idp.requests())
+}
+
+func Test_ExternalOidcProvider_ValidateToken_rejects_other_audience(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	key := idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer, WithExternalAudience("other-audience"))
+	token := signExternalTestToken(t, jwt.SigningMethodRS256, key, "key-1", newExternalTestClaims(idp.issuer))
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.Equal(t, ErrJwtOidcProviderInvalidAudience, err)
+}
+
+func Test_ExternalOidcProvider_ValidateToken_rejects_expired_token_and_token_without_expiry(t *testing.T) {
+	// Arrange
+	idp := newFakeIdp(t)
+	key := idp.addKey(t, "RS256", "key-1")
+	sut := NewExternalOidcProvider(idp.issuer)
+
+	expired_claims := newExternalTestClaims(idp.issuer)
+	expired_claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	expired := signExternalTestToken(t, jwt.SigningMethodRS256, key, "key-1", expired_claims)
+
+	unlimited_claims := newExternalTestClaims(idp.issuer)
+	delete(unlimited_claims, "exp")
+	unlimited := signExternalTestToken(t, jwt.SigningMethodRS256, key, "key-1", unlimited_claims)
+
+	// Act
+	_, expiredErr := sut.ValidateToken(expired)
+	_, unlimitedErr := sut.ValidateToken(unlimited)
+
+	// Assert
+	assert.NotNil(t, expiredErr)
+	assert.Equal(t, ErrExternalOidcProviderMissingExpiry, unlimitedErr)
+}
+
+func Test_ExternalOidcProvider_GenerateToken_returns_error(t *testing.T) {
+	// Arrange
+	sut := NewExternalOidcProvider("some-issuer")
+
+	// Act
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+
+	// Assert
+	assert.Empty(t, token)
+	assert.Equal(t, ErrExternalOidcProviderCannotSign, err)
+	assert.Empty(t, sut.Jwks().Keys)
+}
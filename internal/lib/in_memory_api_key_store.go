@@ -0,0 +1,115 @@
+package lib
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// apiKeyPrefix makes keys recognizable, for example by secret scanners.
+const apiKeyPrefix = "ak_"
+
+// InMemoryApiKeyStore only keeps a hash of the keys, expired keys are removed when
+// new keys are issued.
+type InMemoryApiKeyStore struct {
+	mutex  sync.Mutex
+	keys   map[string]*ApiKey
+	hashes map[string]string
+	now    func() time.Time
+}
+
+func NewInMemoryApiKeyStore() ApiKeyStore {
+	return &InMemoryApiKeyStore{
+		keys:   map[string]*ApiKey{},
+		hashes: map[string]string{},
+		now:    time.Now,
+	}
+}
+
+// Issue returns the key only once, it can't be read from the store later.
+func (s *InMemoryApiKeyStore) Issue(owner string, scopes []string, lifetime time.Duration) (*ApiKey, string, error) {
+	if owner == "" || lifetime < 0 {
+		return nil, "", ErrApiKeyStoreValidationError
+	}
+
+	if lifetime == 0 {
+		lifetime = DefaultApiKeyLifetime
+	}
+
+	id, err := newRandomId()
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, err := NewRandomToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.removeExpired()
+
+	now := s.now()
+	apiKey := &ApiKey{
+		Id:        id,
+		Owner:     owner,
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: now.Add(lifetime),
+	}
+
+	key := apiKeyPrefix + secret
+	s.keys[hashToken(key)] = apiKey
+	s.hashes[id] = hashToken(key)
+
+	log.Printf("issued api key %s for %s, expires at %s", id, owner, apiKey.ExpiresAt.Format(time.RFC3339))
+
+	issued := *apiKey
+	return &issued, key, nil
+}
+
+func (s *InMemoryApiKeyStore) Verify(key string) (*ApiKey, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	apiKey, ok := s.keys[hashToken(key)]
+	if !ok || !s.now().Before(apiKey.ExpiresAt) {
+		return nil, ErrApiKeyStoreInvalidKey
+	}
+
+	verified := *apiKey
+	return &verified, nil
+}
+
+func (s *InMemoryApiKeyStore) Revoke(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hash, ok := s.hashes[id]
+	if !ok {
+		return ErrApiKeyStoreUnknownKey
+	}
+
+	delete(s.keys, hash)
+	delete(s.hashes, id)
+
+	log.Printf("revoked api key %s", id)
+	return nil
+}
+
+func (s *InMemoryApiKeyStore) removeExpired() {
+	now := s.now()
+
+	for hash, apiKey := range s.keys {
+		if !now.Before(apiKey.ExpiresAt) {
+			delete(s.keys, hash)
+			delete(s.hashes, apiKey.Id)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package lib
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InMemoryApiKeyStore_Verify_returns_key_of_owner(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryApiKeyStore()
+	issued, key, err := sut.Issue("some-owner", []string{"some-scope"}, time.Hour)
+	require.Nil(t, err)
+
+	// Act
+	apiKey, err := sut.Verify(key)
+
+	// Assert
+	require.Nil(t, err)
+	assert.True(t, strings.HasPrefix(key, "ak_"))
+	assert.Equal(t, issued.Id, apiKey.Id)
+	assert.Equal(t, "some-owner", apiKey.Owner)
+	assert.Equal(t, []string{"some-scope"}, apiKey.Scopes)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), apiKey.ExpiresAt, time.Minute)
+}
+
+func Test_InMemoryApiKeyStore_Verify_returns_error_on_unknown_key(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryApiKeyStore()
+	_, key, err := sut.Issue("some-owner", nil, time.Hour)
+	require.Nil(t, err)
+
+	// Act
+	apiKey, err := sut.Verify(key + "x")
+
+	// Assert
+	assert.Nil(t, apiKey)
+	assert.True(t, errors.Is(err, ErrApiKeyStoreInvalidKey))
+}
+
+func Test_InMemoryApiKeyStore_Verify_returns_error_on_expired_key(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryApiKeyStore()
+	now := time.Now()
+	sut.(*InMemoryApiKeyStore).now = func() time.Time {
+		return now
+	}
+
+	_, key, err := sut.Issue("some-owner", nil, time.Hour)
+	require.Nil(t, err)
+
+	sut.(*InMemoryApiKeyStore).now = func() time.Time {
+		return now.Add(time.Hour)
+	}
+
+	// Act
+	apiKey, err := sut.Verify(key)
+
+	// Assert
+	assert.Nil(t, apiKey)
+	assert.True(t, errors.Is(err, ErrApiKeyStoreInvalidKey))
+}
+
+func Test_InMemoryApiKeyStore_Verify_returns_error_on_revoked_key(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryApiKeyStore()
+	issued, key, err := sut.Issue("some-owner", nil, time.Hour)
+	require.Nil(t, err)
+
+	// Act
+	revokeErr := sut.Revoke(issued.Id)
+	apiKey, err := sut.Verify(key)
+
+	// Assert
+	assert.Nil(t, revokeErr)
+	assert.Nil(t, apiKey)
+	assert.True(t, errors.Is(err, ErrApiKeyStoreInvalidKey))
+}
+
+func Test_InMemoryApiKeyStore_Revoke_returns_error_on_unknown_id(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryApiKeyStore()
+
+	// Act
+	err := sut.Revoke("some-id")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrApiKeyStoreUnknownKey))
+}
+
+func Test_InMemoryApiKeyStore_Issue_uses_default_lifetime_and_rejects_missing_owner(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryApiKeyStore()
+
+	// Act
+	issued, _, err := sut.Issue("some-owner", nil, 0)
+	_, _, ownerErr := sut.Issue("", nil, time.Hour)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, DefaultApiKeyLifetime, issued.ExpiresAt.Sub(issued.CreatedAt))
+	assert.Equal(t, []string{}, issued.Scopes)
+	assert.True(t, errors.Is(ownerErr, ErrApiKeyStoreValidationError))
+}
+
+func Test_ApiKey_Principal_uses_owner_as_subject(t *testing.T) {
+	// Arrange
+	sut := &ApiKey{Id: "some-id", Owner: "some-owner", Scopes: []string{"some-scope", "other-scope"}}
+
+	// Act
+	principal := sut.Principal()
+
+	// Assert
+	assert.Equal(t, "some-owner", principal.Subject)
+	assert.True(t, principal.HasScope("other-scope"))
+	assert.False(t, principal.HasAmr("pwd"))
+	assert.True(t, principal.Client)
+	assert.Equal(t, "some-id", principal.Claims["api_key_id"])
+}
@@ -0,0 +1,83 @@
+package lib
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultAuthorizationCodeLifetime is the maximum recommended by RFC 6749, the
+// client exchanges the code right after the redirect.
+const DefaultAuthorizationCodeLifetime = 10 * time.Minute
+
+type authorizationCodeEntry struct {
+	grant     AuthorizationGrant
+	expiresAt time.Time
+}
+
+// InMemoryAuthorizationCodeStore only keeps a hash of the codes, expired codes are
+// removed when new codes are issued.
+type InMemoryAuthorizationCodeStore struct {
+	mutex    sync.Mutex
+	codes    map[string]*authorizationCodeEntry
+	lifetime time.Duration
+	now      func() time.Time
+}
+
+func NewInMemoryAuthorizationCodeStore(lifetime time.Duration) AuthorizationCodeStore {
+	return &InMemoryAuthorizationCodeStore{
+		codes:    map[string]*authorizationCodeEntry{},
+		lifetime: lifetime,
+		now:      time.Now,
+	}
+}
+
+func (s *InMemoryAuthorizationCodeStore) Issue(grant AuthorizationGrant) (string, error) {
+	code, err := NewRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.removeExpired()
+	s.codes[hashToken(code)] = &authorizationCodeEntry{
+		grant:     grant,
+		expiresAt: s.now().Add(s.lifetime),
+	}
+
+	return code, nil
+}
+
+// Redeem removes the code, so it can only be exchanged once.
+func (s *InMemoryAuthorizationCodeStore) Redeem(code string) (*AuthorizationGrant, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hash := hashToken(code)
+	entry, ok := s.codes[hash]
+	if !ok {
+		return nil, ErrAuthorizationCodeStoreInvalidCode
+	}
+
+	delete(s.codes, hash)
+
+	if !s.now().Before(entry.expiresAt) {
+		log.Printf("expired authorization code presented for %s", entry.grant.Subject)
+		return nil, ErrAuthorizationCodeStoreInvalidCode
+	}
+
+	grant := entry.grant
+	return &grant, nil
+}
+
+func (s *InMemoryAuthorizationCodeStore) removeExpired() {
+	now := s.now()
+
+	for hash, entry := range s.codes {
+		if !now.Before(entry.expiresAt) {
+			delete(s.codes, hash)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InMemoryAuthorizationCodeStore_Redeem_returns_grant(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryAuthorizationCodeStore(time.Minute)
+	code, err := sut.Issue(AuthorizationGrant{ClientId: "some-client", Subject: "some-user"})
+	require.Nil(t, err)
+
+	// Act
+	grant, err := sut.Redeem(code)
+
+	// Assert
+	require.Nil(t, err)
+	require.NotNil(t, grant)
+	assert.Equal(t, "some-client", grant.ClientId)
+	assert.Equal(t, "some-user", grant.Subject)
+}
+
+func Test_InMemoryAuthorizationCodeStore_Redeem_returns_error_on_second_use(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryAuthorizationCodeStore(time.Minute)
+	code, err := sut.Issue(AuthorizationGrant{Subject: "some-user"})
+	require.Nil(t, err)
+	_, err = sut.Redeem(code)
+	require.Nil(t, err)
+
+	// Act
+	grant, err := sut.Redeem(code)
+
+	// Assert
+	assert.Nil(t, grant)
+	assert.True(t, errors.Is(err, ErrAuthorizationCodeStoreInvalidCode))
+}
+
+func Test_InMemoryAuthorizationCodeStore_Redeem_returns_error_on_expired_code(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := NewInMemoryAuthorizationCodeStore(time.Minute)
+	sut.(*InMemoryAuthorizationCodeStore).now = func() time.Time { return now }
+	code, err := sut.Issue(AuthorizationGrant{Subject: "some-user"})
+	require.Nil(t, err)
+
+	now = now.Add(time.Minute)
+
+	// Act
+	grant, err := sut.Redeem(code)
+
+	// Assert
+	assert.Nil(t, grant)
+	assert.True(t, errors.Is(err, ErrAuthorizationCodeStoreInvalidCode))
+}
+
+func Test_InMemoryAuthorizationCodeStore_Redeem_returns_error_on_unknown_code(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryAuthorizationCodeStore(time.Minute)
+
+	// Act
+	grant, err := sut.Redeem("some-code")
+
+	// Assert
+	assert.Nil(t, grant)
+	assert.True(t, errors.Is(err, ErrAuthorizationCodeStoreInvalidCode))
+}
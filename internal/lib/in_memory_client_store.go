@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInMemoryClientStoreInvalidClient = errors.New("client needs an id and a bcrypt secret hash or no secret")
+)
+
+// InMemoryClientStore keeps clients with bcrypt secret hashes in memory, it can be
+// loaded from a JSON file with LoadClientStore.
+type InMemoryClientStore struct {
+	mutex   sync.RWMutex
+	clients map[string]Client
+}
+
+func NewInMemoryClientStore(clients []Client) (*InMemoryClientStore, error) {
+	store := &InMemoryClientStore{
+		clients: map[string]Client{},
+	}
+
+	for _, client := range clients {
+		if client.Id == "" {
+			return nil, ErrInMemoryClientStoreInvalidClient
+		}
+
+		if client.IsPublic() {
+			store.clients[client.Id] = client
+			continue
+		}
+
+		if _, err := bcrypt.Cost([]byte(client.SecretHash)); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInMemoryClientStoreInvalidClient, client.Id)
+		}
+
+		store.clients[client.Id] = client
+	}
+
+	return store, nil
+}
+
+// LoadClientStore reads a JSON array of clients, hashes are created the same way
+// as the password hashes of the credential store.
+func LoadClientStore(path string) (*InMemoryClientStore, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var clients []Client
+	if err := json.Unmarshal(content, &clients); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	return NewInMemoryClientStore(clients)
+}
+
+// List returns the clients in no particular order.
+func (s *InMemoryClientStore) List() []Client {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	clients := make([]Client, 0, len(s.clients))
+	for _, client := range s.clients {
+		clients = append(clients, client)
+	}
+
+	return clients
+}
+
+func (s *InMemoryClientStore) GetClient(id string) (*Client, error) {
+	s.mutex.RLock()
+	client, ok := s.clients[id]
+	s.mutex.RUnlock()
+
+	if !ok {
+		return nil, ErrClientStoreInvalidClient
+	}
+
+	return &client, nil
+}
+
+func (s *InMemoryClientStore) VerifyClient(id string, secret string) (*Client, error) {
+	s.mutex.RLock()
+	client, ok := s.clients[id]
+	s.mutex.RUnlock()
+
+	if !ok || client.IsPublic() {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(secret))
+		return nil, ErrClientStoreInvalidClient
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(client.SecretHash), []byte(secret)); err != nil {
+		return nil, ErrClientStoreInvalidClient
+	}
+
+	return &client, nil
+}
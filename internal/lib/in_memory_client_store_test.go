@@ -0,0 +1,146 @@
+package lib
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InMemoryClientStore_VerifyClient_returns_client_on_valid_secret(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryClientStore([]Client{
+		{Id: "some-client", SecretHash: hashTestPassword(t, "some-secret")},
+	})
+	require.Nil(t, err)
+
+	// Act
+	client, err := sut.VerifyClient("some-client", "some-secret")
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, client)
+	assert.Equal(t, "some-client", client.Id)
+}
+
+func Test_InMemoryClientStore_VerifyClient_returns_error_on_wrong_secret(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryClientStore([]Client{
+		{Id: "some-client", SecretHash: hashTestPassword(t, "some-secret")},
+	})
+	require.Nil(t, err)
+
+	// Act
+	client, err := sut.VerifyClient("some-client", "wrong-secret")
+
+	// Assert
+	assert.Nil(t, client)
+	assert.True(t, errors.Is(err, ErrClientStoreInvalidClient))
+}
+
+func Test_InMemoryClientStore_VerifyClient_returns_error_on_unknown_client(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryClientStore([]Client{})
+	require.Nil(t, err)
+
+	// Act
+	client, err := sut.VerifyClient("some-client", "some-secret")
+
+	// Assert
+	assert.Nil(t, client)
+	assert.True(t, errors.Is(err, ErrClientStoreInvalidClient))
+}
+
+func Test_InMemoryClientStore_VerifyClient_returns_error_on_public_client(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryClientStore([]Client{
+		{Id: "some-client"},
+	})
+	require.Nil(t, err)
+
+	// Act
+	client, err := sut.VerifyClient("some-client", "")
+
+	// Assert
+	assert.Nil(t, client)
+	assert.True(t, errors.Is(err, ErrClientStoreInvalidClient))
+}
+
+func Test_InMemoryClientStore_GetClient_returns_public_client(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryClientStore([]Client{
+		{Id: "some-client", RedirectUris: []string{"http://localhost/callback"}},
+	})
+	require.Nil(t, err)
+
+	// Act
+	client, err := sut.GetClient("some-client")
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, client)
+	assert.True(t, client.IsPublic())
+	assert.True(t, client.HasRedirectUri("http://localhost/callback"))
+	assert.False(t, client.HasRedirectUri("http://localhost/callback/other"))
+}
+
+func Test_InMemoryClientStore_GetClient_returns_error_on_unknown_client(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryClientStore([]Client{})
+	require.Nil(t, err)
+
+	// Act
+	client, err := sut.GetClient("some-client")
+
+	// Assert
+	assert.Nil(t, client)
+	assert.True(t, errors.Is(err, ErrClientStoreInvalidClient))
+}
+
+func Test_InMemoryClientStore_List_returns_clients(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryClientStore([]Client{
+		{Id: "some-client", TokenLifetime: Duration(time.Hour)},
+		{Id: "other-client"},
+	})
+	require.Nil(t, err)
+
+	// Act
+	clients := sut.List()
+
+	// Assert
+	assert.ElementsMatch(t, []Client{
+		{Id: "some-client", TokenLifetime: Duration(time.Hour)},
+		{Id: "other-client"},
+	}, clients)
+}
+
+func Test_NewInMemoryClientStore_returns_error_on_plain_text_secret(t *testing.T) {
+	// Act
+	sut, err := NewInMemoryClientStore([]Client{
+		{Id: "some-client", SecretHash: "some-secret"},
+	})
+
+	// Assert
+	assert.Nil(t, sut)
+	assert.True(t, errors.Is(err, ErrInMemoryClientStoreInvalidClient))
+}
+
+func Test_LoadClientStore_reads_clients_from_json_file(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "clients.json")
+	content := `[{"id":"some-client","secretHash":"` + hashTestPassword(t, "some-secret") + `"}]`
+	require.Nil(t, os.WriteFile(path, []byte(content), 0600))
+
+	// Act
+	sut, err := LoadClientStore(path)
+
+	// Assert
+	require.Nil(t, err)
+	_, err = sut.VerifyClient("some-client", "some-secret")
+	assert.Nil(t, err)
+}
@@ -0,0 +1,271 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MaxPasswordHistory is how many earlier password hashes are kept per user, the
+// PasswordPolicy decides how many of them are checked and can't check more.
+const MaxPasswordHistory = 24
+
+// dummyPasswordHash is compared against for unknown users, so the response time
+// doesn't reveal whether a username exists.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// InMemoryCredentialStore keeps users with bcrypt password hashes in memory, it
+// can be loaded from a JSON file with LoadCredentialStore. Users are managed
+// through its UserStore methods.
+type InMemoryCredentialStore struct {
+	mutex     sync.RWMutex
+	users     map[string]User
+	usernames map[string]string
+}
+
+// NewInMemoryCredentialStore assigns a random id to users without one, the id
+// has to be part of the users to stay the same across restarts.
+func NewInMemoryCredentialStore(users []User) (*InMemoryCredentialStore, error) {
+	store := &InMemoryCredentialStore{
+		users:     map[string]User{},
+		usernames: map[string]string{},
+	}
+
+	for _, user := range users {
+		if _, err := store.add(user); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// LoadCredentialStore reads a JSON array of users, hashes can be created with
+// `htpasswd -bnBC 10 "" <password> | tr -d ':\n'`.
+func LoadCredentialStore(path string) (*InMemoryCredentialStore, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []User
+	if err := json.Unmarshal(content, &users); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	return NewInMemoryCredentialStore(users)
+}
+
+// HashPassword creates the bcrypt hash stored for a user.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+func (s *InMemoryCredentialStore) VerifyCredentials(username string, password string) (*User, error) {
+	s.mutex.RLock()
+	user, ok := s.users[s.usernames[username]]
+	s.mutex.RUnlock()
+
+	if !ok || user.Disabled {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, ErrCredentialStoreInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrCredentialStoreInvalidCredentials
+	}
+
+	return &user, nil
+}
+
+// Claims implements ClaimsEnricher with the claims of the user, the username is
+// added as preferred_username as the subject is the id. Unknown subjects have no
+// claims.
+func (s *InMemoryCredentialStore) Claims(subject string) (map[string]interface{}, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	user, ok := s.users[subject]
+	if !ok {
+		return nil, nil
+	}
+
+	claims := map[string]interface{}{}
+	for name, value := range user.Claims {
+		claims[name] = value
+	}
+
+	claims["preferred_username"] = user.Username
+	return claims, nil
+}
+
+func (s *InMemoryCredentialStore) Create(user User) (*User, error) {
+	user.Id = ""
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	created, err := s.add(user)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("created user %s with id %s", created.Username, created.Id)
+	return created, nil
+}
+
+// List returns the users ordered by username.
+func (s *InMemoryCredentialStore) List() ([]User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	users := make([]User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+
+	return users, nil
+}
+
+func (s *InMemoryCredentialStore) Get(id string) (*User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, ErrUserStoreUnknownUser
+	}
+
+	return &user, nil
+}
+
+func (s *InMemoryCredentialStore) GetByUsername(username string) (*User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	user, ok := s.users[s.usernames[username]]
+	if !ok {
+		return nil, ErrUserStoreUnknownUser
+	}
+
+	return &user, nil
+}
+
+func (s *InMemoryCredentialStore) SetDisabled(id string, disabled bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return ErrUserStoreUnknownUser
+	}
+
+	user.Disabled = disabled
+	s.users[id] = user
+	return nil
+}
+
+func (s *InMemoryCredentialStore) SetPasswordHash(id string, passwordHash string) error {
+	if _, err := bcrypt.Cost([]byte(passwordHash)); err != nil {
+		return fmt.Errorf("%w: %s", ErrUserStoreValidationError, id)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return ErrUserStoreUnknownUser
+	}
+
+	user.PasswordHistory = append([]string{user.PasswordHash}, user.PasswordHistory...)
+	if len(user.PasswordHistory) > MaxPasswordHistory {
+		user.PasswordHistory = user.PasswordHistory[:MaxPasswordHistory]
+	}
+
+	user.PasswordHash = passwordHash
+	s.users[id] = user
+	return nil
+}
+
+func (s *InMemoryCredentialStore) SetRoles(id string, roles []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return ErrUserStoreUnknownUser
+	}
+
+	user.Roles = append([]string{}, roles...)
+	s.users[id] = user
+	return nil
+}
+
+func (s *InMemoryCredentialStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return ErrUserStoreUnknownUser
+	}
+
+	delete(s.users, id)
+	delete(s.usernames, user.Username)
+
+	log.Printf("deleted user %s with id %s", user.Username, id)
+	return nil
+}
+
+// add validates the user and assigns an id if it has none, the caller holds the lock.
+func (s *InMemoryCredentialStore) add(user User) (*User, error) {
+	if user.Username == "" {
+		return nil, ErrUserStoreValidationError
+	}
+
+	if _, err := bcrypt.Cost([]byte(user.PasswordHash)); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserStoreValidationError, user.Username)
+	}
+
+	for name := range user.Claims {
+		if IsReservedClaim(name) {
+			return nil, fmt.Errorf("%w: %s has reserved claim %s", ErrUserStoreValidationError, user.Username, name)
+		}
+	}
+
+	if _, ok := s.usernames[user.Username]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrUserStoreDuplicateUsername, user.Username)
+	}
+
+	if user.Id == "" {
+		id, err := newRandomId()
+		if err != nil {
+			return nil, err
+		}
+
+		user.Id = id
+	}
+
+	if _, ok := s.users[user.Id]; ok {
+		return nil, fmt.Errorf("%w: id %s is used twice", ErrUserStoreValidationError, user.Id)
+	}
+
+	s.users[user.Id] = user
+	s.usernames[user.Username] = user.Id
+	return &user, nil
+}
@@ -0,0 +1,394 @@
+package lib
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func hashTestPassword(t *testing.T, password string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.Nil(t, err)
+	return string(hash)
+}
+
+func Test_InMemoryCredentialStore_VerifyCredentials_returns_user_on_valid_credentials(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")},
+	})
+	require.Nil(t, err)
+
+	// Act
+	user, err := sut.VerifyCredentials("some-user", "some-password")
+
+	// Assert
+	assert.Nil(t, err)
+	require.NotNil(t, user)
+	assert.Equal(t, "some-user", user.Username)
+}
+
+func Test_InMemoryCredentialStore_VerifyCredentials_returns_error_on_wrong_password(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")},
+	})
+	require.Nil(t, err)
+
+	// Act
+	user, err := sut.VerifyCredentials("some-user", "wrong-password")
+
+	// Assert
+	assert.Nil(t, user)
+	assert.True(t, errors.Is(err, ErrCredentialStoreInvalidCredentials))
+}
+
+func Test_InMemoryCredentialStore_VerifyCredentials_returns_error_on_unknown_user(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{})
+	require.Nil(t, err)
+
+	// Act
+	user, err := sut.VerifyCredentials("some-user", "some-password")
+
+	// Assert
+	assert.Nil(t, user)
+	assert.True(t, errors.Is(err, ErrCredentialStoreInvalidCredentials))
+}
+
+func Test_NewInMemoryCredentialStore_returns_error_on_plain_text_password(t *testing.T) {
+	// Act
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Username: "some-user", PasswordHash: "some-password"},
+	})
+
+	// Assert
+	assert.Nil(t, sut)
+	assert.True(t, errors.Is(err, ErrUserStoreValidationError))
+}
+
+func Test_NewInMemoryCredentialStore_returns_error_on_reserved_claim(t *testing.T) {
+	// Act
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Username: "some-user", PasswordHash: hashTestPassword(t, "some-password"), Claims: map[string]interface{}{"sub": "other-user"}},
+	})
+
+	// Assert
+	assert.Nil(t, sut)
+	assert.True(t, errors.Is(err, ErrUserStoreValidationError))
+}
+
+func Test_NewInMemoryCredentialStore_returns_error_on_preferred_username_claim(t *testing.T) {
+	// Act
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Username: "some-user", PasswordHash: hashTestPassword(t, "some-password"), Claims: map[string]interface{}{"preferred_username": "other-user"}},
+	})
+
+	// Assert
+	assert.Nil(t, sut)
+	assert.True(t, errors.Is(err, ErrUserStoreValidationError))
+}
+
+func Test_InMemoryCredentialStore_Claims_returns_claims_of_user(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Id: "some-id", Username: "some-user", PasswordHash: hashTestPassword(t, "some-password"), Claims: map[string]interface{}{"email": "some-user@example.com"}},
+	})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.Claims("some-id")
+	unknown, unknownErr := sut.Claims("some-client")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"email": "some-user@example.com", "preferred_username": "some-user"}, claims)
+	assert.Nil(t, unknownErr)
+	assert.Empty(t, unknown)
+}
+
+func Test_NewInMemoryCredentialStore_assigns_id_to_users_without_one(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Id: "some-id", Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")},
+		{Username: "other-user", PasswordHash: hashTestPassword(t, "other-password")},
+	})
+	require.Nil(t, err)
+
+	// Act
+	user, userErr := sut.VerifyCredentials("some-user", "some-password")
+	other, otherErr := sut.VerifyCredentials("other-user", "other-password")
+
+	// Assert
+	require.Nil(t, userErr)
+	require.Nil(t, otherErr)
+	assert.Equal(t, "some-id", user.Id)
+	assert.NotEmpty(t, other.Id)
+	assert.NotEqual(t, "other-user", other.Id)
+}
+
+func Test_NewInMemoryCredentialStore_returns_error_on_duplicate_username(t *testing.T) {
+	// Act
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")},
+		{Username: "some-user", PasswordHash: hashTestPassword(t, "other-password")},
+	})
+
+	// Assert
+	assert.Nil(t, sut)
+	assert.True(t, errors.Is(err, ErrUserStoreDuplicateUsername))
+}
+
+func Test_InMemoryCredentialStore_Create_adds_user_with_new_id(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{})
+	require.Nil(t, err)
+
+	// Act
+	user, err := sut.Create(User{Id: "chosen-id", Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")})
+
+	// Assert
+	require.Nil(t, err)
+	assert.NotEmpty(t, user.Id)
+	assert.NotEqual(t, "chosen-id", user.Id)
+	verified, err := sut.VerifyCredentials("some-user", "some-password")
+	require.Nil(t, err)
+	assert.Equal(t, user.Id, verified.Id)
+}
+
+func Test_InMemoryCredentialStore_Create_returns_error_on_invalid_user(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")},
+	})
+	require.Nil(t, err)
+
+	for expected, user := range map[error]User{
+		ErrUserStoreValidationError:   {Username: "", PasswordHash: hashTestPassword(t, "some-password")},
+		ErrUserStoreDuplicateUsername: {Username: "some-user", PasswordHash: hashTestPassword(t, "other-password")},
+	} {
+		// Act
+		created, err := sut.Create(user)
+
+		// Assert
+		assert.Nil(t, created)
+		assert.True(t, errors.Is(err, expected))
+	}
+}
+
+func Test_InMemoryCredentialStore_List_returns_users_ordered_by_username(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Username: "other-user", PasswordHash: hashTestPassword(t, "other-password")},
+		{Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")},
+		{Username: "another-user", PasswordHash: hashTestPassword(t, "another-password")},
+	})
+	require.Nil(t, err)
+
+	// Act
+	users, err := sut.List()
+
+	// Assert
+	require.Nil(t, err)
+	require.Len(t, users, 3)
+	assert.Equal(t, "another-user", users[0].Username)
+	assert.Equal(t, "other-user", users[1].Username)
+	assert.Equal(t, "some-user", users[2].Username)
+}
+
+func Test_InMemoryCredentialStore_SetDisabled_rejects_credentials_of_disabled_user(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Id: "some-id", Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")},
+	})
+	require.Nil(t, err)
+
+	// Act
+	disableErr := sut.SetDisabled("some-id", true)
+	_, disabledErr := sut.VerifyCredentials("some-user", "some-password")
+	enableErr := sut.SetDisabled("some-id", false)
+	_, enabledErr := sut.VerifyCredentials("some-user", "some-password")
+
+	// Assert
+	assert.Nil(t, disableErr)
+	assert.True(t, errors.Is(disabledErr, ErrCredentialStoreInvalidCredentials))
+	assert.Nil(t, enableErr)
+	assert.Nil(t, enabledErr)
+}
+
+func Test_InMemoryCredentialStore_SetPasswordHash_replaces_password(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Id: "some-id", Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")},
+	})
+	require.Nil(t, err)
+
+	// Act
+	err = sut.SetPasswordHash("some-id", hashTestPassword(t, "new-password"))
+
+	// Assert
+	require.Nil(t, err)
+	_, oldErr := sut.VerifyCredentials("some-user", "some-password")
+	_, newErr := sut.VerifyCredentials("some-user", "new-password")
+	assert.True(t, errors.Is(oldErr, ErrCredentialStoreInvalidCredentials))
+	assert.Nil(t, newErr)
+}
+
+func Test_InMemoryCredentialStore_SetPasswordHash_keeps_password_history(t *testing.T) {
+	// Arrange
+	first := hashTestPassword(t, "first-password")
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Id: "some-id", Username: "some-user", PasswordHash: first},
+	})
+	require.Nil(t, err)
+
+	second := hashTestPassword(t, "second-password")
+	require.Nil(t, sut.SetPasswordHash("some-id", second))
+
+	for i := 0; i < MaxPasswordHistory; i++ {
+		require.Nil(t, sut.SetPasswordHash("some-id", second))
+	}
+
+	// Act
+	err = sut.SetPasswordHash("some-id", hashTestPassword(t, "third-password"))
+
+	// Assert
+	require.Nil(t, err)
+	user, err := sut.Get("some-id")
+	require.Nil(t, err)
+	assert.Len(t, user.PasswordHistory, MaxPasswordHistory)
+	assert.Equal(t, second, user.PasswordHistory[0])
+	assert.NotContains(t, user.PasswordHistory, first)
+}
+
+func Test_InMemoryCredentialStore_Get_returns_user_by_id_and_username(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Id: "some-id", Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")},
+	})
+	require.Nil(t, err)
+
+	// Act
+	byId, byIdErr := sut.Get("some-id")
+	byUsername, byUsernameErr := sut.GetByUsername("some-user")
+	_, unknownIdErr := sut.Get("some-user")
+	_, unknownUsernameErr := sut.GetByUsername("some-id")
+
+	// Assert
+	require.Nil(t, byIdErr)
+	require.Nil(t, byUsernameErr)
+	assert.Equal(t, "some-user", byId.Username)
+	assert.Equal(t, "some-id", byUsername.Id)
+	assert.Equal(t, ErrUserStoreUnknownUser, unknownIdErr)
+	assert.Equal(t, ErrUserStoreUnknownUser, unknownUsernameErr)
+}
+
+func Test_InMemoryCredentialStore_SetPasswordHash_returns_error_on_plain_text_password(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Id: "some-id", Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")},
+	})
+	require.Nil(t, err)
+
+	// Act
+	err = sut.SetPasswordHash("some-id", "new-password")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrUserStoreValidationError))
+}
+
+func Test_InMemoryCredentialStore_Delete_removes_user(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Id: "some-id", Username: "some-user", PasswordHash: hashTestPassword(t, "some-password")},
+	})
+	require.Nil(t, err)
+
+	// Act
+	err = sut.Delete("some-id")
+
+	// Assert
+	require.Nil(t, err)
+	_, verifyErr := sut.VerifyCredentials("some-user", "some-password")
+	assert.True(t, errors.Is(verifyErr, ErrCredentialStoreInvalidCredentials))
+	claims, _ := sut.Claims("some-id")
+	assert.Empty(t, claims)
+}
+
+func Test_InMemoryCredentialStore_SetRoles_replaces_roles(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{
+		{Id: "some-id", Username: "some-user", PasswordHash: hashTestPassword(t, "some-password"), Roles: []string{"some-role"}},
+	})
+	require.Nil(t, err)
+
+	// Act
+	err = sut.SetRoles("some-id", []string{"other-role"})
+
+	// Assert
+	require.Nil(t, err)
+	user, _ := sut.Get("some-id")
+	assert.Equal(t, []string{"other-role"}, user.Roles)
+	verified, _ := sut.VerifyCredentials("some-user", "some-password")
+	assert.Equal(t, []string{"other-role"}, verified.Roles)
+}
+
+func Test_InMemoryCredentialStore_returns_error_on_unknown_id(t *testing.T) {
+	// Arrange
+	sut, err := NewInMemoryCredentialStore([]User{})
+	require.Nil(t, err)
+
+	// Act
+	disableErr := sut.SetDisabled("some-id", true)
+	passwordErr := sut.SetPasswordHash("some-id", hashTestPassword(t, "some-password"))
+	rolesErr := sut.SetRoles("some-id", []string{"some-role"})
+	deleteErr := sut.Delete("some-id")
+
+	// Assert
+	assert.Equal(t, ErrUserStoreUnknownUser, disableErr)
+	assert.Equal(t, ErrUserStoreUnknownUser, passwordErr)
+	assert.Equal(t, ErrUserStoreUnknownUser, rolesErr)
+	assert.Equal(t, ErrUserStoreUnknownUser, deleteErr)
+}
+
+func Test_LoadCredentialStore_reads_users_from_json_file(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	content := `[{"username":"some-user","passwordHash":"` + hashTestPassword(t, "some-password") + `"}]`
+	require.Nil(t, os.WriteFile(path, []byte(content), 0600))
+
+	// Act
+	sut, err := LoadCredentialStore(path)
+
+	// Assert
+	require.Nil(t, err)
+	_, err = sut.VerifyCredentials("some-user", "some-password")
+	assert.Nil(t, err)
+}
+
+func Test_LoadCredentialStore_returns_error_on_invalid_json(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.Nil(t, os.WriteFile(path, []byte("invalid-json"), 0600))
+
+	// Act
+	sut, err := LoadCredentialStore(path)
+
+	// Assert
+	assert.Nil(t, sut)
+	assert.NotNil(t, err)
+}
+
+func Test_HashPassword_creates_verifiable_bcrypt_hash(t *testing.T) {
+	// Act
+	hash, err := HashPassword("some-password")
+
+	// Assert
+	require.Nil(t, err)
+	assert.Nil(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("some-password")))
+}
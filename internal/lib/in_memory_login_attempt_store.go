@@ -0,0 +1,241 @@
+package lib
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// LoginAttemptPolicy allows a number of failed logins, every further failure locks
+// the key out for twice as long as the previous one, up to the max lockout. The
+// failures are forgotten once there was none for the reset period. At most
+// MaxEntries keys are tracked, DefaultMaxLoginAttemptEntries without it.
+type LoginAttemptPolicy struct {
+	FreeAttempts int
+	BaseLockout  time.Duration
+	MaxLockout   time.Duration
+	ResetAfter   time.Duration
+	MaxEntries   int
+}
+
+// DefaultMaxLoginAttemptEntries bounds the memory a spray of random usernames or
+// ips can take up.
+const DefaultMaxLoginAttemptEntries = 100000
+
+var (
+	// DefaultUsernameLoginAttemptPolicy locks out a username after 5 failed logins.
+	DefaultUsernameLoginAttemptPolicy = LoginAttemptPolicy{
+		FreeAttempts: 5,
+		BaseLockout:  30 * time.Second,
+		MaxLockout:   15 * time.Minute,
+		ResetAfter:   time.Hour,
+	}
+
+	// DefaultIpLoginAttemptPolicy allows more failures, as several users can share
+	// an ip behind a NAT or proxy.
+	DefaultIpLoginAttemptPolicy = LoginAttemptPolicy{
+		FreeAttempts: 20,
+		BaseLockout:  30 * time.Second,
+		MaxLockout:   15 * time.Minute,
+		ResetAfter:   time.Hour,
+	}
+
+	// DefaultUsernamePasswordResetAttemptPolicy limits the reset links sent to a
+	// user, so the mailbox can't be flooded.
+	DefaultUsernamePasswordResetAttemptPolicy = LoginAttemptPolicy{
+		FreeAttempts: 2,
+		BaseLockout:  5 * time.Minute,
+		MaxLockout:   time.Hour,
+		ResetAfter:   time.Hour,
+	}
+
+	// DefaultIpPasswordResetAttemptPolicy limits how many usernames an ip can probe.
+	DefaultIpPasswordResetAttemptPolicy = LoginAttemptPolicy{
+		FreeAttempts: 10,
+		BaseLockout:  time.Minute,
+		MaxLockout:   time.Hour,
+		ResetAfter:   time.Hour,
+	}
+)
+
+// loginAttemptSweepInterval is how often entries without failures in the reset
+// period are removed, entries which are looked up are reset on their own.
+const loginAttemptSweepInterval = time.Minute
+
+type loginAttemptEntry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+	locked      bool
+}
+
+// InMemoryLoginAttemptStore removes entries without failures in the reset period
+// when an attempt is counted, at most once per sweep interval so the attempts don't
+// have to scan all entries. When it is full the oldest entry which isn't locked
+// out is evicted for a new key.
+type InMemoryLoginAttemptStore struct {
+	mutex     sync.Mutex
+	entries   map[string]*loginAttemptEntry
+	policy    LoginAttemptPolicy
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewInMemoryLoginAttemptStore(policy LoginAttemptPolicy) LoginAttemptStore {
+	if policy.MaxEntries <= 0 {
+		policy.MaxEntries = DefaultMaxLoginAttemptEntries
+	}
+
+	return &InMemoryLoginAttemptStore{
+		entries: map[string]*loginAttemptEntry{},
+		policy:  policy,
+		now:     time.Now,
+	}
+}
+
+func (s *InMemoryLoginAttemptStore) LockedOutFor(key string) time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return 0
+	}
+
+	return s.lockedOutFor(key, entry, s.now())
+}
+
+// TryAttempt lets the attempt which exceeds the free attempts through and locks the
+// key out for the ones after it, like a failure recorded after the attempt would.
+func (s *InMemoryLoginAttemptStore) TryAttempt(key string) time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	if !now.Before(s.lastSweep.Add(loginAttemptSweepInterval)) {
+		s.removeExpired(now)
+		s.lastSweep = now
+	}
+
+	entry, ok := s.entries[key]
+	if !ok && len(s.entries) >= s.policy.MaxEntries {
+		s.evictOldest(now)
+	}
+
+	if !ok || s.expired(entry, now) {
+		entry = &loginAttemptEntry{}
+		s.entries[key] = entry
+	}
+
+	if lockout := s.lockedOutFor(key, entry, now); lockout > 0 {
+		return lockout
+	}
+
+	entry.failures++
+	entry.lastFailure = now
+
+	if entry.failures <= s.policy.FreeAttempts {
+		return 0
+	}
+
+	lockout := s.lockout(entry.failures - s.policy.FreeAttempts)
+	entry.lockedUntil = now.Add(lockout)
+	entry.locked = true
+	log.Printf("locking out %s for %s after %d failed logins", key, lockout, entry.failures)
+
+	return 0
+}
+
+// Forgive lifts a lockout once the remaining failures are within the free attempts,
+// the entry is removed once no failure is left.
+func (s *InMemoryLoginAttemptStore) Forgive(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+
+	if entry.failures <= 1 {
+		delete(s.entries, key)
+		return
+	}
+
+	entry.failures--
+	if entry.failures <= s.policy.FreeAttempts {
+		entry.lockedUntil = time.Time{}
+		entry.locked = false
+	}
+}
+
+func (s *InMemoryLoginAttemptStore) Reset(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.entries, key)
+}
+
+// lockedOutFor logs the unlock the first time an expired lockout is noticed.
+func (s *InMemoryLoginAttemptStore) lockedOutFor(key string, entry *loginAttemptEntry, now time.Time) time.Duration {
+	if now.Before(entry.lockedUntil) {
+		return entry.lockedUntil.Sub(now)
+	}
+
+	if entry.locked {
+		entry.locked = false
+		log.Printf("lockout of %s expired", key)
+	}
+
+	return 0
+}
+
+// lockout doubles the base lockout for every failure past the free attempts.
+func (s *InMemoryLoginAttemptStore) lockout(excess int) time.Duration {
+	lockout := s.policy.BaseLockout
+	for i := 1; i < excess && lockout < s.policy.MaxLockout; i++ {
+		lockout *= 2
+	}
+
+	if lockout > s.policy.MaxLockout {
+		return s.policy.MaxLockout
+	}
+
+	return lockout
+}
+
+// expired is true once there was no failure for the reset period and the lockout is over.
+func (s *InMemoryLoginAttemptStore) expired(entry *loginAttemptEntry, now time.Time) bool {
+	return !now.Before(entry.lastFailure.Add(s.policy.ResetAfter)) && !now.Before(entry.lockedUntil)
+}
+
+func (s *InMemoryLoginAttemptStore) removeExpired(now time.Time) {
+	for key, entry := range s.entries {
+		if s.expired(entry, now) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// evictOldest removes the entry with the oldest failure which isn't locked out, or
+// the one whose lockout ends first when all of them are.
+func (s *InMemoryLoginAttemptStore) evictOldest(now time.Time) {
+	var oldestKey, firstUnlockKey string
+	var oldest, firstUnlock *loginAttemptEntry
+
+	for key, entry := range s.entries {
+		if now.Before(entry.lockedUntil) {
+			if firstUnlock == nil || entry.lockedUntil.Before(firstUnlock.lockedUntil) {
+				firstUnlockKey, firstUnlock = key, entry
+			}
+		} else if oldest == nil || entry.lastFailure.Before(oldest.lastFailure) {
+			oldestKey, oldest = key, entry
+		}
+	}
+
+	if oldest != nil {
+		delete(s.entries, oldestKey)
+	} else if firstUnlock != nil {
+		delete(s.entries, firstUnlockKey)
+	}
+}
@@ -0,0 +1,267 @@
+package lib
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testLoginAttemptPolicy = LoginAttemptPolicy{
+	FreeAttempts: 2,
+	BaseLockout:  time.Minute,
+	MaxLockout:   5 * time.Minute,
+	ResetAfter:   time.Hour,
+}
+
+func newTestLoginAttemptStore(now *time.Time) LoginAttemptStore {
+	sut := NewInMemoryLoginAttemptStore(testLoginAttemptPolicy)
+	sut.(*InMemoryLoginAttemptStore).now = func() time.Time {
+		return *now
+	}
+
+	return sut
+}
+
+func Test_InMemoryLoginAttemptStore_TryAttempt_allows_free_attempts(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestLoginAttemptStore(&now)
+
+	// Act
+	first := sut.TryAttempt("some-user")
+	second := sut.TryAttempt("some-user")
+
+	// Assert
+	assert.Equal(t, time.Duration(0), first)
+	assert.Equal(t, time.Duration(0), second)
+	assert.Equal(t, time.Duration(0), sut.LockedOutFor("some-user"))
+}
+
+func Test_InMemoryLoginAttemptStore_TryAttempt_rejects_attempts_after_lockout(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestLoginAttemptStore(&now)
+	for i := 0; i < 3; i++ {
+		require.Equal(t, time.Duration(0), sut.TryAttempt("some-user"))
+	}
+
+	// Act
+	rejected := sut.TryAttempt("some-user")
+
+	// Assert
+	assert.Equal(t, time.Minute, rejected)
+	assert.Equal(t, 3, sut.(*InMemoryLoginAttemptStore).entries["some-user"].failures)
+}
+
+func Test_InMemoryLoginAttemptStore_TryAttempt_doubles_lockout_up_to_max(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestLoginAttemptStore(&now)
+	sut.TryAttempt("some-user")
+	sut.TryAttempt("some-user")
+
+	// Act
+	lockouts := []time.Duration{}
+	for i := 0; i < 5; i++ {
+		sut.TryAttempt("some-user")
+		lockouts = append(lockouts, sut.LockedOutFor("some-user"))
+		now = now.Add(10 * time.Minute)
+	}
+
+	// Assert
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}, lockouts)
+}
+
+func Test_InMemoryLoginAttemptStore_TryAttempt_caps_concurrent_attempts(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestLoginAttemptStore(&now)
+
+	// Act
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	allowed := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if sut.TryAttempt("some-user") == 0 {
+				mutex.Lock()
+				allowed++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, testLoginAttemptPolicy.FreeAttempts+1, allowed)
+}
+
+func Test_InMemoryLoginAttemptStore_LockedOutFor_returns_remaining_lockout(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestLoginAttemptStore(&now)
+	for i := 0; i < 3; i++ {
+		sut.TryAttempt("some-user")
+	}
+
+	// Act
+	now = now.Add(20 * time.Second)
+	remaining := sut.LockedOutFor("some-user")
+	other := sut.LockedOutFor("other-user")
+
+	now = now.Add(time.Minute)
+	expired := sut.LockedOutFor("some-user")
+
+	// Assert
+	assert.Equal(t, 40*time.Second, remaining)
+	assert.Equal(t, time.Duration(0), other)
+	assert.Equal(t, time.Duration(0), expired)
+}
+
+func Test_InMemoryLoginAttemptStore_Forgive_takes_back_attempt(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestLoginAttemptStore(&now)
+	for i := 0; i < 3; i++ {
+		sut.TryAttempt("some-user")
+	}
+
+	// Act
+	sut.Forgive("some-user")
+	lockout := sut.LockedOutFor("some-user")
+
+	// Assert
+	assert.Equal(t, time.Duration(0), lockout)
+	assert.Equal(t, 2, sut.(*InMemoryLoginAttemptStore).entries["some-user"].failures)
+}
+
+func Test_InMemoryLoginAttemptStore_Forgive_removes_entry_without_failures(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestLoginAttemptStore(&now)
+	sut.TryAttempt("some-user")
+
+	// Act
+	sut.Forgive("some-user")
+
+	// Assert
+	assert.Empty(t, sut.(*InMemoryLoginAttemptStore).entries)
+}
+
+func Test_InMemoryLoginAttemptStore_Reset_forgets_failures(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestLoginAttemptStore(&now)
+	for i := 0; i < 3; i++ {
+		sut.TryAttempt("some-user")
+	}
+
+	// Act
+	sut.Reset("some-user")
+	lockout := sut.TryAttempt("some-user")
+
+	// Assert
+	assert.Equal(t, time.Duration(0), lockout)
+}
+
+func Test_InMemoryLoginAttemptStore_TryAttempt_forgets_failures_after_reset_period(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestLoginAttemptStore(&now)
+	sut.TryAttempt("some-user")
+	sut.TryAttempt("some-user")
+
+	// Act
+	now = now.Add(time.Hour)
+	sut.TryAttempt("some-user")
+
+	// Assert
+	assert.Equal(t, time.Duration(0), sut.LockedOutFor("some-user"))
+	assert.Len(t, sut.(*InMemoryLoginAttemptStore).entries, 1)
+}
+
+func Test_InMemoryLoginAttemptStore_TryAttempt_removes_expired_entries_once_per_sweep_interval(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := NewInMemoryLoginAttemptStore(LoginAttemptPolicy{FreeAttempts: 2, BaseLockout: time.Minute, MaxLockout: time.Hour, ResetAfter: 10 * time.Second})
+	sut.(*InMemoryLoginAttemptStore).now = func() time.Time {
+		return now
+	}
+
+	sut.TryAttempt("some-user")
+	sut.TryAttempt("some-user")
+
+	// Act
+	now = now.Add(20 * time.Second)
+	sut.TryAttempt("other-user")
+	sut.TryAttempt("some-user")
+	entriesBeforeSweep := len(sut.(*InMemoryLoginAttemptStore).entries)
+	failuresOfExpiredEntry := sut.(*InMemoryLoginAttemptStore).entries["some-user"].failures
+
+	now = now.Add(loginAttemptSweepInterval)
+	sut.TryAttempt("third-user")
+
+	// Assert
+	assert.Equal(t, 2, entriesBeforeSweep)
+	assert.Equal(t, 1, failuresOfExpiredEntry)
+	assert.Len(t, sut.(*InMemoryLoginAttemptStore).entries, 1)
+	assert.Contains(t, sut.(*InMemoryLoginAttemptStore).entries, "third-user")
+}
+
+func Test_InMemoryLoginAttemptStore_TryAttempt_evicts_oldest_entry_which_is_not_locked_out(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := NewInMemoryLoginAttemptStore(LoginAttemptPolicy{FreeAttempts: 1, BaseLockout: time.Minute, MaxLockout: time.Hour, ResetAfter: time.Hour, MaxEntries: 3})
+	sut.(*InMemoryLoginAttemptStore).now = func() time.Time {
+		return now
+	}
+
+	// locked-user is the oldest but locked out, so old-user goes first
+	sut.TryAttempt("locked-user")
+	sut.TryAttempt("locked-user")
+	now = now.Add(time.Second)
+	sut.TryAttempt("old-user")
+	now = now.Add(time.Second)
+	sut.TryAttempt("new-user")
+
+	// Act
+	now = now.Add(time.Second)
+	sut.TryAttempt("some-user")
+	now = now.Add(time.Second)
+	sut.TryAttempt("other-user")
+
+	// Assert
+	entries := sut.(*InMemoryLoginAttemptStore).entries
+	assert.Len(t, entries, 3)
+	assert.Contains(t, entries, "locked-user")
+	assert.Contains(t, entries, "some-user")
+	assert.Contains(t, entries, "other-user")
+	assert.Greater(t, sut.LockedOutFor("locked-user"), time.Duration(0))
+}
+
+func Test_InMemoryLoginAttemptStore_TryAttempt_evicts_first_unlocked_entry_when_all_are_locked_out(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := NewInMemoryLoginAttemptStore(LoginAttemptPolicy{FreeAttempts: 0, BaseLockout: time.Minute, MaxLockout: time.Hour, ResetAfter: time.Hour, MaxEntries: 2})
+	sut.(*InMemoryLoginAttemptStore).now = func() time.Time {
+		return now
+	}
+
+	sut.TryAttempt("some-user")
+	now = now.Add(time.Second)
+	sut.TryAttempt("other-user")
+
+	// Act
+	now = now.Add(time.Second)
+	sut.TryAttempt("third-user")
+
+	// Assert
+	entries := sut.(*InMemoryLoginAttemptStore).entries
+	assert.Len(t, entries, 2)
+	assert.NotContains(t, entries, "some-user")
+}
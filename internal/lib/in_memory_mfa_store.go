@@ -0,0 +1,144 @@
+package lib
+
+import (
+	"crypto/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recoveryCodeCount is how many recovery codes an enrollment has.
+const recoveryCodeCount = 10
+
+type mfaEntry struct {
+	secret        string
+	recoveryCodes map[string]bool
+	lastStep      int64
+}
+
+// InMemoryMfaStore only keeps hashes of the recovery codes. Re-enrolling replaces
+// the pending enrollment, the active one stays in place until the new one is
+// confirmed.
+type InMemoryMfaStore struct {
+	mutex   sync.Mutex
+	issuer  string
+	active  map[string]*mfaEntry
+	pending map[string]*mfaEntry
+	now     func() time.Time
+}
+
+// NewInMemoryMfaStore uses issuer as the name of the issuer in authenticator apps.
+func NewInMemoryMfaStore(issuer string) MfaStore {
+	return &InMemoryMfaStore{
+		issuer:  issuer,
+		active:  map[string]*mfaEntry{},
+		pending: map[string]*mfaEntry{},
+		now:     time.Now,
+	}
+}
+
+func (s *InMemoryMfaStore) Enroll(subject string, account string) (*MfaEnrollment, error) {
+	secret, err := GenerateTotpSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &mfaEntry{
+		secret:        secret,
+		recoveryCodes: map[string]bool{},
+	}
+
+	recoveryCodes := []string{}
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		recoveryCodes = append(recoveryCodes, code)
+		entry.recoveryCodes[hashToken(normalizeRecoveryCode(code))] = true
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending[subject] = entry
+
+	return &MfaEnrollment{
+		Secret:        secret,
+		Uri:           TotpUri(s.issuer, account, secret),
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (s *InMemoryMfaStore) Confirm(subject string, code string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.pending[subject]
+	if !ok {
+		return ErrMfaStoreNoEnrollment
+	}
+
+	step, ok := VerifyTotp(entry.secret, code, s.now())
+	if !ok {
+		return ErrMfaStoreInvalidCode
+	}
+
+	entry.lastStep = step
+	s.active[subject] = entry
+	delete(s.pending, subject)
+
+	return nil
+}
+
+func (s *InMemoryMfaStore) IsEnrolled(subject string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, ok := s.active[subject]
+	return ok
+}
+
+func (s *InMemoryMfaStore) Verify(subject string, code string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.active[subject]
+	if !ok {
+		return ErrMfaStoreNotEnrolled
+	}
+
+	// a code is only accepted once, so an observed code can't be replayed
+	if step, ok := VerifyTotp(entry.secret, code, s.now()); ok {
+		if step <= entry.lastStep {
+			return ErrMfaStoreInvalidCode
+		}
+
+		entry.lastStep = step
+		return nil
+	}
+
+	hash := hashToken(normalizeRecoveryCode(code))
+	if entry.recoveryCodes[hash] {
+		delete(entry.recoveryCodes, hash)
+		return nil
+	}
+
+	return ErrMfaStoreInvalidCode
+}
+
+// newRecoveryCode returns 50 random bits as two groups of five base32 characters.
+func newRecoveryCode() (string, error) {
+	value := make([]byte, 10)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(totpEncoding.EncodeToString(value))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
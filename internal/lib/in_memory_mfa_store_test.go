@@ -0,0 +1,143 @@
+package lib
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTotpCode(t *testing.T, secret string, now time.Time) string {
+	key, err := totpEncoding.DecodeString(secret)
+	require.Nil(t, err)
+	return totpCode(key, now.Unix()/totpPeriod)
+}
+
+// enrollTestUser returns an active enrollment, confirmed with the code of the
+// previous period so the current code is still unused.
+func enrollTestUser(t *testing.T, sut MfaStore, now time.Time) *MfaEnrollment {
+	enrollment, err := sut.Enroll("some-user", "some-username")
+	require.Nil(t, err)
+	require.Nil(t, sut.Confirm("some-user", newTestTotpCode(t, enrollment.Secret, now.Add(-totpPeriod*time.Second))))
+	return enrollment
+}
+
+func newTestMfaStore(now time.Time) MfaStore {
+	sut := NewInMemoryMfaStore("some-issuer")
+	sut.(*InMemoryMfaStore).now = func() time.Time {
+		return now
+	}
+
+	return sut
+}
+
+func Test_InMemoryMfaStore_Enroll_returns_secret_uri_and_recovery_codes(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryMfaStore("some-issuer")
+
+	// Act
+	enrollment, err := sut.Enroll("some-user", "some-username")
+
+	// Assert
+	require.Nil(t, err)
+	assert.NotEmpty(t, enrollment.Secret)
+	assert.True(t, strings.HasPrefix(enrollment.Uri, "otpauth://totp/some-issuer:some-username?"))
+	assert.Len(t, enrollment.RecoveryCodes, 10)
+	assert.False(t, sut.IsEnrolled("some-user"))
+}
+
+func Test_InMemoryMfaStore_Confirm_activates_enrollment(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestMfaStore(now)
+	enrollment, err := sut.Enroll("some-user", "some-username")
+	require.Nil(t, err)
+
+	code := newTestTotpCode(t, enrollment.Secret, now)
+	wrong := code[:5] + string('0'+(code[5]-'0'+1)%10)
+
+	// Act
+	wrongErr := sut.Confirm("some-user", wrong)
+	err = sut.Confirm("some-user", code)
+
+	// Assert
+	assert.True(t, errors.Is(wrongErr, ErrMfaStoreInvalidCode))
+	assert.Nil(t, err)
+	assert.True(t, sut.IsEnrolled("some-user"))
+}
+
+func Test_InMemoryMfaStore_Confirm_returns_error_without_enrollment(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryMfaStore("some-issuer")
+
+	// Act
+	err := sut.Confirm("some-user", "123456")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrMfaStoreNoEnrollment))
+}
+
+func Test_InMemoryMfaStore_Verify_accepts_a_totp_code_once(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestMfaStore(now)
+	enrollment := enrollTestUser(t, sut, now)
+	code := newTestTotpCode(t, enrollment.Secret, now)
+
+	// Act
+	err := sut.Verify("some-user", code)
+	replayErr := sut.Verify("some-user", code)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(replayErr, ErrMfaStoreInvalidCode))
+}
+
+func Test_InMemoryMfaStore_Verify_accepts_a_recovery_code_once(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestMfaStore(now)
+	enrollment := enrollTestUser(t, sut, now)
+	code := enrollment.RecoveryCodes[0]
+
+	// Act
+	err := sut.Verify("some-user", strings.ToUpper(strings.Replace(code, "-", " ", 1)))
+	reuseErr := sut.Verify("some-user", code)
+	otherErr := sut.Verify("some-user", enrollment.RecoveryCodes[1])
+
+	// Assert
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(reuseErr, ErrMfaStoreInvalidCode))
+	assert.Nil(t, otherErr)
+}
+
+func Test_InMemoryMfaStore_Verify_returns_error_when_not_enrolled(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryMfaStore("some-issuer")
+	_, err := sut.Enroll("some-user", "some-username")
+	require.Nil(t, err)
+
+	// Act
+	err = sut.Verify("some-user", "123456")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrMfaStoreNotEnrolled))
+}
+
+func Test_InMemoryMfaStore_Enroll_keeps_active_enrollment_until_confirmed(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := newTestMfaStore(now)
+	enrollment := enrollTestUser(t, sut, now)
+
+	// Act
+	_, err := sut.Enroll("some-user", "some-username")
+
+	// Assert
+	require.Nil(t, err)
+	assert.True(t, sut.IsEnrolled("some-user"))
+	assert.Nil(t, sut.Verify("some-user", newTestTotpCode(t, enrollment.Secret, now)))
+}
@@ -0,0 +1,99 @@
+package lib
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultPasswordResetLifetime is how long the link sent to a user can be used.
+const DefaultPasswordResetLifetime = 30 * time.Minute
+
+type passwordResetEntry struct {
+	subject   string
+	expiresAt time.Time
+}
+
+// InMemoryPasswordResetStore only keeps a hash of the tokens, expired tokens are
+// removed when new tokens are issued.
+type InMemoryPasswordResetStore struct {
+	mutex    sync.Mutex
+	tokens   map[string]*passwordResetEntry
+	subjects map[string]string
+	lifetime time.Duration
+	now      func() time.Time
+}
+
+func NewInMemoryPasswordResetStore(lifetime time.Duration) PasswordResetStore {
+	return &InMemoryPasswordResetStore{
+		tokens:   map[string]*passwordResetEntry{},
+		subjects: map[string]string{},
+		lifetime: lifetime,
+		now:      time.Now,
+	}
+}
+
+func (s *InMemoryPasswordResetStore) Issue(subject string) (string, error) {
+	token, err := NewRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.removeExpired()
+	delete(s.tokens, s.subjects[subject])
+
+	hash := hashToken(token)
+	s.tokens[hash] = &passwordResetEntry{
+		subject:   subject,
+		expiresAt: s.now().Add(s.lifetime),
+	}
+	s.subjects[subject] = hash
+
+	return token, nil
+}
+
+func (s *InMemoryPasswordResetStore) Lookup(token string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.tokens[hashToken(token)]
+	if !ok || !s.now().Before(entry.expiresAt) {
+		return "", ErrPasswordResetStoreInvalidToken
+	}
+
+	return entry.subject, nil
+}
+
+// Redeem removes the token, so it can only be used once.
+func (s *InMemoryPasswordResetStore) Redeem(token string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hash := hashToken(token)
+	entry, ok := s.tokens[hash]
+	if !ok {
+		return "", ErrPasswordResetStoreInvalidToken
+	}
+
+	delete(s.tokens, hash)
+	delete(s.subjects, entry.subject)
+
+	if !s.now().Before(entry.expiresAt) {
+		return "", ErrPasswordResetStoreInvalidToken
+	}
+
+	return entry.subject, nil
+}
+
+func (s *InMemoryPasswordResetStore) removeExpired() {
+	now := s.now()
+
+	for hash, entry := range s.tokens {
+		if !now.Before(entry.expiresAt) {
+			delete(s.tokens, hash)
+			delete(s.subjects, entry.subject)
+		}
+	}
+}
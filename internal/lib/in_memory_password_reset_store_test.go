@@ -0,0 +1,82 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InMemoryPasswordResetStore_Redeem_returns_subject_once(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryPasswordResetStore(time.Minute)
+	token, err := sut.Issue("some-user")
+	require.Nil(t, err)
+
+	// Act
+	lookedUp, lookupErr := sut.Lookup(token)
+	subject, err := sut.Redeem(token)
+	_, reusedErr := sut.Redeem(token)
+
+	// Assert
+	assert.Nil(t, lookupErr)
+	assert.Equal(t, "some-user", lookedUp)
+	assert.Nil(t, err)
+	assert.Equal(t, "some-user", subject)
+	assert.Equal(t, ErrPasswordResetStoreInvalidToken, reusedErr)
+}
+
+func Test_InMemoryPasswordResetStore_Redeem_returns_error_on_unknown_token(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryPasswordResetStore(time.Minute)
+
+	// Act
+	_, lookupErr := sut.Lookup("some-token")
+	_, redeemErr := sut.Redeem("some-token")
+
+	// Assert
+	assert.Equal(t, ErrPasswordResetStoreInvalidToken, lookupErr)
+	assert.Equal(t, ErrPasswordResetStoreInvalidToken, redeemErr)
+}
+
+func Test_InMemoryPasswordResetStore_Redeem_returns_error_on_expired_token(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryPasswordResetStore(time.Minute)
+	token, err := sut.Issue("some-user")
+	require.Nil(t, err)
+
+	now := time.Now()
+	sut.(*InMemoryPasswordResetStore).now = func() time.Time {
+		return now.Add(time.Minute)
+	}
+
+	// Act
+	_, lookupErr := sut.Lookup(token)
+	_, redeemErr := sut.Redeem(token)
+
+	// Assert
+	assert.Equal(t, ErrPasswordResetStoreInvalidToken, lookupErr)
+	assert.Equal(t, ErrPasswordResetStoreInvalidToken, redeemErr)
+}
+
+func Test_InMemoryPasswordResetStore_Issue_replaces_earlier_token_of_subject(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryPasswordResetStore(time.Minute)
+	first, err := sut.Issue("some-user")
+	require.Nil(t, err)
+	other, err := sut.Issue("other-user")
+	require.Nil(t, err)
+
+	// Act
+	second, err := sut.Issue("some-user")
+
+	// Assert
+	require.Nil(t, err)
+	_, firstErr := sut.Redeem(first)
+	_, secondErr := sut.Redeem(second)
+	_, otherErr := sut.Redeem(other)
+	assert.Equal(t, ErrPasswordResetStoreInvalidToken, firstErr)
+	assert.Nil(t, secondErr)
+	assert.Nil(t, otherErr)
+}
@@ -0,0 +1,189 @@
+package lib
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshTokenLifetime is how long a refresh token can be used, every
+// rotation starts a new lifetime.
+const DefaultRefreshTokenLifetime = 24 * time.Hour
+
+type refreshTokenEntry struct {
+	family    string
+	grant     RefreshGrant
+	expiresAt time.Time
+	used      bool
+}
+
+// InMemoryRefreshTokenStore only keeps a hash of the tokens, expired tokens are
+// removed when new tokens are issued.
+type InMemoryRefreshTokenStore struct {
+	mutex    sync.Mutex
+	tokens   map[string]*refreshTokenEntry
+	revoked  map[string]time.Time
+	lifetime time.Duration
+	now      func() time.Time
+}
+
+func NewInMemoryRefreshTokenStore(lifetime time.Duration) RefreshTokenStore {
+	return &InMemoryRefreshTokenStore{
+		tokens:   map[string]*refreshTokenEntry{},
+		revoked:  map[string]time.Time{},
+		lifetime: lifetime,
+		now:      time.Now,
+	}
+}
+
+func (s *InMemoryRefreshTokenStore) Issue(grant RefreshGrant) (string, error) {
+	family, err := NewRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.removeExpired()
+	return s.issue(family, grant)
+}
+
+func (s *InMemoryRefreshTokenStore) Rotate(token string) (*RefreshGrant, string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.tokens[hashToken(token)]
+	if !ok || !s.now().Before(entry.expiresAt) {
+		return nil, "", ErrRefreshTokenStoreInvalidToken
+	}
+
+	if _, revoked := s.revoked[entry.family]; revoked {
+		return nil, "", ErrRefreshTokenStoreInvalidToken
+	}
+
+	if entry.used {
+		log.Printf("refresh token reused for %s, revoking token family", entry.grant.Subject)
+		s.revokeFamily(entry.family)
+		return nil, "", ErrRefreshTokenStoreReusedToken
+	}
+
+	entry.used = true
+	next, err := s.issue(entry.family, entry.grant)
+	if err != nil {
+		return nil, "", err
+	}
+
+	grant := entry.grant
+	return &grant, next, nil
+}
+
+// Grant also returns the grant of used tokens, they still belong to their family.
+func (s *InMemoryRefreshTokenStore) Grant(token string) (*RefreshGrant, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.tokens[hashToken(token)]
+	if !ok {
+		return nil, ErrRefreshTokenStoreInvalidToken
+	}
+
+	grant := entry.grant
+	return &grant, nil
+}
+
+// Revoke revokes the family of the token, unknown tokens are ignored.
+func (s *InMemoryRefreshTokenStore) Revoke(token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.tokens[hashToken(token)]
+	if !ok {
+		return ErrRefreshTokenStoreInvalidToken
+	}
+
+	s.revokeFamily(entry.family)
+	return nil
+}
+
+func (s *InMemoryRefreshTokenStore) RevokeSubject(subject string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, entry := range s.tokens {
+		if entry.grant.Subject == subject {
+			s.revokeFamily(entry.family)
+		}
+	}
+
+	return nil
+}
+
+func (s *InMemoryRefreshTokenStore) issue(family string, grant RefreshGrant) (string, error) {
+	token, err := NewRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.tokens[hashToken(token)] = &refreshTokenEntry{
+		family:    family,
+		grant:     grant,
+		expiresAt: s.now().Add(s.lifetime),
+	}
+
+	return token, nil
+}
+
+// revokeFamily remembers the family until its last token has expired.
+func (s *InMemoryRefreshTokenStore) revokeFamily(family string) {
+	for _, entry := range s.tokens {
+		if entry.family == family && entry.expiresAt.After(s.revoked[family]) {
+			s.revoked[family] = entry.expiresAt
+		}
+	}
+}
+
+func (s *InMemoryRefreshTokenStore) removeExpired() {
+	now := s.now()
+
+	for hash, entry := range s.tokens {
+		if !now.Before(entry.expiresAt) {
+			delete(s.tokens, hash)
+		}
+	}
+
+	for family, expiresAt := range s.revoked {
+		if !now.Before(expiresAt) {
+			delete(s.revoked, family)
+		}
+	}
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return string(hash[:])
+}
+
+// NewRandomToken returns 256 random bits, base64url encoded, for values which grant
+// something on their own, like tokens, codes and csrf tokens.
+func NewRandomToken() (string, error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+
+	return encodeBase64Url(value), nil
+}
+
+// newRandomId returns 96 random bits, base64url encoded, for ids which are shown
+// but don't grant anything on their own, like the ids of users and api keys.
+func newRandomId() (string, error) {
+	value := make([]byte, 12)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+
+	return encodeBase64Url(value), nil
+}
@@ -0,0 +1,205 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InMemoryRefreshTokenStore_Rotate_returns_grant_and_new_token(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRefreshTokenStore(time.Hour)
+	token, err := sut.Issue(RefreshGrant{Subject: "some-user"})
+	require.Nil(t, err)
+
+	// Act
+	grant, next, err := sut.Rotate(token)
+
+	// Assert
+	require.Nil(t, err)
+	require.NotNil(t, grant)
+	assert.Equal(t, "some-user", grant.Subject)
+	assert.NotEmpty(t, next)
+	assert.NotEqual(t, token, next)
+}
+
+func Test_InMemoryRefreshTokenStore_Rotate_returns_error_on_unknown_token(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRefreshTokenStore(time.Hour)
+
+	// Act
+	grant, next, err := sut.Rotate("some-token")
+
+	// Assert
+	assert.Nil(t, grant)
+	assert.Empty(t, next)
+	assert.True(t, errors.Is(err, ErrRefreshTokenStoreInvalidToken))
+}
+
+func Test_InMemoryRefreshTokenStore_Rotate_returns_error_on_expired_token(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRefreshTokenStore(time.Hour)
+	now := time.Now()
+	sut.(*InMemoryRefreshTokenStore).now = func() time.Time {
+		return now
+	}
+
+	token, err := sut.Issue(RefreshGrant{Subject: "some-user"})
+	require.Nil(t, err)
+
+	sut.(*InMemoryRefreshTokenStore).now = func() time.Time {
+		return now.Add(time.Hour)
+	}
+
+	// Act
+	grant, _, err := sut.Rotate(token)
+
+	// Assert
+	assert.Nil(t, grant)
+	assert.True(t, errors.Is(err, ErrRefreshTokenStoreInvalidToken))
+}
+
+func Test_InMemoryRefreshTokenStore_Rotate_revokes_family_on_reuse(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRefreshTokenStore(time.Hour)
+	first, err := sut.Issue(RefreshGrant{Subject: "some-user"})
+	require.Nil(t, err)
+
+	_, second, err := sut.Rotate(first)
+	require.Nil(t, err)
+
+	// Act
+	_, _, reuse_err := sut.Rotate(first)
+	_, _, second_err := sut.Rotate(second)
+
+	// Assert
+	assert.True(t, errors.Is(reuse_err, ErrRefreshTokenStoreReusedToken))
+	assert.True(t, errors.Is(second_err, ErrRefreshTokenStoreInvalidToken))
+}
+
+func Test_InMemoryRefreshTokenStore_Rotate_does_not_revoke_other_families(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRefreshTokenStore(time.Hour)
+	first, err := sut.Issue(RefreshGrant{Subject: "some-user"})
+	require.Nil(t, err)
+	other, err := sut.Issue(RefreshGrant{Subject: "some-user"})
+	require.Nil(t, err)
+
+	_, _, err = sut.Rotate(first)
+	require.Nil(t, err)
+	_, _, err = sut.Rotate(first)
+	require.NotNil(t, err)
+
+	// Act
+	grant, _, err := sut.Rotate(other)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, grant)
+}
+
+func Test_InMemoryRefreshTokenStore_Issue_removes_expired_tokens(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRefreshTokenStore(time.Hour)
+	now := time.Now()
+	sut.(*InMemoryRefreshTokenStore).now = func() time.Time {
+		return now
+	}
+
+	_, err := sut.Issue(RefreshGrant{Subject: "some-user"})
+	require.Nil(t, err)
+
+	sut.(*InMemoryRefreshTokenStore).now = func() time.Time {
+		return now.Add(time.Hour)
+	}
+
+	// Act
+	_, err = sut.Issue(RefreshGrant{Subject: "some-user"})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Len(t, sut.(*InMemoryRefreshTokenStore).tokens, 1)
+}
+
+func Test_InMemoryRefreshTokenStore_Grant_returns_grant_without_using_token(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRefreshTokenStore(time.Hour)
+	token, err := sut.Issue(RefreshGrant{Subject: "some-user", ClientId: "some-client"})
+	require.Nil(t, err)
+
+	// Act
+	grant, err := sut.Grant(token)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, &RefreshGrant{Subject: "some-user", ClientId: "some-client"}, grant)
+	_, _, err = sut.Rotate(token)
+	assert.Nil(t, err)
+}
+
+func Test_InMemoryRefreshTokenStore_Grant_returns_error_on_unknown_token(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRefreshTokenStore(time.Hour)
+
+	// Act
+	grant, err := sut.Grant("some-token")
+
+	// Assert
+	assert.Nil(t, grant)
+	assert.True(t, errors.Is(err, ErrRefreshTokenStoreInvalidToken))
+}
+
+func Test_InMemoryRefreshTokenStore_Revoke_revokes_the_token_family(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRefreshTokenStore(time.Hour)
+	first, err := sut.Issue(RefreshGrant{Subject: "some-user"})
+	require.Nil(t, err)
+
+	_, second, err := sut.Rotate(first)
+	require.Nil(t, err)
+
+	// Act
+	err = sut.Revoke(first)
+
+	// Assert
+	assert.Nil(t, err)
+	_, _, err = sut.Rotate(second)
+	assert.True(t, errors.Is(err, ErrRefreshTokenStoreInvalidToken))
+}
+
+func Test_InMemoryRefreshTokenStore_Revoke_returns_error_on_unknown_token(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRefreshTokenStore(time.Hour)
+
+	// Act
+	err := sut.Revoke("some-token")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrRefreshTokenStoreInvalidToken))
+}
+
+func Test_InMemoryRefreshTokenStore_RevokeSubject_revokes_token_families_of_subject(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRefreshTokenStore(time.Hour)
+	first, err := sut.Issue(RefreshGrant{Subject: "some-user"})
+	require.Nil(t, err)
+	second, err := sut.Issue(RefreshGrant{Subject: "some-user"})
+	require.Nil(t, err)
+	other, err := sut.Issue(RefreshGrant{Subject: "other-user"})
+	require.Nil(t, err)
+
+	// Act
+	err = sut.RevokeSubject("some-user")
+
+	// Assert
+	assert.Nil(t, err)
+	_, _, firstErr := sut.Rotate(first)
+	_, _, secondErr := sut.Rotate(second)
+	_, _, otherErr := sut.Rotate(other)
+	assert.True(t, errors.Is(firstErr, ErrRefreshTokenStoreInvalidToken))
+	assert.True(t, errors.Is(secondErr, ErrRefreshTokenStoreInvalidToken))
+	assert.Nil(t, otherErr)
+}
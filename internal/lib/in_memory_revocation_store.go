@@ -0,0 +1,51 @@
+package lib
+
+import (
+	"sync"
+	"time"
+)
+
+// InMemoryRevocationStore removes entries once their token has expired and the
+// leeway has passed, expired entries are cleaned up whenever a token is revoked.
+// The leeway must be the one of the provider, which accepts expired tokens for as
+// long.
+type InMemoryRevocationStore struct {
+	mutex   sync.RWMutex
+	revoked map[string]time.Time
+	leeway  time.Duration
+	now     func() time.Time
+}
+
+func NewInMemoryRevocationStore(leeway time.Duration) RevocationStore {
+	return &InMemoryRevocationStore{
+		revoked: map[string]time.Time{},
+		leeway:  leeway,
+		now:     time.Now,
+	}
+}
+
+func (s *InMemoryRevocationStore) Revoke(jti string, expiresAt time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expiresAt = expiresAt.Add(s.leeway)
+
+	now := s.now()
+	for revoked, revokedExpiresAt := range s.revoked {
+		if !now.Before(revokedExpiresAt) {
+			delete(s.revoked, revoked)
+		}
+	}
+
+	if now.Before(expiresAt) {
+		s.revoked[jti] = expiresAt
+	}
+}
+
+func (s *InMemoryRevocationStore) IsRevoked(jti string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	expiresAt, ok := s.revoked[jti]
+	return ok && s.now().Before(expiresAt)
+}
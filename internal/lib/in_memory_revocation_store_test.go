@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InMemoryRevocationStore_IsRevoked_returns_true_for_revoked_jti(t *testing.T) {
+	// Arrange
+	sut := NewInMemoryRevocationStore(0)
+	sut.Revoke("some-jti", time.Now().Add(time.Hour))
+
+	// Act
+	revoked := sut.IsRevoked("some-jti")
+
+	// Assert
+	assert.True(t, revoked)
+	assert.False(t, sut.IsRevoked("another-jti"))
+}
+
+func Test_InMemoryRevocationStore_IsRevoked_returns_false_once_token_expired(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := NewInMemoryRevocationStore(0)
+	sut.Revoke("some-jti", now.Add(time.Hour))
+
+	sut.(*InMemoryRevocationStore).now = func() time.Time {
+		return now.Add(time.Hour)
+	}
+
+	// Act
+	revoked := sut.IsRevoked("some-jti")
+
+	// Assert
+	assert.False(t, revoked)
+}
+
+func Test_InMemoryRevocationStore_Revoke_removes_expired_entries(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := NewInMemoryRevocationStore(0)
+	sut.Revoke("some-jti", now.Add(time.Hour))
+
+	sut.(*InMemoryRevocationStore).now = func() time.Time {
+		return now.Add(2 * time.Hour)
+	}
+
+	// Act
+	sut.Revoke("another-jti", now.Add(3*time.Hour))
+	sut.Revoke("expired-jti", now)
+
+	// Assert
+	assert.Len(t, sut.(*InMemoryRevocationStore).revoked, 1)
+	assert.True(t, sut.IsRevoked("another-jti"))
+}
+
+func Test_InMemoryRevocationStore_IsRevoked_returns_true_within_leeway(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut := NewInMemoryRevocationStore(time.Minute)
+	sut.Revoke("some-jti", now.Add(time.Hour))
+
+	// Act
+	sut.(*InMemoryRevocationStore).now = func() time.Time {
+		return now.Add(time.Hour + 30*time.Second)
+	}
+	within := sut.IsRevoked("some-jti")
+
+	sut.(*InMemoryRevocationStore).now = func() time.Time {
+		return now.Add(time.Hour + time.Minute)
+	}
+	outside := sut.IsRevoked("some-jti")
+
+	// Assert
+	assert.True(t, within)
+	assert.False(t, outside)
+}
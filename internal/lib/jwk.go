@@ -0,0 +1,160 @@
+package lib
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	ErrJwkUnsupportedKeyType = errors.New("unsupported key type")
+	ErrJwkInvalidKey         = errors.New("invalid key")
+)
+
+// jwkCurves maps the crv of EC keys to their curve.
+var jwkCurves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// Jwk is the public part of a signing key as described in RFC 7517.
+type Jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC and OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JwkSet struct {
+	Keys []Jwk `json:"keys"`
+}
+
+// NewJwk creates the JWK for a public key, the kid is the RFC 7638 thumbprint of the key.
+func NewJwk(algorithm string, publicKey crypto.PublicKey) (Jwk, error) {
+	var jwk Jwk
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk = Jwk{
+			Kty: "RSA",
+			N:   encodeBase64Url(key.N.Bytes()),
+			E:   encodeBase64Url(big.NewInt(int64(key.E)).Bytes()),
+		}
+
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk = Jwk{
+			Kty: "EC",
+			Crv: key.Curve.Params().Name,
+			X:   encodeBase64Url(key.X.FillBytes(make([]byte, size))),
+			Y:   encodeBase64Url(key.Y.FillBytes(make([]byte, size))),
+		}
+
+	case ed25519.PublicKey:
+		jwk = Jwk{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   encodeBase64Url(key),
+		}
+
+	default:
+		return Jwk{}, fmt.Errorf("%w: %T", ErrJwkUnsupportedKeyType, publicKey)
+	}
+
+	jwk.Use = "sig"
+	jwk.Alg = algorithm
+	jwk.Kid = jwk.Thumbprint()
+
+	return jwk, nil
+}
+
+// Thumbprint calculates the RFC 7638 thumbprint, which only covers the required
+// members of the key in lexicographic order.
+func (k Jwk) Thumbprint() string {
+	var canonical string
+
+	switch k.Kty {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, k.E, k.N)
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, k.Crv, k.X, k.Y)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":"%s","kty":"OKP","x":"%s"}`, k.Crv, k.X)
+	}
+
+	hash := sha256.Sum256([]byte(canonical))
+	return encodeBase64Url(hash[:])
+}
+
+// PublicKey converts the JWK back to a public key, used for keys of other issuers.
+func (k Jwk) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil || len(n) == 0 {
+			return nil, fmt.Errorf("%w: modulus of %s", ErrJwkInvalidKey, k.Kid)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("%w: exponent of %s", ErrJwkInvalidKey, k.Kid)
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+
+	case "EC":
+		curve, ok := jwkCurves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("%w: curve %s", ErrJwkUnsupportedKeyType, k.Crv)
+		}
+
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("%w: coordinates of %s", ErrJwkInvalidKey, k.Kid)
+		}
+
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("%w: point of %s is not on the curve", ErrJwkInvalidKey, k.Kid)
+		}
+
+		return key, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("%w: curve %s", ErrJwkUnsupportedKeyType, k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: %s", ErrJwkInvalidKey, k.Kid)
+		}
+
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrJwkUnsupportedKeyType, k.Kty)
+	}
+}
+
+func encodeBase64Url(value []byte) string {
+	return base64.RawURLEncoding.EncodeToString(value)
+}
@@ -0,0 +1,120 @@
+package lib
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Jwk_Thumbprint_matches_rfc7638_example(t *testing.T) {
+	// Arrange
+	sut := Jwk{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+		Alg: "RS256",
+		Kid: "2011-04-29",
+	}
+
+	// Act
+	thumbprint := sut.Thumbprint()
+
+	// Assert
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+}
+
+func Test_NewJwk_creates_rsa_key(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "RS256").(*rsa.PrivateKey)
+
+	// Act
+	jwk, err := NewJwk("RS256", key.Public())
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "RSA", jwk.Kty)
+	assert.Equal(t, "sig", jwk.Use)
+	assert.Equal(t, "RS256", jwk.Alg)
+	assert.Equal(t, "AQAB", jwk.E)
+	assert.NotEmpty(t, jwk.N)
+	assert.Equal(t, jwk.Thumbprint(), jwk.Kid)
+}
+
+func Test_NewJwk_creates_ec_key_with_padded_coordinates(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "ES256").(*ecdsa.PrivateKey)
+
+	// Act
+	jwk, err := NewJwk("ES256", key.Public())
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "EC", jwk.Kty)
+	assert.Equal(t, "P-256", jwk.Crv)
+	assert.Len(t, jwk.X, 43)
+	assert.Len(t, jwk.Y, 43)
+	assert.Equal(t, jwk.Thumbprint(), jwk.Kid)
+}
+
+func Test_NewJwk_creates_okp_key(t *testing.T) {
+	// Arrange
+	key := generateTestKey(t, "EdDSA").(ed25519.PrivateKey)
+
+	// Act
+	jwk, err := NewJwk("EdDSA", key.Public())
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "OKP", jwk.Kty)
+	assert.Equal(t, "Ed25519", jwk.Crv)
+	assert.Equal(t, encodeBase64Url(key.Public().(ed25519.PublicKey)), jwk.X)
+	assert.Empty(t, jwk.Y)
+	assert.Equal(t, jwk.Thumbprint(), jwk.Kid)
+}
+
+func Test_NewJwk_returns_error_on_unsupported_key(t *testing.T) {
+	// Act
+	_, err := NewJwk("HS512", []byte("some-secret"))
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrJwkUnsupportedKeyType))
+}
+
+func Test_Jwk_PublicKey_returns_the_key_of_NewJwk(t *testing.T) {
+	for _, algorithm := range []string{"RS256", "ES256", "ES384", "EdDSA"} {
+		// Arrange
+		key := generateTestKey(t, algorithm)
+		sut, err := NewJwk(algorithm, key.Public())
+		require.Nil(t, err)
+
+		// Act
+		publicKey, err := sut.PublicKey()
+
+		// Assert
+		require.Nil(t, err, algorithm)
+		assert.True(t, key.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(publicKey), algorithm)
+	}
+}
+
+func Test_Jwk_PublicKey_returns_error_on_invalid_key(t *testing.T) {
+	for _, sut := range []Jwk{
+		{Kty: "oct"},
+		{Kty: "RSA", N: "", E: "AQAB"},
+		{Kty: "EC", Crv: "P-256", X: "AQAB", Y: "AQAB"},
+		{Kty: "EC", Crv: "secp256k1", X: "AQAB", Y: "AQAB"},
+		{Kty: "OKP", Crv: "Ed25519", X: "AQAB"},
+	} {
+		// Act
+		publicKey, err := sut.PublicKey()
+
+		// Assert
+		assert.Nil(t, publicKey)
+		assert.NotNil(t, err, sut.Kty)
+	}
+}
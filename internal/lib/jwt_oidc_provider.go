@@ -0,0 +1,341 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var (
+	ErrJwtOidcProviderValidationError   = errors.New("username cannot be empty")
+	ErrJwtOidcProviderInvalidToken      = errors.New("invalid token")
+	ErrJwtOidcProviderMissingSigningKey = errors.New("provider has no private key and can only validate tokens")
+	ErrJwtOidcProviderRevokedToken      = errors.New("token has been revoked")
+	ErrJwtOidcProviderMissingClientId   = errors.New("id token needs a client id as audience")
+	ErrJwtOidcProviderInvalidAudience   = errors.New("token is not meant for this audience")
+	ErrJwtOidcProviderExpiredToken      = errors.New("token is expired")
+	ErrJwtOidcProviderTokenNotValidYet  = errors.New("token is not valid yet")
+	ErrJwtOidcProviderMalformedToken    = errors.New("token is malformed")
+	ErrJwtOidcProviderUnknownIssuer     = errors.New("unknown issuer")
+)
+
+// DefaultTokenLifetime is how long issued tokens are valid, unless configured
+// otherwise.
+const DefaultTokenLifetime = time.Hour
+
+// JwtOidcProvider issues and validates tokens with the keys of a KeyRing. The kid
+// header of a token selects the key it is validated with.
+type JwtOidcProvider struct {
+	keys            *KeyRing
+	issuer          string
+	audience        string
+	lifetime        time.Duration
+	leeway          time.Duration
+	revocationStore RevocationStore
+	claimsEnricher  ClaimsEnricher
+	now             func() time.Time
+	newId           func() (string, error)
+}
+
+// tokenClaims adds the space separated scope claim of RFC 8693, the roles of the
+// user and the client_id claim of RFC 9068 to the registered claims. The gty
+// claim marks tokens whose subject is the client itself.
+type tokenClaims struct {
+	Scope     string   `json:"scope,omitempty"`
+	Amr       []string `json:"amr,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	ClientId  string   `json:"client_id,omitempty"`
+	GrantType string   `json:"gty,omitempty"`
+	jwt.StandardClaims
+}
+
+// idTokenClaims are the claims of an OpenID Connect ID token.
+type idTokenClaims struct {
+	Nonce    string   `json:"nonce,omitempty"`
+	AuthTime int64    `json:"auth_time,omitempty"`
+	Amr      []string `json:"amr,omitempty"`
+	jwt.StandardClaims
+}
+
+type JwtOidcProviderOption func(p *JwtOidcProvider)
+
+// WithRevocationStore rejects tokens whose jti has been revoked.
+func WithRevocationStore(revocationStore RevocationStore) JwtOidcProviderOption {
+	return func(p *JwtOidcProvider) {
+		p.revocationStore = revocationStore
+	}
+}
+
+// WithAudience issues access tokens for audience and only accepts tokens meant for
+// it, so tokens of other APIs and ID tokens can't be replayed.
+func WithAudience(audience string) JwtOidcProviderOption {
+	return func(p *JwtOidcProvider) {
+		p.audience = audience
+	}
+}
+
+// WithTokenLifetime changes the lifetime of tokens which are issued without one.
+func WithTokenLifetime(lifetime time.Duration) JwtOidcProviderOption {
+	return func(p *JwtOidcProvider) {
+		p.lifetime = lifetime
+	}
+}
+
+// WithClaimsEnricher adds the claims of the enricher to access and ID tokens,
+// reserved claims are never overridden.
+func WithClaimsEnricher(claimsEnricher ClaimsEnricher) JwtOidcProviderOption {
+	return func(p *JwtOidcProvider) {
+		p.claimsEnricher = claimsEnricher
+	}
+}
+
+// WithLeeway accepts tokens which expired or became valid within the leeway, so
+// the clocks of other servers may drift slightly.
+func WithLeeway(leeway time.Duration) JwtOidcProviderOption {
+	return func(p *JwtOidcProvider) {
+		p.leeway = leeway
+	}
+}
+
+func NewJwtOidcProvider(keys *KeyRing, issuer string, options ...JwtOidcProviderOption) OidcProvider {
+	provider := &JwtOidcProvider{
+		keys:     keys,
+		issuer:   issuer,
+		lifetime: DefaultTokenLifetime,
+		now:      time.Now,
+		newId:    NewRandomToken,
+	}
+
+	for _, option := range options {
+		option(provider)
+	}
+
+	return provider
+}
+
+func (p *JwtOidcProvider) GenerateToken(subject string, options TokenOptions) (string, error) {
+	if subject == "" {
+		return "", ErrJwtOidcProviderValidationError
+	}
+
+	standardClaims, err := p.standardClaims(subject, p.audience, options.Lifetime)
+	if err != nil {
+		return "", err
+	}
+
+	claims := &tokenClaims{
+		Scope:          strings.Join(options.Scopes, " "),
+		Amr:            options.Amr,
+		Roles:          options.Roles,
+		ClientId:       options.ClientId,
+		StandardClaims: standardClaims,
+	}
+
+	if options.ClientSubject {
+		claims.GrantType = ClientCredentialsGrantType
+	}
+
+	// subjects of clients can collide with user ids, they must not get the claims of the user
+	return p.sign(subject, claims, !options.ClientSubject)
+}
+
+// GenerateIdToken issues an ID token for the client the user authenticated with,
+// the nonce of the authentication request is passed on so the client can detect
+// replayed ID tokens.
+func (p *JwtOidcProvider) GenerateIdToken(subject string, options IdTokenOptions) (string, error) {
+	if subject == "" {
+		return "", ErrJwtOidcProviderValidationError
+	}
+
+	if options.ClientId == "" {
+		return "", ErrJwtOidcProviderMissingClientId
+	}
+
+	standardClaims, err := p.standardClaims(subject, options.ClientId, 0)
+	if err != nil {
+		return "", err
+	}
+
+	claims := &idTokenClaims{
+		Nonce:          options.Nonce,
+		Amr:            options.Amr,
+		StandardClaims: standardClaims,
+	}
+
+	if !options.AuthTime.IsZero() {
+		claims.AuthTime = options.AuthTime.Unix()
+	}
+
+	return p.sign(subject, claims, true)
+}
+
+func (p *JwtOidcProvider) standardClaims(subject string, audience string, lifetime time.Duration) (jwt.StandardClaims, error) {
+	jti, err := p.newId()
+	if err != nil {
+		return jwt.StandardClaims{}, err
+	}
+
+	if lifetime <= 0 {
+		lifetime = p.lifetime
+	}
+
+	now := p.now().Unix()
+	return jwt.StandardClaims{
+		Audience:  audience,
+		Id:        jti,
+		IssuedAt:  now,
+		NotBefore: now,
+		ExpiresAt: now + int64(lifetime.Seconds()),
+		Issuer:    p.issuer,
+		Subject:   subject,
+	}, nil
+}
+
+// sign only adds the claims of the enricher for tokens of users.
+func (p *JwtOidcProvider) sign(subject string, claims jwt.Claims, userSubject bool) (string, error) {
+	key := p.keys.SigningKey()
+	if !key.CanSign() {
+		return "", ErrJwtOidcProviderMissingSigningKey
+	}
+
+	if userSubject {
+		enriched, err := p.enrich(subject, claims)
+		if err != nil {
+			return "", err
+		}
+
+		claims = enriched
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.signKey)
+}
+
+// enrich adds the claims of the enricher, the claims are only converted to a map
+// when there is something to add.
+func (p *JwtOidcProvider) enrich(subject string, claims jwt.Claims) (jwt.Claims, error) {
+	if p.claimsEnricher == nil {
+		return claims, nil
+	}
+
+	additional, err := p.claimsEnricher.Claims(subject)
+	if err != nil {
+		return nil, fmt.Errorf("unable to enrich claims of %s: %w", subject, err)
+	}
+
+	if len(additional) == 0 {
+		return claims, nil
+	}
+
+	content, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	enriched := jwt.MapClaims{}
+	if err := json.Unmarshal(content, &enriched); err != nil {
+		return nil, err
+	}
+
+	for name, value := range additional {
+		if IsReservedClaim(name) && !enricherClaims[name] {
+			log.Printf("ignoring reserved claim %s for %s", name, subject)
+			continue
+		}
+
+		enriched[name] = value
+	}
+
+	return enriched, nil
+}
+
+func (p *JwtOidcProvider) ValidateToken(tokenString string) (map[string]interface{}, error) {
+	// the time claims are checked with the clock of the provider below, the parser
+	// would use the global jwt.TimeFunc
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.keys.VerificationKey(kid)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, kid)
+		}
+
+		// compare the exact algorithm, the family alone is not enough to rule out downgrades
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("invalid signing method: %v", token.Header["alg"])
+		}
+
+		if token.Claims.(jwt.MapClaims)["iss"] != p.issuer {
+			return nil, fmt.Errorf("%w: %v", ErrJwtOidcProviderUnknownIssuer, token.Claims.(jwt.MapClaims)["iss"])
+		}
+
+		return key.verifyKey, nil
+	})
+
+	if err != nil {
+		return nil, unwrapParseError(err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, ErrJwtOidcProviderInvalidToken
+	}
+
+	if err := verifyTimeClaims(claims, p.now(), p.leeway); err != nil {
+		return nil, err
+	}
+
+	if p.audience != "" && !claims.VerifyAudience(p.audience, true) {
+		return nil, ErrJwtOidcProviderInvalidAudience
+	}
+
+	if jti, _ := claims["jti"].(string); jti != "" && p.revocationStore != nil && p.revocationStore.IsRevoked(jti) {
+		return nil, ErrJwtOidcProviderRevokedToken
+	}
+
+	return claims, nil
+}
+
+func (p *JwtOidcProvider) Jwks() JwkSet {
+	return p.keys.Jwks()
+}
+
+// verifyTimeClaims checks exp, nbf and iat against now, moved by the leeway in
+// favour of the token. The claims are optional, like in jwt.MapClaims.Valid.
+func verifyTimeClaims(claims jwt.MapClaims, now time.Time, leeway time.Duration) error {
+	if !claims.VerifyExpiresAt(now.Add(-leeway).Unix(), false) {
+		return ErrJwtOidcProviderExpiredToken
+	}
+
+	if !claims.VerifyNotBefore(now.Add(leeway).Unix(), false) || !claims.VerifyIssuedAt(now.Add(leeway).Unix(), false) {
+		return ErrJwtOidcProviderTokenNotValidYet
+	}
+
+	return nil
+}
+
+// unwrapParseError returns the error of the key function, which the parser hides
+// in a jwt.ValidationError, so callers can tell why a token was rejected. Tokens
+// which can't be decoded are reported as malformed.
+func unwrapParseError(err error) error {
+	var validationErr *jwt.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	if validationErr.Errors&jwt.ValidationErrorMalformed != 0 {
+		return fmt.Errorf("%w: %s", ErrJwtOidcProviderMalformedToken, err)
+	}
+
+	if validationErr.Inner != nil {
+		return validationErr.Inner
+	}
+
+	return err
+}
@@ -0,0 +1,633 @@
+package lib
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JwtOidcProvider_GenerateToken_signs_with_current_key_of_key_ring(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	next, err := GenerateSigningKey("EdDSA")
+	require.Nil(t, err)
+	require.Nil(t, keys.Rotate(next))
+
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	// Act
+	tokenString, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+	require.Nil(t, err)
+
+	// Assert
+	assert.Equal(t, next.Kid, token.Header["kid"])
+}
+
+func Test_JwtOidcProvider_ValidateToken_accepts_tokens_of_rotated_key(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	keys, _ := newTestKeyRing(t, now)
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	next, err := GenerateSigningKey("EdDSA")
+	require.Nil(t, err)
+	require.Nil(t, keys.Rotate(next))
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "some-user", claims["sub"])
+}
+
+func Test_JwtOidcProvider_ValidateToken_returns_error_on_retired_key(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	keys, _ := newTestKeyRing(t, now)
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	next, err := GenerateSigningKey("EdDSA")
+	require.Nil(t, err)
+	require.Nil(t, keys.Rotate(next))
+
+	keys.now = func() time.Time {
+		return now.Add(time.Hour)
+	}
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, claims)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), ErrKeyRingRetiredKey.Error())
+}
+
+func Test_JwtOidcProvider_GenerateToken_generates_unique_jti(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	first, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+	second, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	first_claims, err := sut.ValidateToken(first)
+	require.Nil(t, err)
+	second_claims, err := sut.ValidateToken(second)
+	require.Nil(t, err)
+
+	// Assert
+	assert.NotEmpty(t, first_claims["jti"])
+	assert.NotEqual(t, first_claims["jti"], second_claims["jti"])
+}
+
+func Test_JwtOidcProvider_ValidateToken_returns_error_on_revoked_token(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	revocation_store_mock := &RevocationStoreMock{
+		NextIsRevokedResult: true,
+	}
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithRevocationStore(revocation_store_mock))
+	sut.(*JwtOidcProvider).newId = func() (string, error) {
+		return "some-jti", nil
+	}
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.True(t, errors.Is(err, ErrJwtOidcProviderRevokedToken))
+	assert.Equal(t, "some-jti", revocation_store_mock.LastJti)
+}
+
+func Test_JwtOidcProvider_ValidateToken_accepts_token_which_is_not_revoked(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	revocation_store_mock := &RevocationStoreMock{}
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithRevocationStore(revocation_store_mock))
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.NotNil(t, claims)
+	assert.True(t, revocation_store_mock.IsRevokedCalled)
+}
+
+func Test_JwtOidcProvider_GenerateToken_adds_space_separated_scope_claim(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{Scopes: []string{"some-scope", "other-scope"}})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "some-scope other-scope", claims["scope"])
+}
+
+func Test_JwtOidcProvider_GenerateToken_omits_scope_claim_without_scopes(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.NotContains(t, claims, "scope")
+}
+
+func Test_JwtOidcProvider_GenerateToken_adds_authentication_methods(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{Amr: []string{"pwd", "otp"}})
+	require.Nil(t, err)
+
+	idToken, err := sut.GenerateIdToken("some-user", IdTokenOptions{ClientId: "some-client", Amr: []string{"pwd"}})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+	idClaims, idErr := sut.ValidateToken(idToken)
+
+	// Assert
+	require.Nil(t, err)
+	require.Nil(t, idErr)
+	assert.Equal(t, []interface{}{"pwd", "otp"}, claims["amr"])
+	assert.Equal(t, []interface{}{"pwd"}, idClaims["amr"])
+}
+
+func Test_JwtOidcProvider_GenerateToken_adds_roles(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{Roles: []string{"analyst"}})
+	require.Nil(t, err)
+
+	clientToken, err := sut.GenerateToken("some-client", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+	clientClaims, clientErr := sut.ValidateToken(clientToken)
+
+	// Assert
+	require.Nil(t, err)
+	require.Nil(t, clientErr)
+	assert.Equal(t, []interface{}{"analyst"}, claims["roles"])
+	assert.NotContains(t, clientClaims, "roles")
+}
+
+func Test_JwtOidcProvider_GenerateToken_adds_client_id(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{ClientId: "some-client"})
+	require.Nil(t, err)
+
+	loginToken, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+	loginClaims, loginErr := sut.ValidateToken(loginToken)
+
+	// Assert
+	require.Nil(t, err)
+	require.Nil(t, loginErr)
+	assert.Equal(t, "some-client", claims["client_id"])
+	assert.NotContains(t, loginClaims, "client_id")
+}
+
+func Test_JwtOidcProvider_GenerateToken_marks_tokens_of_clients(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	token, err := sut.GenerateToken("some-client", TokenOptions{ClientId: "some-client", ClientSubject: true})
+	require.Nil(t, err)
+
+	userToken, err := sut.GenerateToken("some-user", TokenOptions{ClientId: "some-client"})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+	userClaims, userErr := sut.ValidateToken(userToken)
+
+	// Assert
+	require.Nil(t, err)
+	require.Nil(t, userErr)
+	assert.Equal(t, ClientCredentialsGrantType, claims["gty"])
+	assert.NotContains(t, userClaims, "gty")
+}
+
+func Test_JwtOidcProvider_GenerateIdToken_adds_client_as_audience_with_nonce_and_auth_time(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+	authTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	// Act
+	token, err := sut.GenerateIdToken("some-user", IdTokenOptions{ClientId: "some-client", Nonce: "some-nonce", AuthTime: authTime})
+	require.Nil(t, err)
+
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "some-user", claims["sub"])
+	assert.Equal(t, "some-client", claims["aud"])
+	assert.Equal(t, "some-nonce", claims["nonce"])
+	assert.Equal(t, float64(authTime.Unix()), claims["auth_time"])
+	assert.NotContains(t, claims, "scope")
+}
+
+func Test_JwtOidcProvider_GenerateIdToken_returns_error_without_client_id(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	// Act
+	token, err := sut.GenerateIdToken("some-user", IdTokenOptions{})
+
+	// Assert
+	assert.Empty(t, token)
+	assert.True(t, errors.Is(err, ErrJwtOidcProviderMissingClientId))
+}
+
+func Test_JwtOidcProvider_WithAudience_issues_and_accepts_tokens_for_audience(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithAudience("some-api"))
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "some-api", claims["aud"])
+}
+
+func Test_JwtOidcProvider_WithAudience_rejects_tokens_of_other_audience(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	other := NewJwtOidcProvider(keys, "some-issuer", WithAudience("other-api"))
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithAudience("some-api"))
+
+	token, err := other.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.True(t, errors.Is(err, ErrJwtOidcProviderInvalidAudience))
+}
+
+func Test_JwtOidcProvider_WithAudience_rejects_id_tokens(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithAudience("some-api"))
+
+	token, err := sut.GenerateIdToken("some-user", IdTokenOptions{ClientId: "some-client"})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.True(t, errors.Is(err, ErrJwtOidcProviderInvalidAudience))
+}
+
+func Test_JwtOidcProvider_WithAudience_rejects_tokens_without_audience(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	other := NewJwtOidcProvider(keys, "some-issuer")
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithAudience("some-api"))
+
+	token, err := other.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, claims)
+	assert.True(t, errors.Is(err, ErrJwtOidcProviderInvalidAudience))
+}
+
+func Test_JwtOidcProvider_GenerateToken_uses_lifetime_of_options(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	keys, _ := newTestKeyRing(t, now)
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithTokenLifetime(time.Hour))
+
+	short, err := sut.GenerateToken("some-user", TokenOptions{Lifetime: time.Minute})
+	require.Nil(t, err)
+	long, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	shortClaims, err := sut.ValidateToken(short)
+	require.Nil(t, err)
+	longClaims, err := sut.ValidateToken(long)
+	require.Nil(t, err)
+
+	// Assert
+	assert.Equal(t, float64(60), shortClaims["exp"].(float64)-shortClaims["iat"].(float64))
+	assert.Equal(t, float64(3600), longClaims["exp"].(float64)-longClaims["iat"].(float64))
+}
+
+func Test_JwtOidcProvider_WithClaimsEnricher_adds_claims_but_keeps_reserved_claims(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	enricher := &ClaimsEnricherMock{
+		NextClaimsResult: map[string]interface{}{"email": "some-user@example.com", "sub": "other-user", "iss": "other-issuer", "roles": []string{"admin"}},
+	}
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithClaimsEnricher(enricher))
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{Scopes: []string{"some-scope"}, Roles: []string{"analyst"}})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "some-user", enricher.LastSubject)
+	assert.Equal(t, "some-user@example.com", claims["email"])
+	assert.Equal(t, "some-user", claims["sub"])
+	assert.Equal(t, "some-issuer", claims["iss"])
+	assert.Equal(t, "some-scope", claims["scope"])
+	assert.Equal(t, []interface{}{"analyst"}, claims["roles"])
+}
+
+func Test_JwtOidcProvider_WithClaimsEnricher_adds_preferred_username_of_enricher(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	enricher := &ClaimsEnricherMock{
+		NextClaimsResult: map[string]interface{}{"preferred_username": "some-user"},
+	}
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithClaimsEnricher(enricher))
+
+	token, err := sut.GenerateToken("some-user-id", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "some-user", claims["preferred_username"])
+	assert.True(t, IsReservedClaim("preferred_username"))
+}
+
+func Test_JwtOidcProvider_WithClaimsEnricher_skips_tokens_of_clients(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	enricher := &ClaimsEnricherMock{
+		NextClaimsResult: map[string]interface{}{"email": "some-user@example.com"},
+	}
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithClaimsEnricher(enricher))
+
+	token, err := sut.GenerateToken("some-client", TokenOptions{ClientId: "some-client", ClientSubject: true})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.False(t, enricher.ClaimsCalled)
+	assert.NotContains(t, claims, "email")
+}
+
+func Test_JwtOidcProvider_WithClaimsEnricher_adds_claims_to_id_tokens(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	enricher := &ClaimsEnricherMock{
+		NextClaimsResult: map[string]interface{}{"name": "Some User", "nonce": "other-nonce"},
+	}
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithClaimsEnricher(enricher))
+
+	token, err := sut.GenerateIdToken("some-user", IdTokenOptions{ClientId: "some-client", Nonce: "some-nonce"})
+	require.Nil(t, err)
+
+	// Act
+	claims, err := sut.ValidateToken(token)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "Some User", claims["name"])
+	assert.Equal(t, "some-nonce", claims["nonce"])
+}
+
+func Test_JwtOidcProvider_WithClaimsEnricher_returns_error_of_enricher(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	enricher := &ClaimsEnricherMock{
+		NextClaimsError: errors.New("some-error"),
+	}
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithClaimsEnricher(enricher))
+
+	// Act
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+
+	// Assert
+	assert.Empty(t, token)
+	assert.NotNil(t, err)
+}
+
+func Test_JwtOidcProvider_ValidateToken_accepts_expired_token_within_leeway(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	keys, _ := newTestKeyRing(t, now)
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithLeeway(time.Minute))
+	sut.(*JwtOidcProvider).now = func() time.Time {
+		return now
+	}
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	sut.(*JwtOidcProvider).now = func() time.Time {
+		return now.Add(DefaultTokenLifetime + 30*time.Second)
+	}
+	_, withinErr := sut.ValidateToken(token)
+
+	sut.(*JwtOidcProvider).now = func() time.Time {
+		return now.Add(DefaultTokenLifetime + 2*time.Minute)
+	}
+	_, outsideErr := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, withinErr)
+	assert.Equal(t, ErrJwtOidcProviderExpiredToken, outsideErr)
+}
+
+func Test_JwtOidcProvider_ValidateToken_rejects_revoked_token_within_leeway(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	keys, _ := newTestKeyRing(t, now)
+	revocationStore := NewInMemoryRevocationStore(time.Minute)
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithLeeway(time.Minute), WithRevocationStore(revocationStore))
+	sut.(*JwtOidcProvider).now = func() time.Time {
+		return now
+	}
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	claims, err := sut.ValidateToken(token)
+	require.Nil(t, err)
+	revocationStore.Revoke(claims["jti"].(string), time.Unix(int64(claims["exp"].(float64)), 0))
+
+	// Act
+	later := func() time.Time {
+		return now.Add(DefaultTokenLifetime + 30*time.Second)
+	}
+	sut.(*JwtOidcProvider).now = later
+	revocationStore.(*InMemoryRevocationStore).now = later
+	_, err = sut.ValidateToken(token)
+
+	// Assert
+	assert.Equal(t, ErrJwtOidcProviderRevokedToken, err)
+}
+
+func Test_JwtOidcProvider_ValidateToken_accepts_token_of_a_clock_ahead_within_leeway(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	keys, _ := newTestKeyRing(t, now)
+	issuer := NewJwtOidcProvider(keys, "some-issuer")
+	issuer.(*JwtOidcProvider).now = func() time.Time {
+		return now.Add(30 * time.Second)
+	}
+
+	token, err := issuer.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	strict := NewJwtOidcProvider(keys, "some-issuer")
+	sut := NewJwtOidcProvider(keys, "some-issuer", WithLeeway(time.Minute))
+
+	// Act
+	_, strictErr := strict.ValidateToken(token)
+	_, err = sut.ValidateToken(token)
+
+	// Assert
+	assert.Equal(t, ErrJwtOidcProviderTokenNotValidYet, strictErr)
+	assert.Nil(t, err)
+}
+
+func Test_JwtOidcProvider_does_not_share_its_clock_with_other_providers(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	past, _ := time.Parse(time.RFC3339, "2000-01-02T03:04:05.00Z")
+	pastProvider := NewJwtOidcProvider(keys, "some-issuer")
+	pastProvider.(*JwtOidcProvider).now = func() time.Time {
+		return past
+	}
+	sut := NewJwtOidcProvider(keys, "some-issuer")
+
+	token, err := sut.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+	pastToken, err := pastProvider.GenerateToken("some-user", TokenOptions{})
+	require.Nil(t, err)
+
+	// Act
+	_, err = sut.ValidateToken(token)
+	_, pastErr := pastProvider.ValidateToken(pastToken)
+	_, presentErr := sut.ValidateToken(token)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, pastErr)
+	assert.Nil(t, presentErr)
+}
+
+// run with -race, the providers used to set the global jwt.TimeFunc on every call
+func Test_JwtOidcProvider_is_safe_for_concurrent_use(t *testing.T) {
+	// Arrange
+	keys, _ := newTestKeyRing(t, time.Now())
+	past, _ := time.Parse(time.RFC3339, "2000-01-02T03:04:05.00Z")
+	providers := []OidcProvider{
+		NewJwtOidcProvider(keys, "some-issuer"),
+		NewJwtOidcProvider(keys, "some-issuer", WithLeeway(time.Minute)),
+		NewJwtOidcProvider(keys, "some-issuer"),
+	}
+	providers[2].(*JwtOidcProvider).now = func() time.Time {
+		return past
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 300)
+
+	// Act
+	for i := 0; i < 100; i++ {
+		for _, provider := range providers {
+			wg.Add(1)
+			go func(provider OidcProvider) {
+				defer wg.Done()
+
+				token, err := provider.GenerateToken("some-user", TokenOptions{})
+				if err == nil {
+					_, err = provider.ValidateToken(token)
+				}
+
+				errs <- err
+			}(provider)
+		}
+	}
+
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		assert.Nil(t, err)
+	}
+}
@@ -0,0 +1,147 @@
+package lib
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrKeyRingUnknownKey   = errors.New("unknown key id")
+	ErrKeyRingRetiredKey   = errors.New("key has been retired")
+	ErrKeyRingDuplicateKey = errors.New("key is already in the key ring")
+)
+
+// DefaultKeyRetention matches the token lifetime.
+const DefaultKeyRetention = time.Hour
+
+// rotatedKey no longer signs tokens, but validates them until it retires.
+type rotatedKey struct {
+	key       *SigningKey
+	retiresAt time.Time
+}
+
+// KeyRing holds the key which signs new tokens and the previous keys, which stay
+// valid for validation for the retention period after they were rotated out. The
+// retention should be at least the token lifetime, so tokens signed just before a
+// rotation don't become invalid.
+type KeyRing struct {
+	mutex     sync.RWMutex
+	current   *SigningKey
+	rotated   []rotatedKey
+	retired   map[string]bool
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewKeyRing(key *SigningKey, retention time.Duration) *KeyRing {
+	return &KeyRing{
+		current:   key,
+		retired:   map[string]bool{},
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// SigningKey returns the key new tokens must be signed with.
+func (r *KeyRing) SigningKey() *SigningKey {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.current
+}
+
+// VerificationKey finds the key a token was signed with. An empty kid selects the
+// current key, tokens issued before the key ring was introduced have no kid.
+func (r *KeyRing) VerificationKey(kid string) (*SigningKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if kid == "" || kid == r.current.Kid {
+		return r.current, nil
+	}
+
+	if r.retired[kid] {
+		return nil, ErrKeyRingRetiredKey
+	}
+
+	now := r.now()
+	for _, rotated := range r.rotated {
+		if rotated.key.Kid != kid {
+			continue
+		}
+
+		if !now.Before(rotated.retiresAt) {
+			return nil, ErrKeyRingRetiredKey
+		}
+
+		return rotated.key, nil
+	}
+
+	return nil, ErrKeyRingUnknownKey
+}
+
+// Rotate makes key the signing key, the previous signing key stays valid for
+// validation until the retention period has passed.
+func (r *KeyRing) Rotate(key *SigningKey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if key.Kid == r.current.Kid {
+		return ErrKeyRingDuplicateKey
+	}
+
+	if r.retired[key.Kid] {
+		return ErrKeyRingRetiredKey
+	}
+
+	now := r.now()
+	rotated := []rotatedKey{{
+		key:       r.current,
+		retiresAt: now.Add(r.retention),
+	}}
+
+	for _, previous := range r.rotated {
+		if previous.key.Kid == key.Kid {
+			// a previous key is reactivated, it is the current key again
+			continue
+		}
+
+		if !now.Before(previous.retiresAt) {
+			// only the kid is kept, so the key material can be released
+			r.retired[previous.key.Kid] = true
+			continue
+		}
+
+		rotated = append(rotated, previous)
+	}
+
+	r.current = key
+	r.rotated = rotated
+
+	return nil
+}
+
+// Jwks returns the public keys of the signing key and of the keys which haven't
+// retired yet, HMAC keys are never published.
+func (r *KeyRing) Jwks() JwkSet {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	jwks := JwkSet{
+		Keys: []Jwk{},
+	}
+
+	if r.current.jwk != nil {
+		jwks.Keys = append(jwks.Keys, *r.current.jwk)
+	}
+
+	now := r.now()
+	for _, rotated := range r.rotated {
+		if rotated.key.jwk != nil && now.Before(rotated.retiresAt) {
+			jwks.Keys = append(jwks.Keys, *rotated.key.jwk)
+		}
+	}
+
+	return jwks
+}
@@ -0,0 +1,166 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyRing(t *testing.T, now time.Time) (*KeyRing, *SigningKey) {
+	key, err := GenerateSigningKey("EdDSA")
+	require.Nil(t, err)
+
+	sut := NewKeyRing(key, time.Hour)
+	sut.now = func() time.Time {
+		return now
+	}
+
+	return sut, key
+}
+
+func Test_KeyRing_VerificationKey_returns_signing_key_for_empty_kid(t *testing.T) {
+	// Arrange
+	sut, key := newTestKeyRing(t, time.Now())
+
+	// Act
+	res, err := sut.VerificationKey("")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, key, res)
+}
+
+func Test_KeyRing_VerificationKey_returns_error_on_unknown_kid(t *testing.T) {
+	// Arrange
+	sut, _ := newTestKeyRing(t, time.Now())
+
+	// Act
+	res, err := sut.VerificationKey("some-kid")
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrKeyRingUnknownKey))
+}
+
+func Test_KeyRing_Rotate_replaces_signing_key_and_keeps_previous_key_for_validation(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut, previous := newTestKeyRing(t, now)
+	next, err := GenerateSigningKey("EdDSA")
+	require.Nil(t, err)
+
+	// Act
+	err = sut.Rotate(next)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, next, sut.SigningKey())
+
+	res, err := sut.VerificationKey(previous.Kid)
+	assert.Nil(t, err)
+	assert.Equal(t, previous, res)
+}
+
+func Test_KeyRing_Rotate_retires_previous_key_after_retention(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut, previous := newTestKeyRing(t, now)
+	next, err := GenerateSigningKey("EdDSA")
+	require.Nil(t, err)
+
+	err = sut.Rotate(next)
+	require.Nil(t, err)
+
+	sut.now = func() time.Time {
+		return now.Add(time.Hour)
+	}
+
+	// Act
+	res, err := sut.VerificationKey(previous.Kid)
+
+	// Assert
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, ErrKeyRingRetiredKey))
+}
+
+func Test_KeyRing_Rotate_remembers_retired_keys(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut, first := newTestKeyRing(t, now)
+	second, err := GenerateSigningKey("EdDSA")
+	require.Nil(t, err)
+	third, err := GenerateSigningKey("EdDSA")
+	require.Nil(t, err)
+
+	err = sut.Rotate(second)
+	require.Nil(t, err)
+
+	sut.now = func() time.Time {
+		return now.Add(2 * time.Hour)
+	}
+
+	// Act
+	err = sut.Rotate(third)
+	require.Nil(t, err)
+
+	// Assert
+	_, err = sut.VerificationKey(first.Kid)
+	assert.True(t, errors.Is(err, ErrKeyRingRetiredKey))
+	assert.True(t, errors.Is(sut.Rotate(first), ErrKeyRingRetiredKey))
+
+	res, err := sut.VerificationKey(second.Kid)
+	assert.Nil(t, err)
+	assert.Equal(t, second, res)
+}
+
+func Test_KeyRing_Rotate_returns_error_on_current_key(t *testing.T) {
+	// Arrange
+	sut, key := newTestKeyRing(t, time.Now())
+
+	// Act
+	err := sut.Rotate(key)
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrKeyRingDuplicateKey))
+}
+
+func Test_KeyRing_Jwks_publishes_keys_until_they_retire(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	sut, previous := newTestKeyRing(t, now)
+	next, err := GenerateSigningKey("EdDSA")
+	require.Nil(t, err)
+
+	err = sut.Rotate(next)
+	require.Nil(t, err)
+
+	// Act
+	before := sut.Jwks()
+	sut.now = func() time.Time {
+		return now.Add(time.Hour)
+	}
+	after := sut.Jwks()
+
+	// Assert
+	require.Len(t, before.Keys, 2)
+	assert.Equal(t, next.Kid, before.Keys[0].Kid)
+	assert.Equal(t, previous.Kid, before.Keys[1].Kid)
+
+	require.Len(t, after.Keys, 1)
+	assert.Equal(t, next.Kid, after.Keys[0].Kid)
+}
+
+func Test_KeyRing_Jwks_does_not_publish_hmac_keys(t *testing.T) {
+	// Arrange
+	sut := NewKeyRing(NewHmacSigningKey([]byte("some-secret")), time.Hour)
+
+	// Act
+	jwks := sut.Jwks()
+
+	// Assert
+	assert.NotNil(t, jwks.Keys)
+	assert.Empty(t, jwks.Keys)
+}
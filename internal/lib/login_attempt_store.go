@@ -0,0 +1,20 @@
+package lib
+
+import (
+	"time"
+)
+
+// LoginAttemptStore tracks failed logins per key, like a username or a client ip.
+// Keys which failed too often are locked out for a while.
+type LoginAttemptStore interface {
+	// LockedOutFor returns how long the key is still locked out, zero if it isn't.
+	LockedOutFor(key string) time.Duration
+	// TryAttempt returns how long the key is still locked out, or counts the attempt
+	// as a failure and returns zero. The check and the count are atomic, so concurrent
+	// attempts can't all pass the check before any of them failed.
+	TryAttempt(key string) time.Duration
+	// Forgive takes back an attempt which didn't turn out to be a failed login.
+	Forgive(key string)
+	// Reset forgets the failures of the key after a successful login.
+	Reset(key string)
+}
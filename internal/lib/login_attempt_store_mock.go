@@ -0,0 +1,39 @@
+package lib
+
+import (
+	"time"
+)
+
+type LoginAttemptStoreMock struct {
+	LockedOutForCalled bool
+	TryAttemptCalled   bool
+	ForgiveCalled      bool
+	ResetCalled        bool
+
+	LastKey string
+
+	NextLockedOutForResult time.Duration
+	NextTryAttemptResult   time.Duration
+}
+
+func (m *LoginAttemptStoreMock) LockedOutFor(key string) time.Duration {
+	m.LockedOutForCalled = true
+	m.LastKey = key
+	return m.NextLockedOutForResult
+}
+
+func (m *LoginAttemptStoreMock) TryAttempt(key string) time.Duration {
+	m.TryAttemptCalled = true
+	m.LastKey = key
+	return m.NextTryAttemptResult
+}
+
+func (m *LoginAttemptStoreMock) Forgive(key string) {
+	m.ForgiveCalled = true
+	m.LastKey = key
+}
+
+func (m *LoginAttemptStoreMock) Reset(key string) {
+	m.ResetCalled = true
+	m.LastKey = key
+}
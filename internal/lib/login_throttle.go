@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"time"
+)
+
+// LoginThrottle slows down password guessing by tracking failed logins per username
+// and per client ip. Every attempt counts as a failure before the password is checked,
+// so a burst of concurrent guesses is capped like sequential ones. Only the username is
+// reset by a successful login, otherwise a single valid account could reset the
+// failures of an ip guessing other passwords.
+type LoginThrottle struct {
+	usernames LoginAttemptStore
+	ips       LoginAttemptStore
+}
+
+func NewLoginThrottle(usernames LoginAttemptStore, ips LoginAttemptStore) *LoginThrottle {
+	return &LoginThrottle{
+		usernames: usernames,
+		ips:       ips,
+	}
+}
+
+// TryAttempt returns the lockout of the ip or the username, or counts the attempt
+// against both and returns zero. A locked out ip is rejected before the username is
+// looked at, so it can't fill the store with the usernames it tries. An empty ip is
+// not tracked.
+func (t *LoginThrottle) TryAttempt(username string, ip string) time.Duration {
+	if ip != "" {
+		if lockout := t.ips.LockedOutFor(ip); lockout > 0 {
+			return lockout
+		}
+	}
+
+	if lockout := t.usernames.TryAttempt(username); lockout > 0 {
+		return lockout
+	}
+
+	// the ip can have been locked out by a concurrent attempt since the check above
+	if ip != "" {
+		if lockout := t.ips.TryAttempt(ip); lockout > 0 {
+			t.usernames.Forgive(username)
+			return lockout
+		}
+	}
+
+	return 0
+}
+
+// Forgive takes back an attempt which ended without a wrong password or one time
+// password, e.g. because of an internal error.
+func (t *LoginThrottle) Forgive(username string, ip string) {
+	t.usernames.Forgive(username)
+	if ip != "" {
+		t.ips.Forgive(ip)
+	}
+}
+
+func (t *LoginThrottle) RecordSuccess(username string, ip string) {
+	t.usernames.Reset(username)
+	if ip != "" {
+		t.ips.Forgive(ip)
+	}
+}
@@ -0,0 +1,144 @@
+package lib
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LoginThrottle_TryAttempt_counts_attempt_against_username_and_ip(t *testing.T) {
+	// Arrange
+	usernames := &LoginAttemptStoreMock{}
+	ips := &LoginAttemptStoreMock{}
+	sut := NewLoginThrottle(usernames, ips)
+
+	// Act
+	lockout := sut.TryAttempt("some-user", "192.0.2.1")
+
+	// Assert
+	assert.Equal(t, time.Duration(0), lockout)
+	assert.True(t, usernames.TryAttemptCalled)
+	assert.Equal(t, "some-user", usernames.LastKey)
+	assert.True(t, ips.TryAttemptCalled)
+	assert.Equal(t, "192.0.2.1", ips.LastKey)
+}
+
+func Test_LoginThrottle_TryAttempt_returns_lockout_of_username_without_counting_ip(t *testing.T) {
+	// Arrange
+	usernames := &LoginAttemptStoreMock{NextTryAttemptResult: time.Minute}
+	ips := &LoginAttemptStoreMock{}
+	sut := NewLoginThrottle(usernames, ips)
+
+	// Act
+	lockout := sut.TryAttempt("some-user", "192.0.2.1")
+
+	// Assert
+	assert.Equal(t, time.Minute, lockout)
+	assert.False(t, ips.TryAttemptCalled)
+}
+
+func Test_LoginThrottle_TryAttempt_returns_lockout_of_ip_without_counting_username(t *testing.T) {
+	// Arrange
+	usernames := &LoginAttemptStoreMock{}
+	ips := &LoginAttemptStoreMock{NextLockedOutForResult: 2 * time.Minute}
+	sut := NewLoginThrottle(usernames, ips)
+
+	// Act
+	lockout := sut.TryAttempt("some-user", "192.0.2.1")
+
+	// Assert
+	assert.Equal(t, 2*time.Minute, lockout)
+	assert.False(t, usernames.TryAttemptCalled)
+	assert.False(t, ips.TryAttemptCalled)
+}
+
+func Test_LoginThrottle_TryAttempt_does_not_track_usernames_of_locked_out_ip(t *testing.T) {
+	// Arrange
+	policy := LoginAttemptPolicy{FreeAttempts: 1, BaseLockout: time.Minute, MaxLockout: time.Hour, ResetAfter: time.Hour}
+	usernames := NewInMemoryLoginAttemptStore(DefaultUsernameLoginAttemptPolicy)
+	sut := NewLoginThrottle(usernames, NewInMemoryLoginAttemptStore(policy))
+	sut.TryAttempt("some-user", "192.0.2.1")
+	sut.TryAttempt("other-user", "192.0.2.1")
+
+	// Act
+	for i := 0; i < 100; i++ {
+		sut.TryAttempt(fmt.Sprintf("user-%d", i), "192.0.2.1")
+	}
+
+	// Assert
+	assert.Len(t, usernames.(*InMemoryLoginAttemptStore).entries, 2)
+}
+
+func Test_LoginThrottle_TryAttempt_forgives_username_when_ip_is_locked_out(t *testing.T) {
+	// Arrange
+	usernames := &LoginAttemptStoreMock{}
+	ips := &LoginAttemptStoreMock{NextTryAttemptResult: 2 * time.Minute}
+	sut := NewLoginThrottle(usernames, ips)
+
+	// Act
+	lockout := sut.TryAttempt("some-user", "192.0.2.1")
+
+	// Assert
+	assert.Equal(t, 2*time.Minute, lockout)
+	assert.True(t, usernames.ForgiveCalled)
+}
+
+func Test_LoginThrottle_TryAttempt_caps_concurrent_attempts(t *testing.T) {
+	// Arrange
+	policy := LoginAttemptPolicy{FreeAttempts: 5, BaseLockout: time.Minute, MaxLockout: time.Hour, ResetAfter: time.Hour}
+	sut := NewLoginThrottle(NewInMemoryLoginAttemptStore(policy), NewInMemoryLoginAttemptStore(policy))
+
+	// Act
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	allowed := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if sut.TryAttempt("some-user", "192.0.2.1") == 0 {
+				mutex.Lock()
+				allowed++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, policy.FreeAttempts+1, allowed)
+}
+
+func Test_LoginThrottle_ignores_empty_ip(t *testing.T) {
+	// Arrange
+	usernames := &LoginAttemptStoreMock{}
+	ips := &LoginAttemptStoreMock{}
+	sut := NewLoginThrottle(usernames, ips)
+
+	// Act
+	sut.TryAttempt("some-user", "")
+	sut.Forgive("some-user", "")
+	sut.RecordSuccess("some-user", "")
+
+	// Assert
+	assert.False(t, ips.TryAttemptCalled)
+	assert.False(t, ips.ForgiveCalled)
+}
+
+func Test_LoginThrottle_RecordSuccess_resets_username_and_forgives_ip(t *testing.T) {
+	// Arrange
+	usernames := &LoginAttemptStoreMock{}
+	ips := &LoginAttemptStoreMock{}
+	sut := NewLoginThrottle(usernames, ips)
+
+	// Act
+	sut.RecordSuccess("some-user", "192.0.2.1")
+
+	// Assert
+	assert.True(t, usernames.ResetCalled)
+	assert.False(t, ips.ResetCalled)
+	assert.True(t, ips.ForgiveCalled)
+}
@@ -0,0 +1,32 @@
+package lib
+
+import (
+	"errors"
+)
+
+var (
+	ErrMfaStoreNotEnrolled  = errors.New("user has no second factor")
+	ErrMfaStoreNoEnrollment = errors.New("no pending enrollment")
+	ErrMfaStoreInvalidCode  = errors.New("invalid one time password")
+)
+
+// MfaEnrollment is shown to the user once, the secret is added to an authenticator
+// app and the recovery codes are kept in case the app is lost.
+type MfaEnrollment struct {
+	Secret        string   `json:"secret"`
+	Uri           string   `json:"uri"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MfaStore keeps the TOTP secrets and recovery codes of users by subject, so they
+// stay in place when the username changes. An enrollment only becomes active once
+// it is confirmed with a code of the app, so a user can't lock themselves out with
+// a secret they never saved.
+type MfaStore interface {
+	// Enroll shows account, like the username, as the label in authenticator apps.
+	Enroll(subject string, account string) (*MfaEnrollment, error)
+	Confirm(subject string, code string) error
+	IsEnrolled(subject string) bool
+	// Verify accepts a TOTP code or a recovery code, both can only be used once.
+	Verify(subject string, code string) error
+}
@@ -0,0 +1,48 @@
+package lib
+
+type MfaStoreMock struct {
+	EnrollCalled     bool
+	ConfirmCalled    bool
+	IsEnrolledCalled bool
+	VerifyCalled     bool
+
+	LastSubject string
+	LastAccount string
+	LastCode    string
+
+	NextEnrollResult *MfaEnrollment
+	NextEnrollError  error
+
+	NextConfirmError error
+
+	NextIsEnrolledResult bool
+
+	NextVerifyError error
+}
+
+func (m *MfaStoreMock) Enroll(subject string, account string) (*MfaEnrollment, error) {
+	m.EnrollCalled = true
+	m.LastSubject = subject
+	m.LastAccount = account
+	return m.NextEnrollResult, m.NextEnrollError
+}
+
+func (m *MfaStoreMock) Confirm(subject string, code string) error {
+	m.ConfirmCalled = true
+	m.LastSubject = subject
+	m.LastCode = code
+	return m.NextConfirmError
+}
+
+func (m *MfaStoreMock) IsEnrolled(subject string) bool {
+	m.IsEnrolledCalled = true
+	m.LastSubject = subject
+	return m.NextIsEnrolledResult
+}
+
+func (m *MfaStoreMock) Verify(subject string, code string) error {
+	m.VerifyCalled = true
+	m.LastSubject = subject
+	m.LastCode = code
+	return m.NextVerifyError
+}
@@ -0,0 +1,21 @@
+package lib
+
+import (
+	"errors"
+)
+
+var (
+	ErrNotifierInvalidNotification = errors.New("recipient or subject contains a line break")
+)
+
+// Notification is a message to a user, like the link to reset a password.
+type Notification struct {
+	Recipient string
+	Subject   string
+	Body      string
+}
+
+// Notifier delivers notifications to users, for example by email.
+type Notifier interface {
+	Notify(notification Notification) error
+}
@@ -0,0 +1,15 @@
+package lib
+
+type NotifierMock struct {
+	NotifyCalled bool
+
+	LastNotification Notification
+
+	NextNotifyError error
+}
+
+func (m *NotifierMock) Notify(notification Notification) error {
+	m.NotifyCalled = true
+	m.LastNotification = notification
+	return m.NextNotifyError
+}
@@ -1,6 +1,40 @@
 package lib
 
+import (
+	"time"
+)
+
+// ClientCredentialsGrantType is the gty claim of tokens whose subject is the
+// client itself.
+const ClientCredentialsGrantType = "client_credentials"
+
+// TokenOptions describe what an access token is issued for besides the subject,
+// without a lifetime the default of the provider is used. Amr lists the methods
+// the user authenticated with and Roles the roles of the user, both are empty for
+// tokens of clients. ClientId is the OAuth client the token was issued to, it is
+// empty for logins at /auth. ClientSubject is set when the subject is the client
+// itself, like for the client_credentials grant, such tokens get no claims of users.
+type TokenOptions struct {
+	Scopes        []string
+	Lifetime      time.Duration
+	Amr           []string
+	Roles         []string
+	ClientId      string
+	ClientSubject bool
+}
+
+// IdTokenOptions describe the authentication an ID token is issued for, the ID
+// token is meant for the client and not for resource servers.
+type IdTokenOptions struct {
+	ClientId string
+	Nonce    string
+	AuthTime time.Time
+	Amr      []string
+}
+
 type OidcProvider interface {
-	GenerateToken(username string) (string, error)
+	GenerateToken(subject string, options TokenOptions) (string, error)
+	GenerateIdToken(subject string, options IdTokenOptions) (string, error)
 	ValidateToken(token string) (map[string]interface{}, error)
+	Jwks() JwkSet
 }
@@ -1,27 +1,49 @@
 package lib
 
 type OidcProviderMock struct {
-	GenerateTokenCalled bool
-	ValidateTokenCalled bool
+	GenerateTokenCalled   bool
+	GenerateIdTokenCalled bool
+	ValidateTokenCalled   bool
+	JwksCalled            bool
 
-	LastUsername string
-	LastToken    string
+	LastUsername       string
+	LastTokenOptions   TokenOptions
+	LastIdTokenOptions IdTokenOptions
+	LastToken          string
 
 	NextGenerateTokenResult string
 	NextGenerateTokenError  error
 
+	NextGenerateIdTokenResult string
+	NextGenerateIdTokenError  error
+
 	NextValidateTokenResult map[string]interface{}
 	NextValidateTokenError  error
+
+	NextJwksResult JwkSet
 }
 
-func (m *OidcProviderMock) GenerateToken(username string) (string, error) {
+func (m *OidcProviderMock) GenerateToken(username string, options TokenOptions) (string, error) {
 	m.GenerateTokenCalled = true
 	m.LastUsername = username
+	m.LastTokenOptions = options
 	return m.NextGenerateTokenResult, m.NextGenerateTokenError
 }
 
+func (m *OidcProviderMock) GenerateIdToken(subject string, options IdTokenOptions) (string, error) {
+	m.GenerateIdTokenCalled = true
+	m.LastUsername = subject
+	m.LastIdTokenOptions = options
+	return m.NextGenerateIdTokenResult, m.NextGenerateIdTokenError
+}
+
 func (m *OidcProviderMock) ValidateToken(token string) (map[string]interface{}, error) {
 	m.ValidateTokenCalled = true
 	m.LastToken = token
 	return m.NextValidateTokenResult, m.NextValidateTokenError
 }
+
+func (m *OidcProviderMock) Jwks() JwkSet {
+	m.JwksCalled = true
+	return m.NextJwksResult
+}
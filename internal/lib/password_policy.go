@@ -0,0 +1,113 @@
+package lib
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrPasswordPolicyTooShort = errors.New("password is too short")
+	ErrPasswordPolicyBreached = errors.New("password is known from a data breach")
+	ErrPasswordPolicyReused   = errors.New("password was used before")
+)
+
+const (
+	// DefaultPasswordMinLength follows NIST SP 800-63B, which asks for long
+	// passwords instead of rules about the characters they contain.
+	DefaultPasswordMinLength = 12
+
+	// DefaultPasswordHistory is how many earlier passwords can't be used again.
+	DefaultPasswordHistory = 5
+)
+
+// PasswordPolicy decides which new passwords users may choose. The current
+// password of a user is never accepted as the new one, History counts the earlier
+// passwords before it which are rejected as well.
+type PasswordPolicy struct {
+	MinLength int
+	History   int
+	breached  map[string]bool
+}
+
+// NewPasswordPolicy rejects the breached passwords regardless of their case.
+func NewPasswordPolicy(minLength int, history int, breached []string) *PasswordPolicy {
+	policy := &PasswordPolicy{
+		MinLength: minLength,
+		History:   history,
+		breached:  map[string]bool{},
+	}
+
+	for _, password := range breached {
+		policy.breached[strings.ToLower(password)] = true
+	}
+
+	return policy
+}
+
+// LoadPasswordPolicy reads the breached passwords from a file with one password
+// per line, like the lists published of known breaches. Without a file no
+// password is rejected as breached.
+func LoadPasswordPolicy(minLength int, history int, breachedFile string) (*PasswordPolicy, error) {
+	if breachedFile == "" {
+		return NewPasswordPolicy(minLength, history, nil), nil
+	}
+
+	file, err := os.Open(breachedFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	breached := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if password := strings.TrimSpace(scanner.Text()); password != "" {
+			breached = append(breached, password)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", breachedFile, err)
+	}
+
+	return NewPasswordPolicy(minLength, history, breached), nil
+}
+
+// Check returns the rule the password violates, user is the user who changes its
+// password and is compared against its current and earlier passwords.
+func (p *PasswordPolicy) Check(password string, user *User) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return fmt.Errorf("%w: at least %d characters are required", ErrPasswordPolicyTooShort, p.MinLength)
+	}
+
+	if p.breached[strings.ToLower(password)] {
+		return ErrPasswordPolicyBreached
+	}
+
+	if user == nil {
+		return nil
+	}
+
+	hashes := []string{user.PasswordHash}
+	for i, hash := range user.PasswordHistory {
+		if i >= p.History {
+			break
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return ErrPasswordPolicyReused
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PasswordPolicy_Check_accepts_long_new_password(t *testing.T) {
+	// Arrange
+	sut := NewPasswordPolicy(12, 5, []string{"password1234"})
+	user := &User{PasswordHash: hashTestPassword(t, "old-password")}
+
+	// Act
+	err := sut.Check("correct horse battery", user)
+
+	// Assert
+	assert.Nil(t, err)
+}
+
+func Test_PasswordPolicy_Check_rejects_short_password(t *testing.T) {
+	// Arrange
+	sut := NewPasswordPolicy(12, 5, nil)
+
+	// Act
+	err := sut.Check("short-pw", nil)
+	multibyteErr := sut.Check("äöüäöüäöüäöü", nil)
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrPasswordPolicyTooShort))
+	assert.Nil(t, multibyteErr)
+}
+
+func Test_PasswordPolicy_Check_rejects_breached_password_regardless_of_case(t *testing.T) {
+	// Arrange
+	sut := NewPasswordPolicy(8, 5, []string{"Password1234"})
+
+	// Act
+	err := sut.Check("PASSWORD1234", nil)
+
+	// Assert
+	assert.Equal(t, ErrPasswordPolicyBreached, err)
+}
+
+func Test_PasswordPolicy_Check_rejects_current_and_earlier_passwords(t *testing.T) {
+	// Arrange
+	sut := NewPasswordPolicy(8, 2, nil)
+	user := &User{
+		PasswordHash: hashTestPassword(t, "current-password"),
+		PasswordHistory: []string{
+			hashTestPassword(t, "previous-password"),
+			hashTestPassword(t, "earlier-password"),
+			hashTestPassword(t, "forgotten-password"),
+		},
+	}
+
+	// Act
+	currentErr := sut.Check("current-password", user)
+	previousErr := sut.Check("previous-password", user)
+	earlierErr := sut.Check("earlier-password", user)
+	forgottenErr := sut.Check("forgotten-password", user)
+
+	// Assert
+	assert.Equal(t, ErrPasswordPolicyReused, currentErr)
+	assert.Equal(t, ErrPasswordPolicyReused, previousErr)
+	assert.Equal(t, ErrPasswordPolicyReused, earlierErr)
+	assert.Nil(t, forgottenErr)
+}
+
+func Test_LoadPasswordPolicy_reads_breached_passwords_from_file(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.Nil(t, os.WriteFile(path, []byte("password1234\n\n  qwertyuiop12  \n"), 0600))
+
+	// Act
+	sut, err := LoadPasswordPolicy(8, 5, path)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, ErrPasswordPolicyBreached, sut.Check("password1234", nil))
+	assert.Equal(t, ErrPasswordPolicyBreached, sut.Check("qwertyuiop12", nil))
+	assert.Nil(t, sut.Check("correct horse battery", nil))
+}
+
+func Test_LoadPasswordPolicy_returns_error_on_missing_file(t *testing.T) {
+	// Act
+	sut, err := LoadPasswordPolicy(8, 5, filepath.Join(t.TempDir(), "missing.txt"))
+
+	// Assert
+	assert.Nil(t, sut)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,20 @@
+package lib
+
+import (
+	"errors"
+)
+
+var (
+	ErrPasswordResetStoreInvalidToken = errors.New("invalid password reset token")
+)
+
+// PasswordResetStore issues short lived, single use tokens which allow a user to
+// set a new password without knowing the current one.
+type PasswordResetStore interface {
+	// Issue replaces an earlier token of the subject, only the latest link works.
+	Issue(subject string) (string, error)
+	// Lookup returns the subject of the token without using it up, so a new
+	// password which is rejected by the policy can be corrected.
+	Lookup(token string) (string, error)
+	Redeem(token string) (string, error)
+}
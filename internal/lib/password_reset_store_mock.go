@@ -0,0 +1,37 @@
+package lib
+
+type PasswordResetStoreMock struct {
+	IssueCalled  bool
+	LookupCalled bool
+	RedeemCalled bool
+
+	LastSubject string
+	LastToken   string
+
+	NextIssueResult string
+	NextIssueError  error
+
+	NextLookupResult string
+	NextLookupError  error
+
+	NextRedeemResult string
+	NextRedeemError  error
+}
+
+func (m *PasswordResetStoreMock) Issue(subject string) (string, error) {
+	m.IssueCalled = true
+	m.LastSubject = subject
+	return m.NextIssueResult, m.NextIssueError
+}
+
+func (m *PasswordResetStoreMock) Lookup(token string) (string, error) {
+	m.LookupCalled = true
+	m.LastToken = token
+	return m.NextLookupResult, m.NextLookupError
+}
+
+func (m *PasswordResetStoreMock) Redeem(token string) (string, error) {
+	m.RedeemCalled = true
+	m.LastToken = token
+	return m.NextRedeemResult, m.NextRedeemError
+}
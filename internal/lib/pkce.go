@@ -0,0 +1,18 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+)
+
+// VerifyCodeChallenge checks a PKCE code verifier against the S256 code challenge
+// of RFC 7636, the plain method is not supported.
+func VerifyCodeChallenge(codeVerifier string, codeChallenge string) bool {
+	// RFC 7636 section 4.1 requires 43 to 128 characters
+	if len(codeVerifier) < 43 || len(codeVerifier) > 128 {
+		return false
+	}
+
+	hash := sha256.Sum256([]byte(codeVerifier))
+	return subtle.ConstantTimeCompare([]byte(encodeBase64Url(hash[:])), []byte(codeChallenge)) == 1
+}
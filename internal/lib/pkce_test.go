@@ -0,0 +1,31 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VerifyCodeChallenge_accepts_verifier_of_rfc_7636_example(t *testing.T) {
+	// Act
+	res := VerifyCodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM")
+
+	// Assert
+	assert.True(t, res)
+}
+
+func Test_VerifyCodeChallenge_rejects_wrong_verifier(t *testing.T) {
+	// Act
+	res := VerifyCodeChallenge("eBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM")
+
+	// Assert
+	assert.False(t, res)
+}
+
+func Test_VerifyCodeChallenge_rejects_short_verifier(t *testing.T) {
+	// Act
+	res := VerifyCodeChallenge("some-verifier", "some-challenge")
+
+	// Assert
+	assert.False(t, res)
+}
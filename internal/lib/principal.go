@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"context"
+	"strings"
+)
+
+// Principal is the caller of a request, taken from the claims of a validated token.
+// Client is set for callers which aren't users, like clients of the
+// client_credentials grant, API keys and certificates, they have no roles.
+type Principal struct {
+	Subject string
+	Issuer  string
+	Scopes  []string
+	Amr     []string
+	Roles   []string
+	Client  bool
+	Claims  map[string]interface{}
+}
+
+type principalContextKey struct{}
+
+// NewPrincipal reads the registered claims, scopes are read from the space separated
+// scope claim of RFC 8693, the authentication methods from the amr claim of
+// OpenID Connect and the roles of users from the roles claim. Tokens of the
+// client_credentials grant are marked by their gty claim.
+func NewPrincipal(claims map[string]interface{}) *Principal {
+	principal := &Principal{
+		Scopes: []string{},
+		Amr:    []string{},
+		Roles:  []string{},
+		Claims: claims,
+	}
+
+	principal.Subject, _ = claims["sub"].(string)
+	principal.Issuer, _ = claims["iss"].(string)
+	principal.Client = claims["gty"] == ClientCredentialsGrantType
+
+	if scope, ok := claims["scope"].(string); ok {
+		principal.Scopes = strings.Fields(scope)
+	}
+
+	if amr, ok := claims["amr"].([]interface{}); ok {
+		for _, method := range amr {
+			if method, ok := method.(string); ok {
+				principal.Amr = append(principal.Amr, method)
+			}
+		}
+	}
+
+	if roles, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range roles {
+			if role, ok := role.(string); ok {
+				principal.Roles = append(principal.Roles, role)
+			}
+		}
+	}
+
+	return principal
+}
+
+// HasScope is true when the token was issued for scope.
+func (p *Principal) HasScope(scope string) bool {
+	for _, granted := range p.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasAmr is true when the user authenticated with method, for example "otp".
+func (p *Principal) HasAmr(method string) bool {
+	for _, used := range p.Amr {
+		if used == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns false for requests which were not authenticated.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
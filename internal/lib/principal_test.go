@@ -0,0 +1,109 @@
+package lib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewPrincipal_reads_registered_claims_and_scopes(t *testing.T) {
+	// Arrange
+	claims := map[string]interface{}{
+		"sub":   "some-user",
+		"iss":   "some-issuer",
+		"scope": "some-scope other-scope",
+	}
+
+	// Act
+	sut := NewPrincipal(claims)
+
+	// Assert
+	assert.Equal(t, "some-user", sut.Subject)
+	assert.Equal(t, "some-issuer", sut.Issuer)
+	assert.Equal(t, []string{"some-scope", "other-scope"}, sut.Scopes)
+	assert.Equal(t, claims, sut.Claims)
+	assert.True(t, sut.HasScope("other-scope"))
+	assert.False(t, sut.HasScope("some"))
+}
+
+func Test_NewPrincipal_reads_authentication_methods(t *testing.T) {
+	// Arrange
+	claims := map[string]interface{}{
+		"sub": "some-user",
+		"amr": []interface{}{"pwd", "otp"},
+	}
+
+	// Act
+	sut := NewPrincipal(claims)
+
+	// Assert
+	assert.Equal(t, []string{"pwd", "otp"}, sut.Amr)
+	assert.True(t, sut.HasAmr("otp"))
+	assert.False(t, sut.HasAmr("hwk"))
+}
+
+func Test_NewPrincipal_reads_roles(t *testing.T) {
+	// Arrange
+	claims := map[string]interface{}{
+		"sub":   "some-user",
+		"roles": []interface{}{"analyst", "admin"},
+	}
+
+	// Act
+	sut := NewPrincipal(claims)
+
+	// Assert
+	assert.Equal(t, []string{"analyst", "admin"}, sut.Roles)
+}
+
+func Test_NewPrincipal_marks_tokens_of_clients(t *testing.T) {
+	// Arrange
+	claims := map[string]interface{}{
+		"sub":       "some-client",
+		"client_id": "some-client",
+		"gty":       ClientCredentialsGrantType,
+	}
+
+	// Act
+	sut := NewPrincipal(claims)
+	user := NewPrincipal(map[string]interface{}{"sub": "some-client", "client_id": "some-client"})
+
+	// Assert
+	assert.True(t, sut.Client)
+	assert.False(t, user.Client)
+}
+
+func Test_NewPrincipal_handles_missing_claims(t *testing.T) {
+	// Act
+	sut := NewPrincipal(nil)
+
+	// Assert
+	assert.Equal(t, "", sut.Subject)
+	assert.Empty(t, sut.Scopes)
+	assert.Empty(t, sut.Amr)
+	assert.Empty(t, sut.Roles)
+}
+
+func Test_PrincipalFromContext_returns_principal_stored_with_WithPrincipal(t *testing.T) {
+	// Arrange
+	principal := &Principal{Subject: "some-user"}
+	ctx := WithPrincipal(context.Background(), principal)
+
+	// Act
+	res, ok := PrincipalFromContext(ctx)
+
+	// Assert
+	require.True(t, ok)
+	assert.Same(t, principal, res)
+}
+
+func Test_PrincipalFromContext_returns_false_without_principal(t *testing.T) {
+	// Act
+	res, ok := PrincipalFromContext(context.Background())
+
+	// Assert
+	assert.False(t, ok)
+	assert.Nil(t, res)
+}
@@ -0,0 +1,39 @@
+package lib
+
+import (
+	"errors"
+)
+
+var (
+	ErrRefreshTokenStoreInvalidToken = errors.New("invalid refresh token")
+	ErrRefreshTokenStoreReusedToken  = errors.New("refresh token has already been used")
+)
+
+// RefreshGrant is what a refresh token was issued for, it is carried over when the
+// token is rotated so refreshed tokens keep the authentication methods and roles of
+// the login. ClientId is the OAuth client the token was issued to, it is empty for
+// logins at /auth.
+type RefreshGrant struct {
+	Subject  string
+	ClientId string
+	Scopes   []string
+	Amr      []string
+	Roles    []string
+}
+
+// RefreshTokenStore issues single use refresh tokens. Every rotation returns a new
+// token of the same family, presenting a token which was already used revokes the
+// whole family, as either the client or an attacker holds a stolen token.
+type RefreshTokenStore interface {
+	Issue(grant RefreshGrant) (string, error)
+	Rotate(token string) (*RefreshGrant, string, error)
+	// Grant returns what the token was issued for without using it, so callers can
+	// check who the token belongs to before revoking it.
+	Grant(token string) (*RefreshGrant, error)
+	// Revoke returns ErrRefreshTokenStoreInvalidToken for tokens it doesn't know,
+	// so callers can try other token types.
+	Revoke(token string) error
+	// RevokeSubject revokes every token family of the subject, for example when a
+	// user is disabled.
+	RevokeSubject(subject string) error
+}
@@ -0,0 +1,58 @@
+package lib
+
+type RefreshTokenStoreMock struct {
+	IssueCalled  bool
+	RotateCalled bool
+	GrantCalled  bool
+	RevokeCalled bool
+
+	RevokeSubjectCalled bool
+
+	LastGrant   RefreshGrant
+	LastToken   string
+	LastSubject string
+
+	NextIssueResult string
+	NextIssueError  error
+
+	NextRotateGrant  *RefreshGrant
+	NextRotateResult string
+	NextRotateError  error
+
+	NextGrantResult *RefreshGrant
+	NextGrantError  error
+
+	NextRevokeError error
+
+	NextRevokeSubjectError error
+}
+
+func (m *RefreshTokenStoreMock) Issue(grant RefreshGrant) (string, error) {
+	m.IssueCalled = true
+	m.LastGrant = grant
+	return m.NextIssueResult, m.NextIssueError
+}
+
+func (m *RefreshTokenStoreMock) Rotate(token string) (*RefreshGrant, string, error) {
+	m.RotateCalled = true
+	m.LastToken = token
+	return m.NextRotateGrant, m.NextRotateResult, m.NextRotateError
+}
+
+func (m *RefreshTokenStoreMock) Grant(token string) (*RefreshGrant, error) {
+	m.GrantCalled = true
+	m.LastToken = token
+	return m.NextGrantResult, m.NextGrantError
+}
+
+func (m *RefreshTokenStoreMock) Revoke(token string) error {
+	m.RevokeCalled = true
+	m.LastToken = token
+	return m.NextRevokeError
+}
+
+func (m *RefreshTokenStoreMock) RevokeSubject(subject string) error {
+	m.RevokeSubjectCalled = true
+	m.LastSubject = subject
+	return m.NextRevokeSubjectError
+}
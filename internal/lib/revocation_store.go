@@ -0,0 +1,13 @@
+package lib
+
+import (
+	"time"
+)
+
+// RevocationStore keeps the jti of revoked tokens. Entries only have to be kept
+// until the token expires and the leeway of the provider has passed, after that it
+// is rejected anyway.
+type RevocationStore interface {
+	Revoke(jti string, expiresAt time.Time)
+	IsRevoked(jti string) bool
+}
@@ -0,0 +1,27 @@
+package lib
+
+import (
+	"time"
+)
+
+type RevocationStoreMock struct {
+	RevokeCalled    bool
+	IsRevokedCalled bool
+
+	LastJti       string
+	LastExpiresAt time.Time
+
+	NextIsRevokedResult bool
+}
+
+func (m *RevocationStoreMock) Revoke(jti string, expiresAt time.Time) {
+	m.RevokeCalled = true
+	m.LastJti = jti
+	m.LastExpiresAt = expiresAt
+}
+
+func (m *RevocationStoreMock) IsRevoked(jti string) bool {
+	m.IsRevokedCalled = true
+	m.LastJti = jti
+	return m.NextIsRevokedResult
+}
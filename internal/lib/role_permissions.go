@@ -0,0 +1,84 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	ErrRolePermissionsInvalidRole = errors.New("invalid role")
+)
+
+// RolePermissions maps the roles of users to the permissions they grant, for
+// example "analyst" to "sum:compute" and "admin" to "users:manage".
+type RolePermissions struct {
+	issuer      string
+	permissions map[string]map[string]bool
+}
+
+// NewRolePermissions only honours the roles of tokens of issuer, the roles claim
+// of external issuers means whatever they define it to.
+func NewRolePermissions(issuer string, roles map[string][]string) (*RolePermissions, error) {
+	rolePermissions := &RolePermissions{
+		issuer:      issuer,
+		permissions: map[string]map[string]bool{},
+	}
+
+	for role, permissions := range roles {
+		if strings.TrimSpace(role) == "" {
+			return nil, ErrRolePermissionsInvalidRole
+		}
+
+		rolePermissions.permissions[role] = map[string]bool{}
+		for _, permission := range permissions {
+			if strings.TrimSpace(permission) == "" {
+				return nil, fmt.Errorf("%w: %s has an empty permission", ErrRolePermissionsInvalidRole, role)
+			}
+
+			rolePermissions.permissions[role][permission] = true
+		}
+	}
+
+	return rolePermissions, nil
+}
+
+// LoadRolePermissions reads a JSON object with the permissions of each role.
+func LoadRolePermissions(issuer string, path string) (*RolePermissions, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var roles map[string][]string
+	if err := json.Unmarshal(content, &roles); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	return NewRolePermissions(issuer, roles)
+}
+
+// HasPermission is true when one of the roles grants permission, unknown roles
+// grant nothing.
+func (p *RolePermissions) HasPermission(roles []string, permission string) bool {
+	for _, role := range roles {
+		if p.permissions[role][permission] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Grants decides whether the principal holds permission. Users of the issuer are
+// only checked by their roles, clients, API keys, certificates and users of
+// external issuers need a scope of the same name instead.
+func (p *RolePermissions) Grants(principal *Principal, permission string) bool {
+	if principal.Client || principal.Issuer != p.issuer {
+		return principal.HasScope(permission)
+	}
+
+	return p.HasPermission(principal.Roles, permission)
+}
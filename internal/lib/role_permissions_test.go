@@ -0,0 +1,132 @@
+package lib
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RolePermissions_HasPermission_is_true_when_a_role_grants_permission(t *testing.T) {
+	// Arrange
+	sut, err := NewRolePermissions("some-issuer", map[string][]string{
+		"analyst": {"sum:compute"},
+		"admin":   {"users:manage"},
+	})
+	require.Nil(t, err)
+
+	// Act
+	granted := sut.HasPermission([]string{"other-role", "analyst"}, "sum:compute")
+	denied := sut.HasPermission([]string{"analyst"}, "users:manage")
+
+	// Assert
+	assert.True(t, granted)
+	assert.False(t, denied)
+}
+
+func Test_RolePermissions_Grants_checks_roles_of_users(t *testing.T) {
+	// Arrange
+	sut, err := NewRolePermissions("some-issuer", map[string][]string{"analyst": {"sum:compute"}})
+	require.Nil(t, err)
+
+	// a scope doesn't make up for a role which lacks the permission
+	principal := &Principal{Subject: "some-user", Issuer: "some-issuer", Scopes: []string{"users:manage"}, Roles: []string{"analyst"}}
+
+	// Act
+	granted := sut.Grants(principal, "sum:compute")
+	denied := sut.Grants(principal, "users:manage")
+
+	// Assert
+	assert.True(t, granted)
+	assert.False(t, denied)
+}
+
+func Test_RolePermissions_Grants_denies_users_without_roles(t *testing.T) {
+	// Arrange
+	sut, err := NewRolePermissions("some-issuer", map[string][]string{"analyst": {"sum:compute"}})
+	require.Nil(t, err)
+
+	// the scope of a user of the issuer doesn't grant the permission
+	principal := &Principal{Subject: "some-user", Issuer: "some-issuer", Scopes: []string{"sum:compute"}, Roles: []string{}}
+
+	// Act
+	granted := sut.Grants(principal, "sum:compute")
+
+	// Assert
+	assert.False(t, granted)
+}
+
+func Test_RolePermissions_Grants_checks_scopes_of_clients(t *testing.T) {
+	// Arrange
+	sut, err := NewRolePermissions("some-issuer", map[string][]string{"analyst": {"sum:compute"}})
+	require.Nil(t, err)
+
+	principal := &Principal{Subject: "some-client", Issuer: "some-issuer", Scopes: []string{"sum:compute"}, Client: true}
+
+	// Act
+	granted := sut.Grants(principal, "sum:compute")
+	denied := sut.Grants(principal, "users:manage")
+
+	// Assert
+	assert.True(t, granted)
+	assert.False(t, denied)
+}
+
+func Test_RolePermissions_Grants_checks_scopes_of_users_of_other_issuers(t *testing.T) {
+	// Arrange
+	sut, err := NewRolePermissions("some-issuer", map[string][]string{"admin": {"users:manage"}})
+	require.Nil(t, err)
+
+	// an external issuer can put any role into its tokens
+	principal := &Principal{Subject: "some-user", Issuer: "other-issuer", Scopes: []string{"sum:compute"}, Roles: []string{"admin"}}
+
+	// Act
+	granted := sut.Grants(principal, "sum:compute")
+	denied := sut.Grants(principal, "users:manage")
+
+	// Assert
+	assert.True(t, granted)
+	assert.False(t, denied)
+}
+
+func Test_NewRolePermissions_returns_error_on_invalid_roles(t *testing.T) {
+	for _, roles := range []map[string][]string{
+		{" ": {"sum:compute"}},
+		{"analyst": {""}},
+	} {
+		// Act
+		_, err := NewRolePermissions("some-issuer", roles)
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrRolePermissionsInvalidRole))
+	}
+}
+
+func Test_LoadRolePermissions_reads_json_object(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "roles.json")
+	require.Nil(t, os.WriteFile(path, []byte(`{"analyst":["sum:compute"],"admin":["sum:compute","users:manage"]}`), 0600))
+
+	// Act
+	sut, err := LoadRolePermissions("some-issuer", path)
+
+	// Assert
+	require.Nil(t, err)
+	assert.True(t, sut.HasPermission([]string{"admin"}, "users:manage"))
+	assert.False(t, sut.HasPermission([]string{"analyst"}, "users:manage"))
+}
+
+func Test_LoadRolePermissions_returns_error_on_invalid_json(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "roles.json")
+	require.Nil(t, os.WriteFile(path, []byte(`["analyst"]`), 0600))
+
+	// Act
+	_, err := LoadRolePermissions("some-issuer", path)
+
+	// Assert
+	assert.NotNil(t, err)
+}
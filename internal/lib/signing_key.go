@@ -0,0 +1,222 @@
+package lib
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var (
+	ErrSigningKeyUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrSigningKeyInvalidKey           = errors.New("key does not match signing algorithm")
+	ErrSigningKeyInvalidPem           = errors.New("key must be PEM encoded")
+	ErrSigningKeyInvalidCurve         = errors.New("elliptic curve does not match signing algorithm")
+)
+
+// signingMethods lists the supported algorithms, HS512 uses a shared secret, the
+// others an RSA, ECDSA or Ed25519 key pair.
+var signingMethods = map[string]jwt.SigningMethod{
+	"HS512": jwt.SigningMethodHS512,
+	"RS256": jwt.SigningMethodRS256,
+	"RS384": jwt.SigningMethodRS384,
+	"RS512": jwt.SigningMethodRS512,
+	"ES256": jwt.SigningMethodES256,
+	"ES384": jwt.SigningMethodES384,
+	"ES512": jwt.SigningMethodES512,
+	"EdDSA": jwt.SigningMethodEdDSA,
+}
+
+// ecdsaCurves maps the ECDSA algorithms to the curve their keys must use.
+var ecdsaCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// SigningKey is a single key of a KeyRing. Keys created from a public key can only
+// be used to validate tokens.
+type SigningKey struct {
+	Kid       string
+	Algorithm string
+
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	jwk       *Jwk
+}
+
+// NewHmacSigningKey creates an HS512 key. The kid is derived from the secret, this
+// doesn't make guessing the secret any easier as every token signature already allows
+// checking guesses offline.
+func NewHmacSigningKey(secret []byte) *SigningKey {
+	hash := sha256.Sum256(secret)
+
+	return &SigningKey{
+		Kid:       encodeBase64Url(hash[:8]),
+		Algorithm: "HS512",
+		method:    jwt.SigningMethodHS512,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// NewPrivateSigningKey creates a key which can sign and validate tokens, the kid
+// is the thumbprint of the public key.
+func NewPrivateSigningKey(algorithm string, privateKey crypto.PrivateKey) (*SigningKey, error) {
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, ErrSigningKeyInvalidKey
+	}
+
+	key, err := NewPublicSigningKey(algorithm, signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	key.signKey = privateKey
+	return key, nil
+}
+
+// NewPublicSigningKey creates a key which can only validate tokens.
+func NewPublicSigningKey(algorithm string, publicKey crypto.PublicKey) (*SigningKey, error) {
+	method, ok := signingMethods[algorithm]
+	if !ok || algorithm == "HS512" {
+		return nil, fmt.Errorf("%w: %s", ErrSigningKeyUnsupportedAlgorithm, algorithm)
+	}
+
+	if err := checkPublicKey(algorithm, publicKey); err != nil {
+		return nil, err
+	}
+
+	jwk, err := NewJwk(algorithm, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		Kid:       jwk.Kid,
+		Algorithm: algorithm,
+		method:    method,
+		verifyKey: publicKey,
+		jwk:       &jwk,
+	}, nil
+}
+
+// ParsePrivateKeyPem parses PKCS8 keys as well as the PKCS1 (RSA) and SEC1 (EC) formats.
+func ParsePrivateKeyPem(algorithm string, privateKeyPem []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(privateKeyPem)
+	if block == nil {
+		return nil, ErrSigningKeyInvalidPem
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return NewPrivateSigningKey(algorithm, key)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return NewPrivateSigningKey(algorithm, key)
+	}
+
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse private key: %w", err)
+	}
+
+	return NewPrivateSigningKey(algorithm, key)
+}
+
+// ParsePublicKeyPem parses PKIX public keys, or takes the public key from a certificate.
+func ParsePublicKeyPem(algorithm string, publicKeyPem []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(publicKeyPem)
+	if block == nil {
+		return nil, ErrSigningKeyInvalidPem
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return NewPublicSigningKey(algorithm, key)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse public key: %w", err)
+	}
+
+	return NewPublicSigningKey(algorithm, cert.PublicKey)
+}
+
+// GenerateSigningKey creates a new random key for the algorithm. The key only lives
+// in memory, keys which other instances need to know are loaded from a file.
+func GenerateSigningKey(algorithm string) (*SigningKey, error) {
+	var key crypto.PrivateKey
+	var err error
+
+	switch algorithm {
+	case "HS512":
+		secret := make([]byte, 64)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+
+		return NewHmacSigningKey(secret), nil
+
+	case "RS256", "RS384", "RS512":
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+
+	case "ES256", "ES384", "ES512":
+		key, err = ecdsa.GenerateKey(ecdsaCurves[algorithm], rand.Reader)
+
+	case "EdDSA":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrSigningKeyUnsupportedAlgorithm, algorithm)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrivateSigningKey(algorithm, key)
+}
+
+// CanSign is false for keys created from a public key.
+func (k *SigningKey) CanSign() bool {
+	return k.signKey != nil
+}
+
+// checkPublicKey makes sure the key type matches the algorithm, so a misconfiguration
+// is reported at startup instead of on the first request.
+func checkPublicKey(algorithm string, publicKey crypto.PublicKey) error {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if algorithm[:2] == "RS" {
+			return nil
+		}
+
+	case *ecdsa.PublicKey:
+		if curve, ok := ecdsaCurves[algorithm]; ok {
+			if key.Curve != curve {
+				return ErrSigningKeyInvalidCurve
+			}
+
+			return nil
+		}
+
+	case ed25519.PublicKey:
+		if algorithm == "EdDSA" {
+			return nil
+		}
+	}
+
+	return ErrSigningKeyInvalidKey
+}
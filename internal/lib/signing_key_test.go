@@ -0,0 +1,68 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GenerateSigningKey_generates_a_key_for_each_algorithm(t *testing.T) {
+	for algorithm := range signingMethods {
+		// Act
+		key, err := GenerateSigningKey(algorithm)
+
+		// Assert
+		require.Nil(t, err, algorithm)
+		assert.Equal(t, algorithm, key.Algorithm)
+		assert.NotEmpty(t, key.Kid, algorithm)
+		assert.True(t, key.CanSign(), algorithm)
+	}
+}
+
+func Test_GenerateSigningKey_returns_error_on_unsupported_algorithm(t *testing.T) {
+	// Act
+	key, err := GenerateSigningKey("none")
+
+	// Assert
+	assert.Nil(t, key)
+	assert.True(t, errors.Is(err, ErrSigningKeyUnsupportedAlgorithm))
+}
+
+func Test_NewHmacSigningKey_derives_kid_from_secret(t *testing.T) {
+	// Act
+	key := NewHmacSigningKey([]byte("some-secret"))
+	same := NewHmacSigningKey([]byte("some-secret"))
+	other := NewHmacSigningKey([]byte("another-secret"))
+
+	// Assert
+	assert.Equal(t, key.Kid, same.Kid)
+	assert.NotEqual(t, key.Kid, other.Kid)
+	assert.Nil(t, key.jwk)
+}
+
+func Test_ParsePublicKeyPem_creates_a_key_which_cannot_sign(t *testing.T) {
+	// Arrange
+	private := generateTestKey(t, "ES256")
+
+	// Act
+	key, err := ParsePublicKeyPem("ES256", publicKeyToPem(t, private))
+
+	// Assert
+	require.Nil(t, err)
+	assert.False(t, key.CanSign())
+	assert.Equal(t, key.jwk.Kid, key.Kid)
+}
+
+func Test_ParsePublicKeyPem_returns_error_on_hmac_algorithm(t *testing.T) {
+	// Arrange
+	private := generateTestKey(t, "ES256")
+
+	// Act
+	key, err := ParsePublicKeyPem("HS512", publicKeyToPem(t, private))
+
+	// Assert
+	assert.Nil(t, key)
+	assert.True(t, errors.Is(err, ErrSigningKeyUnsupportedAlgorithm))
+}
@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SmtpNotifier sends notifications as plain text emails. net/smtp upgrades the
+// connection with STARTTLS when the server offers it and only sends credentials
+// over TLS or to localhost.
+type SmtpNotifier struct {
+	addr string
+	from string
+	auth smtp.Auth
+	now  func() time.Time
+}
+
+// NewSmtpNotifier sends through the server at addr, a host:port pair. Without a
+// username the server is used without authentication.
+func NewSmtpNotifier(addr string, from string, username string, password string) Notifier {
+	notifier := &SmtpNotifier{
+		addr: addr,
+		from: from,
+		now:  time.Now,
+	}
+
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		notifier.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return notifier
+}
+
+func (n *SmtpNotifier) Notify(notification Notification) error {
+	// line breaks would allow adding headers or recipients to the message
+	if strings.ContainsAny(notification.Recipient, "\r\n") || strings.ContainsAny(notification.Subject, "\r\n") {
+		return ErrNotifierInvalidNotification
+	}
+
+	message := strings.Join([]string{
+		"From: " + n.from,
+		"To: " + notification.Recipient,
+		"Subject: " + mime.QEncoding.Encode("utf-8", notification.Subject),
+		"Date: " + n.now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"",
+		strings.ReplaceAll(strings.ReplaceAll(notification.Body, "\r\n", "\n"), "\n", "\r\n"),
+	}, "\r\n")
+
+	if err := smtp.SendMail(n.addr, n.auth, n.from, []string{notification.Recipient}, []byte(message)); err != nil {
+		return fmt.Errorf("unable to send mail to %s: %w", notification.Recipient, err)
+	}
+
+	return nil
+}
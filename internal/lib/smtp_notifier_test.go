@@ -0,0 +1,164 @@
+package lib
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSmtpServer accepts mails like an SMTP server without STARTTLS or AUTH and
+// keeps the envelope and data of the last one.
+type fakeSmtpServer struct {
+	listener   net.Listener
+	mutex      sync.Mutex
+	from       string
+	recipients []string
+	data       string
+}
+
+func newFakeSmtpServer(t *testing.T) *fakeSmtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	server := &fakeSmtpServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go server.serve(conn)
+		}
+	}()
+
+	return server
+}
+
+func (s *fakeSmtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 localhost fake smtp")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		command := strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.mutex.Lock()
+			s.from = strings.Trim(strings.TrimPrefix(command, "MAIL FROM:"), "<>")
+			s.recipients = nil
+			s.mutex.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.mutex.Lock()
+			s.recipients = append(s.recipients, strings.Trim(strings.TrimPrefix(command, "RCPT TO:"), "<>"))
+			s.mutex.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			data := strings.Builder{}
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				if line == ".\r\n" {
+					break
+				}
+
+				data.WriteString(line)
+			}
+
+			s.mutex.Lock()
+			s.data = data.String()
+			s.mutex.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func (s *fakeSmtpServer) lastMail() (string, []string, string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.from, s.recipients, s.data
+}
+
+func Test_SmtpNotifier_Notify_sends_mail_to_recipient(t *testing.T) {
+	// Arrange
+	server := newFakeSmtpServer(t)
+	sut := NewSmtpNotifier(server.listener.Addr().String(), "no-reply@example.com", "", "")
+
+	// Act
+	err := sut.Notify(Notification{
+		Recipient: "some-user@example.com",
+		Subject:   "Reset your password",
+		Body:      "Open the link\nhttps://example.com/reset",
+	})
+
+	// Assert
+	require.Nil(t, err)
+	from, recipients, data := server.lastMail()
+	assert.Equal(t, "no-reply@example.com", from)
+	assert.Equal(t, []string{"some-user@example.com"}, recipients)
+	assert.Contains(t, data, "To: some-user@example.com\r\n")
+	assert.Contains(t, data, "Subject: Reset your password\r\n")
+	assert.Contains(t, data, "\r\n\r\nOpen the link\r\nhttps://example.com/reset")
+}
+
+func Test_SmtpNotifier_Notify_rejects_line_breaks_in_headers(t *testing.T) {
+	// Arrange
+	server := newFakeSmtpServer(t)
+	sut := NewSmtpNotifier(server.listener.Addr().String(), "no-reply@example.com", "", "")
+
+	// Act
+	err := sut.Notify(Notification{
+		Recipient: "some-user@example.com\r\nBcc: other-user@example.com",
+		Subject:   "Reset your password",
+	})
+
+	// Assert
+	assert.Equal(t, ErrNotifierInvalidNotification, err)
+	_, recipients, _ := server.lastMail()
+	assert.Empty(t, recipients)
+}
+
+func Test_SmtpNotifier_Notify_returns_error_when_server_is_unavailable(t *testing.T) {
+	// Arrange
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	sut := NewSmtpNotifier(addr, "no-reply@example.com", "", "")
+
+	// Act
+	err = sut.Notify(Notification{Recipient: "some-user@example.com", Subject: "Reset your password"})
+
+	// Assert
+	assert.NotNil(t, err)
+}
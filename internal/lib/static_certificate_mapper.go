@@ -0,0 +1,118 @@
+package lib
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	ErrStaticCertificateMapperInvalidIdentity = errors.New("invalid certificate identity")
+)
+
+// certificateName is a name of a certificate together with where it was found.
+type certificateName struct {
+	kind string
+	name string
+}
+
+// StaticCertificateMapper knows the identities up front, like the services of a
+// mesh. URI SANs are checked first as they carry SPIFFE ids, then DNS and email
+// SANs and the common name of the subject last.
+type StaticCertificateMapper struct {
+	identities map[certificateName]CertificateIdentity
+}
+
+func NewStaticCertificateMapper(identities []CertificateIdentity) (*StaticCertificateMapper, error) {
+	mapper := &StaticCertificateMapper{
+		identities: map[certificateName]CertificateIdentity{},
+	}
+
+	for _, identity := range identities {
+		if strings.TrimSpace(identity.Name) == "" {
+			return nil, ErrStaticCertificateMapperInvalidIdentity
+		}
+
+		switch identity.Type {
+		case CertificateIdentityTypeUri, CertificateIdentityTypeDns, CertificateIdentityTypeEmail, CertificateIdentityTypeCn:
+		default:
+			return nil, fmt.Errorf("%w: %s has unknown type %q", ErrStaticCertificateMapperInvalidIdentity, identity.Name, identity.Type)
+		}
+
+		key := certificateName{kind: identity.Type, name: identity.Name}
+		if _, ok := mapper.identities[key]; ok {
+			return nil, fmt.Errorf("%w: %s %s is configured twice", ErrStaticCertificateMapperInvalidIdentity, identity.Type, identity.Name)
+		}
+
+		mapper.identities[key] = identity
+	}
+
+	return mapper, nil
+}
+
+// LoadStaticCertificateMapper reads a JSON array of certificate identities.
+func LoadStaticCertificateMapper(path string) (*StaticCertificateMapper, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var identities []CertificateIdentity
+	if err := json.Unmarshal(content, &identities); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	return NewStaticCertificateMapper(identities)
+}
+
+func (m *StaticCertificateMapper) Principal(certificate *x509.Certificate) (*Principal, error) {
+	for _, name := range certificateNames(certificate) {
+		identity, ok := m.identities[name]
+		if !ok {
+			continue
+		}
+
+		scopes := identity.Scopes
+		if scopes == nil {
+			scopes = []string{}
+		}
+
+		return &Principal{
+			Subject: identity.Name,
+			Scopes:  scopes,
+			Amr:     []string{},
+			Client:  true,
+			Claims: map[string]interface{}{
+				"sub":   identity.Name,
+				"scope": strings.Join(scopes, " "),
+			},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrCertificateMapperUnknownIdentity, certificate.Subject)
+}
+
+// certificateNames lists the names of the certificate in the order they are matched.
+func certificateNames(certificate *x509.Certificate) []certificateName {
+	names := []certificateName{}
+	for _, uri := range certificate.URIs {
+		names = append(names, certificateName{kind: CertificateIdentityTypeUri, name: uri.String()})
+	}
+
+	for _, dnsName := range certificate.DNSNames {
+		names = append(names, certificateName{kind: CertificateIdentityTypeDns, name: dnsName})
+	}
+
+	for _, email := range certificate.EmailAddresses {
+		names = append(names, certificateName{kind: CertificateIdentityTypeEmail, name: email})
+	}
+
+	if certificate.Subject.CommonName != "" {
+		names = append(names, certificateName{kind: CertificateIdentityTypeCn, name: certificate.Subject.CommonName})
+	}
+
+	return names
+}
@@ -0,0 +1,135 @@
+package lib
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCertificate(commonName string, dnsName string, uri string) *x509.Certificate {
+	certificate := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	if dnsName != "" {
+		certificate.DNSNames = []string{dnsName}
+	}
+
+	if uri != "" {
+		parsed, _ := url.Parse(uri)
+		certificate.URIs = []*url.URL{parsed}
+	}
+
+	return certificate
+}
+
+func Test_StaticCertificateMapper_Principal_maps_uri_san_first(t *testing.T) {
+	// Arrange
+	sut, err := NewStaticCertificateMapper([]CertificateIdentity{
+		{Type: CertificateIdentityTypeCn, Name: "some-service", Scopes: []string{"other-scope"}},
+		{Type: CertificateIdentityTypeUri, Name: "spiffe://mesh/some-service", Scopes: []string{"some-scope"}},
+	})
+	require.Nil(t, err)
+
+	// Act
+	principal, err := sut.Principal(newTestCertificate("some-service", "", "spiffe://mesh/some-service"))
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, "spiffe://mesh/some-service", principal.Subject)
+	assert.Equal(t, []string{"some-scope"}, principal.Scopes)
+	assert.True(t, principal.Client)
+	assert.Equal(t, "some-scope", principal.Claims["scope"])
+}
+
+func Test_StaticCertificateMapper_Principal_maps_dns_san_and_common_name(t *testing.T) {
+	// Arrange
+	sut, err := NewStaticCertificateMapper([]CertificateIdentity{
+		{Type: CertificateIdentityTypeDns, Name: "some-service.internal"},
+		{Type: CertificateIdentityTypeCn, Name: "other-service"},
+	})
+	require.Nil(t, err)
+
+	// Act
+	dnsPrincipal, dnsErr := sut.Principal(newTestCertificate("unknown", "some-service.internal", ""))
+	cnPrincipal, cnErr := sut.Principal(newTestCertificate("other-service", "", ""))
+
+	// Assert
+	require.Nil(t, dnsErr)
+	require.Nil(t, cnErr)
+	assert.Equal(t, "some-service.internal", dnsPrincipal.Subject)
+	assert.Equal(t, "other-service", cnPrincipal.Subject)
+	assert.Empty(t, cnPrincipal.Scopes)
+}
+
+func Test_StaticCertificateMapper_Principal_returns_error_on_unknown_identity(t *testing.T) {
+	// Arrange
+	sut, err := NewStaticCertificateMapper([]CertificateIdentity{{Type: CertificateIdentityTypeCn, Name: "some-service"}})
+	require.Nil(t, err)
+
+	// Act
+	principal, err := sut.Principal(newTestCertificate("other-service", "other-service.internal", ""))
+
+	// Assert
+	assert.Nil(t, principal)
+	assert.True(t, errors.Is(err, ErrCertificateMapperUnknownIdentity))
+}
+
+func Test_StaticCertificateMapper_Principal_only_matches_names_of_identity_type(t *testing.T) {
+	// Arrange
+	sut, err := NewStaticCertificateMapper([]CertificateIdentity{
+		{Type: CertificateIdentityTypeCn, Name: "some-service", Scopes: []string{"some-scope"}},
+		{Type: CertificateIdentityTypeDns, Name: "other-service.internal", Scopes: []string{"some-scope"}},
+	})
+	require.Nil(t, err)
+
+	// Act
+	dnsPrincipal, dnsErr := sut.Principal(newTestCertificate("unknown", "some-service", ""))
+	cnPrincipal, cnErr := sut.Principal(newTestCertificate("other-service.internal", "", ""))
+
+	// Assert
+	assert.Nil(t, dnsPrincipal)
+	assert.True(t, errors.Is(dnsErr, ErrCertificateMapperUnknownIdentity))
+	assert.Nil(t, cnPrincipal)
+	assert.True(t, errors.Is(cnErr, ErrCertificateMapperUnknownIdentity))
+}
+
+func Test_NewStaticCertificateMapper_returns_error_on_invalid_identity(t *testing.T) {
+	// Act
+	_, emptyErr := NewStaticCertificateMapper([]CertificateIdentity{{Type: CertificateIdentityTypeCn, Name: " "}})
+	_, typeErr := NewStaticCertificateMapper([]CertificateIdentity{{Name: "some-service"}})
+	_, duplicateErr := NewStaticCertificateMapper([]CertificateIdentity{
+		{Type: CertificateIdentityTypeCn, Name: "some-service"},
+		{Type: CertificateIdentityTypeCn, Name: "some-service"},
+	})
+	_, otherTypeErr := NewStaticCertificateMapper([]CertificateIdentity{
+		{Type: CertificateIdentityTypeCn, Name: "some-service"},
+		{Type: CertificateIdentityTypeDns, Name: "some-service"},
+	})
+
+	// Assert
+	assert.True(t, errors.Is(emptyErr, ErrStaticCertificateMapperInvalidIdentity))
+	assert.True(t, errors.Is(typeErr, ErrStaticCertificateMapperInvalidIdentity))
+	assert.True(t, errors.Is(duplicateErr, ErrStaticCertificateMapperInvalidIdentity))
+	assert.Nil(t, otherTypeErr)
+}
+
+func Test_LoadStaticCertificateMapper_reads_identities_from_json_file(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "client_certificates.json")
+	content := `[{"type":"cn","name":"some-service","scopes":["some-scope"]}]`
+	require.Nil(t, os.WriteFile(path, []byte(content), 0600))
+
+	// Act
+	sut, err := LoadStaticCertificateMapper(path)
+
+	// Assert
+	require.Nil(t, err)
+	principal, err := sut.Principal(newTestCertificate("some-service", "", ""))
+	require.Nil(t, err)
+	assert.Equal(t, []string{"some-scope"}, principal.Scopes)
+}
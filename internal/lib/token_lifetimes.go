@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTokenLifetimesInvalid = errors.New("token lifetimes must be grant=duration pairs separated by commas")
+)
+
+// Duration is a time.Duration which is read from JSON strings like "15m".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(duration)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// TokenLifetimes decides how long access tokens are valid, the lifetime of the
+// client wins over the lifetime of the grant type, which wins over the default.
+type TokenLifetimes struct {
+	Default time.Duration
+	Grants  map[string]time.Duration
+}
+
+func (l TokenLifetimes) Lifetime(grantType string, client *Client) time.Duration {
+	if client != nil && client.TokenLifetime > 0 {
+		return time.Duration(client.TokenLifetime)
+	}
+
+	if lifetime := l.Grants[grantType]; lifetime > 0 {
+		return lifetime
+	}
+
+	if l.Default > 0 {
+		return l.Default
+	}
+
+	return DefaultTokenLifetime
+}
+
+// Longest is the longest lifetime a token can be issued with, keys have to be
+// kept at least that long after a rotation.
+func (l TokenLifetimes) Longest(clients []Client) time.Duration {
+	longest := l.Default
+	if longest <= 0 {
+		longest = DefaultTokenLifetime
+	}
+
+	for _, lifetime := range l.Grants {
+		if lifetime > longest {
+			longest = lifetime
+		}
+	}
+
+	for _, client := range clients {
+		if lifetime := time.Duration(client.TokenLifetime); lifetime > longest {
+			longest = lifetime
+		}
+	}
+
+	return longest
+}
+
+// grantTypes are the grants tokens are issued for, password is the login at /auth.
+var grantTypes = map[string]bool{
+	"client_credentials": true,
+	"authorization_code": true,
+	"refresh_token":      true,
+	"password":           true,
+}
+
+// ParseGrantLifetimes reads lifetimes per grant type, for example
+// "client_credentials=15m,authorization_code=30m". Unknown grant types are an
+// error, so a typo doesn't go unnoticed.
+func ParseGrantLifetimes(value string) (map[string]time.Duration, error) {
+	grants := map[string]time.Duration{}
+
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		grantType, lifetime, found := strings.Cut(pair, "=")
+		grantType = strings.TrimSpace(grantType)
+		if !found || !grantTypes[grantType] {
+			return nil, fmt.Errorf("%w: %s", ErrTokenLifetimesInvalid, pair)
+		}
+
+		duration, err := time.ParseDuration(strings.TrimSpace(lifetime))
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrTokenLifetimesInvalid, pair)
+		}
+
+		grants[grantType] = duration
+	}
+
+	return grants, nil
+}
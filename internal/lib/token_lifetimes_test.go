@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TokenLifetimes_Lifetime_prefers_client_over_grant_over_default(t *testing.T) {
+	// Arrange
+	sut := TokenLifetimes{
+		Default: time.Hour,
+		Grants:  map[string]time.Duration{"client_credentials": 15 * time.Minute},
+	}
+	client := &Client{Id: "some-client", TokenLifetime: Duration(5 * time.Minute)}
+
+	// Act
+	clientLifetime := sut.Lifetime("client_credentials", client)
+	grantLifetime := sut.Lifetime("client_credentials", &Client{Id: "other-client"})
+	defaultLifetime := sut.Lifetime("authorization_code", nil)
+
+	// Assert
+	assert.Equal(t, 5*time.Minute, clientLifetime)
+	assert.Equal(t, 15*time.Minute, grantLifetime)
+	assert.Equal(t, time.Hour, defaultLifetime)
+}
+
+func Test_TokenLifetimes_Lifetime_falls_back_to_default_token_lifetime(t *testing.T) {
+	// Arrange
+	sut := TokenLifetimes{}
+
+	// Act
+	lifetime := sut.Lifetime("client_credentials", nil)
+
+	// Assert
+	assert.Equal(t, DefaultTokenLifetime, lifetime)
+}
+
+func Test_TokenLifetimes_Longest_includes_grants_and_clients(t *testing.T) {
+	// Arrange
+	sut := TokenLifetimes{
+		Default: 15 * time.Minute,
+		Grants:  map[string]time.Duration{"client_credentials": 30 * time.Minute},
+	}
+
+	// Act
+	grantLongest := sut.Longest([]Client{{Id: "some-client", TokenLifetime: Duration(5 * time.Minute)}})
+	clientLongest := sut.Longest([]Client{{Id: "some-client", TokenLifetime: Duration(2 * time.Hour)}})
+	defaultLongest := TokenLifetimes{}.Longest(nil)
+
+	// Assert
+	assert.Equal(t, 30*time.Minute, grantLongest)
+	assert.Equal(t, 2*time.Hour, clientLongest)
+	assert.Equal(t, DefaultTokenLifetime, defaultLongest)
+}
+
+func Test_ParseGrantLifetimes_reads_grant_duration_pairs(t *testing.T) {
+	// Act
+	grants, err := ParseGrantLifetimes("client_credentials=15m, authorization_code=30m,password=1h,refresh_token=45m")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]time.Duration{
+		"client_credentials": 15 * time.Minute,
+		"authorization_code": 30 * time.Minute,
+		"password":           time.Hour,
+		"refresh_token":      45 * time.Minute,
+	}, grants)
+}
+
+func Test_ParseGrantLifetimes_returns_error_on_invalid_pair(t *testing.T) {
+	for _, value := range []string{"client_credentials", "=15m", "client_credentials=soon", "client_credentials=-1m", "client_credential=15m"} {
+		// Act
+		grants, err := ParseGrantLifetimes(value)
+
+		// Assert
+		assert.Nil(t, grants)
+		assert.True(t, errors.Is(err, ErrTokenLifetimesInvalid), value)
+	}
+}
+
+func Test_Client_reads_token_lifetime_from_json(t *testing.T) {
+	// Arrange
+	var client Client
+
+	// Act
+	err := json.Unmarshal([]byte(`{"id": "some-client", "tokenLifetime": "15m"}`), &client)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Equal(t, Duration(15*time.Minute), client.TokenLifetime)
+}
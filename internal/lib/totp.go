@@ -0,0 +1,82 @@
+package lib
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	totpDigits = 6
+	totpPeriod = 30
+
+	// totpSkew also accepts the codes of the previous and the next period, as the
+	// clocks of phones drift
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTotpSecret returns 160 random bits, base32 encoded as authenticator apps
+// expect it.
+func GenerateTotpSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TotpUri is the otpauth:// uri authenticator apps read from a QR code.
+func TotpUri(issuer string, account string, secret string) string {
+	query := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(totpDigits)},
+		"period":    {fmt.Sprint(totpPeriod)},
+	}
+
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+account) + "?" + query.Encode()
+}
+
+// VerifyTotp checks an RFC 6238 code with HMAC-SHA1, 6 digits and a 30 second
+// period. The time step of the code is returned, so a code can only be used once.
+func VerifyTotp(secret string, code string, now time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// totpCode is the HOTP value of RFC 4226 for the time step.
+func totpCode(key []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
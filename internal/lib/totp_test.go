@@ -0,0 +1,79 @@
+package lib
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTotpSecret is the SHA1 secret of the RFC 6238 test vectors, base32 encoded.
+var testTotpSecret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func Test_VerifyTotp_accepts_rfc6238_test_vectors(t *testing.T) {
+	for unix, code := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		// Act
+		step, ok := VerifyTotp(testTotpSecret, code, time.Unix(unix, 0))
+
+		// Assert
+		assert.True(t, ok, code)
+		assert.Equal(t, unix/30, step, code)
+	}
+}
+
+func Test_VerifyTotp_accepts_codes_of_adjacent_periods_only(t *testing.T) {
+	// Arrange
+	now := time.Unix(1111111109, 0)
+
+	// Act
+	_, previous := VerifyTotp(testTotpSecret, "081804", now.Add(30*time.Second))
+	_, next := VerifyTotp(testTotpSecret, "081804", now.Add(-30*time.Second))
+	_, outdated := VerifyTotp(testTotpSecret, "081804", now.Add(90*time.Second))
+
+	// Assert
+	assert.True(t, previous)
+	assert.True(t, next)
+	assert.False(t, outdated)
+}
+
+func Test_VerifyTotp_rejects_invalid_codes(t *testing.T) {
+	for _, code := range []string{"", "000000", "28708", "2870820", "abcdef"} {
+		// Act
+		_, ok := VerifyTotp(testTotpSecret, code, time.Unix(59, 0))
+
+		// Assert
+		assert.False(t, ok, code)
+	}
+}
+
+func Test_GenerateTotpSecret_returns_base32_secret(t *testing.T) {
+	// Act
+	secret, err := GenerateTotpSecret()
+
+	// Assert
+	require.Nil(t, err)
+	key, err := totpEncoding.DecodeString(secret)
+	assert.Nil(t, err)
+	assert.Len(t, key, 20)
+}
+
+func Test_TotpUri_returns_otpauth_uri(t *testing.T) {
+	// Act
+	uri := TotpUri("some-issuer", "some-user", testTotpSecret)
+
+	// Assert
+	parsed, err := url.Parse(uri)
+	require.Nil(t, err)
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/some-issuer:some-user", parsed.Path)
+	assert.Equal(t, testTotpSecret, parsed.Query().Get("secret"))
+	assert.Equal(t, "some-issuer", parsed.Query().Get("issuer"))
+}
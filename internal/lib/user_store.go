@@ -0,0 +1,26 @@
+package lib
+
+import (
+	"errors"
+)
+
+var (
+	ErrUserStoreUnknownUser       = errors.New("unknown user")
+	ErrUserStoreDuplicateUsername = errors.New("username is already taken")
+	ErrUserStoreValidationError   = errors.New("user needs a username and a bcrypt password hash")
+)
+
+// UserStore manages the users of a CredentialStore. Users are addressed by their id,
+// the store assigns it when a user is created.
+type UserStore interface {
+	Create(user User) (*User, error)
+	List() ([]User, error)
+	Get(id string) (*User, error)
+	GetByUsername(username string) (*User, error)
+	SetDisabled(id string, disabled bool) error
+	// SetPasswordHash keeps the previous hash in the password history of the user.
+	SetPasswordHash(id string, passwordHash string) error
+	// SetRoles replaces the roles of the user.
+	SetRoles(id string, roles []string) error
+	Delete(id string) error
+}
@@ -0,0 +1,86 @@
+package lib
+
+type UserStoreMock struct {
+	CreateCalled          bool
+	ListCalled            bool
+	GetCalled             bool
+	GetByUsernameCalled   bool
+	SetDisabledCalled     bool
+	SetPasswordHashCalled bool
+	SetRolesCalled        bool
+	DeleteCalled          bool
+
+	LastUser         User
+	LastId           string
+	LastUsername     string
+	LastDisabled     bool
+	LastPasswordHash string
+	LastRoles        []string
+
+	NextCreateResult *User
+	NextCreateError  error
+
+	NextListResult []User
+	NextListError  error
+
+	NextGetResult *User
+	NextGetError  error
+
+	NextGetByUsernameResult *User
+	NextGetByUsernameError  error
+
+	NextSetDisabledError     error
+	NextSetPasswordHashError error
+	NextSetRolesError        error
+	NextDeleteError          error
+}
+
+func (m *UserStoreMock) Create(user User) (*User, error) {
+	m.CreateCalled = true
+	m.LastUser = user
+	return m.NextCreateResult, m.NextCreateError
+}
+
+func (m *UserStoreMock) List() ([]User, error) {
+	m.ListCalled = true
+	return m.NextListResult, m.NextListError
+}
+
+func (m *UserStoreMock) Get(id string) (*User, error) {
+	m.GetCalled = true
+	m.LastId = id
+	return m.NextGetResult, m.NextGetError
+}
+
+func (m *UserStoreMock) GetByUsername(username string) (*User, error) {
+	m.GetByUsernameCalled = true
+	m.LastUsername = username
+	return m.NextGetByUsernameResult, m.NextGetByUsernameError
+}
+
+func (m *UserStoreMock) SetDisabled(id string, disabled bool) error {
+	m.SetDisabledCalled = true
+	m.LastId = id
+	m.LastDisabled = disabled
+	return m.NextSetDisabledError
+}
+
+func (m *UserStoreMock) SetPasswordHash(id string, passwordHash string) error {
+	m.SetPasswordHashCalled = true
+	m.LastId = id
+	m.LastPasswordHash = passwordHash
+	return m.NextSetPasswordHashError
+}
+
+func (m *UserStoreMock) SetRoles(id string, roles []string) error {
+	m.SetRolesCalled = true
+	m.LastId = id
+	m.LastRoles = roles
+	return m.NextSetRolesError
+}
+
+func (m *UserStoreMock) Delete(id string) error {
+	m.DeleteCalled = true
+	m.LastId = id
+	return m.NextDeleteError
+}
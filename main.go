@@ -1,32 +1,115 @@
 package main
 
 import (
+	"bytes"
 	"coding_exercise/internal/api_handlers"
 	"coding_exercise/internal/app_handlers"
 	"coding_exercise/internal/lib"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
+var (
+	errEmptySecretFile         = errors.New("secret file is empty")
+	errRotationNeedsSecretFile = errors.New("HS512 keys can only be rotated with SECRET_FILE")
+	errKeyRetentionTooShort    = errors.New("KEY_RETENTION is shorter than the longest token lifetime")
+)
+
 type config struct {
-	secret string
-	issuer string
+	secret            string
+	secret_file       string
+	issuer            string
+	signing_algorithm string
+	private_key_file  string
+	public_key_file   string
+	base_url          string
+	key_retention     time.Duration
+	credentials_file  string
+	clients_file      string
+	audience          string
+	token_lifetime    time.Duration
+	grant_lifetimes   map[string]time.Duration
+	leeway            time.Duration
+
+	external_issuers    []string
+	external_audience   string
+	external_algorithms []string
+
+	tls_cert_file            string
+	tls_key_file             string
+	client_ca_file           string
+	client_certificates_file string
+
+	password_policy    *lib.PasswordPolicy
+	notifier           lib.Notifier
+	password_reset_url string
+
+	role_permissions *lib.RolePermissions
 }
 
 func main() {
 	config := getConfig()
-	router := initializeRouter(config)
-	startHttpServer(router)
+	client_store, err := createClientStore(config)
+	if err != nil {
+		log.Fatalf("unable to load clients: %s\n", err)
+	}
+
+	config.key_retention, err = keyRetention(config, client_store.List())
+	if err != nil {
+		log.Fatalf("env var KEY_RETENTION is invalid: %s\n", err)
+	}
+
+	key_ring, err := createKeyRing(config)
+	if err != nil {
+		log.Fatalf("unable to load signing key: %s\n", err)
+	}
+
+	go rotateKeysOnSignal(config, key_ring)
+
+	credential_store, err := createCredentialStore(config)
+	if err != nil {
+		log.Fatalf("unable to load credentials: %s\n", err)
+	}
+
+	certificate_mapper, err := createCertificateMapper(config)
+	if err != nil {
+		log.Fatalf("unable to load client certificates: %s\n", err)
+	}
+
+	router := initializeRouter(config, key_ring, credential_store, client_store, certificate_mapper)
+	startHttpServer(config, router)
 }
 
 func getConfig() *config {
 	// Load env vars
+	signing_algorithm := os.Getenv("SIGNING_ALGORITHM")
+	if signing_algorithm == "" {
+		signing_algorithm = "HS512"
+	}
+
+	// the secret can also be read from SECRET_FILE, only then can HS512 keys be rotated
 	secret := os.Getenv("SECRET")
-	if secret == "" {
-		log.Fatalln("env var SECRET is empty!")
+	secret_file := os.Getenv("SECRET_FILE")
+	private_key_file := os.Getenv("PRIVATE_KEY_FILE")
+	public_key_file := os.Getenv("PUBLIC_KEY_FILE")
+	if signing_algorithm == "HS512" {
+		if secret == "" && secret_file == "" {
+			log.Fatalln("env var SECRET or SECRET_FILE is required!")
+		}
+	} else if private_key_file == "" && public_key_file == "" {
+		log.Fatalln("env var PRIVATE_KEY_FILE or PUBLIC_KEY_FILE is required for signing algorithm " + signing_algorithm)
 	}
 
 	issuer := os.Getenv("ISSUER")
@@ -34,38 +117,622 @@ func getConfig() *config {
 		log.Fatalln("env var ISSUER is empty!")
 	}
 
+	// how long keys stay valid for validation after a rotation, at least the longest
+	// token lifetime, which is also the default
+	var key_retention time.Duration
+	if value := os.Getenv("KEY_RETENTION"); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			log.Fatalf("env var KEY_RETENTION is invalid: %s\n", value)
+		}
+
+		key_retention = duration
+	}
+
+	// the url the service is reachable on, used in the discovery document
+	base_url := os.Getenv("BASE_URL")
+	if base_url == "" {
+		base_url = issuer
+	}
+
+	// the audience access tokens are issued for, only tokens for it are accepted
+	audience := os.Getenv("AUDIENCE")
+	if audience == "" {
+		audience = base_url
+	}
+
+	// how long access tokens are valid, GRANT_TOKEN_LIFETIMES overrides it per grant
+	// type, password for /auth and refresh_token for refreshed tokens included, and
+	// the tokenLifetime of a client overrides both
+	token_lifetime := lib.DefaultTokenLifetime
+	if value := os.Getenv("TOKEN_LIFETIME"); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			log.Fatalf("env var TOKEN_LIFETIME is invalid: %s\n", value)
+		}
+
+		token_lifetime = duration
+	}
+
+	grant_lifetimes, err := lib.ParseGrantLifetimes(os.Getenv("GRANT_TOKEN_LIFETIMES"))
+	if err != nil {
+		log.Fatalf("env var GRANT_TOKEN_LIFETIMES is invalid: %s\n", err)
+	}
+
+	// clock skew which is tolerated when checking exp, nbf and iat of tokens
+	var leeway time.Duration
+	if value := os.Getenv("CLOCK_LEEWAY"); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			log.Fatalf("env var CLOCK_LEEWAY is invalid: %s\n", value)
+		}
+
+		leeway = duration
+	}
+
+	// other issuers whose tokens are accepted by /sum, like the company IdP
+	external_issuers := splitList(os.Getenv("EXTERNAL_ISSUERS"))
+	external_algorithms := splitList(os.Getenv("EXTERNAL_ALGORITHMS"))
+	external_audience := os.Getenv("EXTERNAL_AUDIENCE")
+	if external_audience == "" {
+		external_audience = audience
+	}
+
+	// TLS is used with a certificate, internal callers can then authenticate with a
+	// client certificate of CLIENT_CA_FILE, mapped to a caller by CLIENT_CERTIFICATES_FILE
+	tls_cert_file := os.Getenv("TLS_CERT_FILE")
+	tls_key_file := os.Getenv("TLS_KEY_FILE")
+	if (tls_cert_file == "") != (tls_key_file == "") {
+		log.Fatalln("env vars TLS_CERT_FILE and TLS_KEY_FILE have to be set together")
+	}
+
+	client_ca_file := os.Getenv("CLIENT_CA_FILE")
+	if client_ca_file != "" && tls_cert_file == "" {
+		log.Fatalln("env var CLIENT_CA_FILE needs TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
+	client_certificates_file := os.Getenv("CLIENT_CERTIFICATES_FILE")
+	if client_ca_file != "" && client_certificates_file == "" {
+		log.Println("env var CLIENT_CERTIFICATES_FILE is empty, no client certificate will be accepted")
+	}
+
+	// new passwords users choose themselves need PASSWORD_MIN_LENGTH characters,
+	// can't be one of BREACHED_PASSWORDS_FILE or the last PASSWORD_HISTORY passwords
+	password_min_length := lib.DefaultPasswordMinLength
+	if value := os.Getenv("PASSWORD_MIN_LENGTH"); value != "" {
+		length, err := strconv.Atoi(value)
+		if err != nil || length <= 0 {
+			log.Fatalf("env var PASSWORD_MIN_LENGTH is invalid: %s\n", value)
+		}
+
+		password_min_length = length
+	}
+
+	password_history := lib.DefaultPasswordHistory
+	if value := os.Getenv("PASSWORD_HISTORY"); value != "" {
+		history, err := strconv.Atoi(value)
+		if err != nil || history < 0 {
+			log.Fatalf("env var PASSWORD_HISTORY is invalid: %s\n", value)
+		}
+
+		// only that many earlier passwords are kept per user
+		if history > lib.MaxPasswordHistory {
+			log.Fatalf("env var PASSWORD_HISTORY can be at most %d: %s\n", lib.MaxPasswordHistory, value)
+		}
+
+		password_history = history
+	}
+
+	password_policy, err := lib.LoadPasswordPolicy(password_min_length, password_history, os.Getenv("BREACHED_PASSWORDS_FILE"))
+	if err != nil {
+		log.Fatalf("unable to load breached passwords: %s\n", err)
+	}
+
+	// reset links are sent by email through SMTP_ADDR, the link opens PASSWORD_RESET_URL
+	// which posts the token and the new password to /password/reset
+	var notifier lib.Notifier
+	if smtp_addr := os.Getenv("SMTP_ADDR"); smtp_addr != "" {
+		smtp_from := os.Getenv("SMTP_FROM")
+		if smtp_from == "" {
+			log.Fatalln("env var SMTP_FROM is required with SMTP_ADDR")
+		}
+
+		notifier = lib.NewSmtpNotifier(smtp_addr, smtp_from, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+	} else {
+		log.Println("env var SMTP_ADDR is empty, users won't be able to reset their password")
+	}
+
+	password_reset_url := os.Getenv("PASSWORD_RESET_URL")
+	if password_reset_url == "" {
+		password_reset_url = strings.TrimSuffix(base_url, "/") + "/password/reset"
+	}
+
+	// routes need permissions which ROLES_FILE grants to the roles of users of this
+	// issuer, other callers need a scope of the same name, without the file only
+	// scopes count
+	var role_permissions *lib.RolePermissions
+	if roles_file := os.Getenv("ROLES_FILE"); roles_file != "" {
+		role_permissions, err = lib.LoadRolePermissions(issuer, roles_file)
+		if err != nil {
+			log.Fatalf("unable to load roles: %s\n", err)
+		}
+	}
+
+	credentials_file := os.Getenv("CREDENTIALS_FILE")
+	if credentials_file == "" {
+		log.Println("env var CREDENTIALS_FILE is empty, no user will be able to log in")
+	}
+
+	clients_file := os.Getenv("CLIENTS_FILE")
+	if clients_file == "" {
+		log.Println("env var CLIENTS_FILE is empty, no client will be able to introspect tokens")
+	}
+
 	return &config{
-		secret: secret,
-		issuer: issuer,
+		secret:            secret,
+		secret_file:       secret_file,
+		issuer:            issuer,
+		signing_algorithm: signing_algorithm,
+		private_key_file:  private_key_file,
+		public_key_file:   public_key_file,
+		base_url:          base_url,
+		key_retention:     key_retention,
+		credentials_file:  credentials_file,
+		clients_file:      clients_file,
+		audience:          audience,
+		token_lifetime:    token_lifetime,
+		grant_lifetimes:   grant_lifetimes,
+		leeway:            leeway,
+
+		external_issuers:    external_issuers,
+		external_audience:   external_audience,
+		external_algorithms: external_algorithms,
+
+		tls_cert_file:            tls_cert_file,
+		tls_key_file:             tls_key_file,
+		client_ca_file:           client_ca_file,
+		client_certificates_file: client_certificates_file,
+
+		password_policy:    password_policy,
+		notifier:           notifier,
+		password_reset_url: password_reset_url,
+
+		role_permissions: role_permissions,
+	}
+}
+
+// splitList splits a comma separated env var, empty entries are skipped.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// signingAlgorithm defaults to HS512 with the shared SECRET.
+func signingAlgorithm(config *config) string {
+	if config.signing_algorithm == "" {
+		return "HS512"
+	}
+
+	return config.signing_algorithm
+}
+
+// loadSigningKey uses the shared secret of SECRET_FILE or SECRET for HS512, otherwise
+// the key files. Without a private key the key can only validate tokens.
+func loadSigningKey(config *config) (*lib.SigningKey, error) {
+	if signingAlgorithm(config) == "HS512" {
+		if config.secret_file == "" {
+			return lib.NewHmacSigningKey([]byte(config.secret)), nil
+		}
+
+		secret, err := os.ReadFile(config.secret_file)
+		if err != nil {
+			return nil, err
+		}
+
+		secret = bytes.TrimSpace(secret)
+		if len(secret) == 0 {
+			return nil, fmt.Errorf("%w: %s", errEmptySecretFile, config.secret_file)
+		}
+
+		return lib.NewHmacSigningKey(secret), nil
+	}
+
+	if config.private_key_file != "" {
+		private_key, err := os.ReadFile(config.private_key_file)
+		if err != nil {
+			return nil, err
+		}
+
+		return lib.ParsePrivateKeyPem(config.signing_algorithm, private_key)
+	}
+
+	public_key, err := os.ReadFile(config.public_key_file)
+	if err != nil {
+		return nil, err
+	}
+
+	return lib.ParsePublicKeyPem(config.signing_algorithm, public_key)
+}
+
+func createKeyRing(config *config) (*lib.KeyRing, error) {
+	key, err := loadSigningKey(config)
+	if err != nil {
+		return nil, err
+	}
+
+	key_retention := config.key_retention
+	if key_retention == 0 {
+		key_retention = lib.DefaultKeyRetention
+	}
+
+	return lib.NewKeyRing(key, key_retention), nil
+}
+
+// rotateKeys reads the key files again, so a new key can be put in place before
+// triggering the rotation. A secret of the SECRET env var can't be rotated, a
+// generated one would only live in memory and other instances, or this one after
+// a restart, couldn't validate its tokens.
+func rotateKeys(config *config, key_ring *lib.KeyRing) error {
+	if signingAlgorithm(config) == "HS512" && config.secret_file == "" {
+		return errRotationNeedsSecretFile
+	}
+
+	key, err := loadSigningKey(config)
+	if err != nil {
+		return err
+	}
+
+	if err := key_ring.Rotate(key); err != nil {
+		return err
+	}
+
+	log.Printf("rotated signing key, new kid: %s\n", key.Kid)
+	return nil
+}
+
+// rotateKeysOnSignal rotates the signing key on SIGHUP, without restarting the service.
+func rotateKeysOnSignal(config *config, key_ring *lib.KeyRing) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	for range signals {
+		if err := rotateKeys(config, key_ring); err != nil {
+			log.Printf("unable to rotate signing key: %s\n", err)
+		}
 	}
 }
 
-func initializeRouter(config *config) *mux.Router {
+// createCredentialStore loads the users from CREDENTIALS_FILE, without the file
+// the store is empty.
+func createCredentialStore(config *config) (lib.CredentialStore, error) {
+	if config.credentials_file == "" {
+		return lib.NewInMemoryCredentialStore([]lib.User{})
+	}
+
+	return lib.LoadCredentialStore(config.credentials_file)
+}
+
+// createClientStore loads the clients from CLIENTS_FILE, without the file the
+// store is empty.
+func createClientStore(config *config) (*lib.InMemoryClientStore, error) {
+	if config.clients_file == "" {
+		return lib.NewInMemoryClientStore([]lib.Client{})
+	}
+
+	return lib.LoadClientStore(config.clients_file)
+}
+
+// keyRetention keeps keys for the longest lifetime of tokens signed with them, the
+// clock leeway included, otherwise they fail with an unknown kid after a rotation.
+// A shorter KEY_RETENTION is an error.
+func keyRetention(config *config, clients []lib.Client) (time.Duration, error) {
+	token_lifetimes := lib.TokenLifetimes{Default: config.token_lifetime, Grants: config.grant_lifetimes}
+	longest := token_lifetimes.Longest(clients) + config.leeway
+
+	if config.key_retention == 0 {
+		if longest < lib.DefaultKeyRetention {
+			return lib.DefaultKeyRetention, nil
+		}
+
+		return longest, nil
+	}
+
+	if config.key_retention < longest {
+		return 0, fmt.Errorf("%w: %s is less than %s", errKeyRetentionTooShort, config.key_retention, longest)
+	}
+
+	return config.key_retention, nil
+}
+
+// createCertificateMapper loads the callers allowed to authenticate with a client
+// certificate from CLIENT_CERTIFICATES_FILE, without the file nobody is.
+func createCertificateMapper(config *config) (lib.CertificateMapper, error) {
+	if config.client_certificates_file == "" {
+		return lib.NewStaticCertificateMapper([]lib.CertificateIdentity{})
+	}
+
+	return lib.LoadStaticCertificateMapper(config.client_certificates_file)
+}
+
+// createTlsConfig asks for client certificates of CLIENT_CA_FILE, they are optional
+// so callers without one can still use tokens or api keys.
+func createTlsConfig(config *config) (*tls.Config, error) {
+	tls_config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if config.client_ca_file == "" {
+		return tls_config, nil
+	}
+
+	client_ca, err := os.ReadFile(config.client_ca_file)
+	if err != nil {
+		return nil, err
+	}
+
+	client_cas := x509.NewCertPool()
+	if !client_cas.AppendCertsFromPEM(client_ca) {
+		return nil, fmt.Errorf("no certificates found in %s", config.client_ca_file)
+	}
+
+	tls_config.ClientCAs = client_cas
+	tls_config.ClientAuth = tls.VerifyClientCertIfGiven
+	return tls_config, nil
+}
+
+// createOidcVerifier also trusts the external issuers, the keys of each issuer are
+// found through its discovery document. Without external issuers only tokens of
+// this service are accepted.
+func createOidcVerifier(config *config, oidc_provider lib.OidcProvider) lib.OidcProvider {
+	if len(config.external_issuers) == 0 {
+		return oidc_provider
+	}
+
+	options := []lib.ExternalOidcProviderOption{lib.WithExternalLeeway(config.leeway)}
+	if config.external_audience != "" {
+		options = append(options, lib.WithExternalAudience(config.external_audience))
+	}
+
+	if len(config.external_algorithms) > 0 {
+		options = append(options, lib.WithAllowedAlgorithms(config.external_algorithms...))
+	}
+
+	external := map[string]lib.OidcProvider{}
+	for _, issuer := range config.external_issuers {
+		external[issuer] = lib.NewExternalOidcProvider(issuer, options...)
+	}
+
+	return lib.NewCompositeOidcProvider(config.issuer, oidc_provider, external)
+}
+
+func initializeRouter(config *config, key_ring *lib.KeyRing, credential_store lib.CredentialStore, client_store lib.ClientStore, certificate_mapper lib.CertificateMapper) *mux.Router {
 	router := mux.NewRouter()
 
+	revocation_store := lib.NewInMemoryRevocationStore(config.leeway)
+	oidc_provider_options := []lib.JwtOidcProviderOption{lib.WithRevocationStore(revocation_store)}
+	if config.audience != "" {
+		oidc_provider_options = append(oidc_provider_options, lib.WithAudience(config.audience))
+	}
+
+	if config.token_lifetime > 0 {
+		oidc_provider_options = append(oidc_provider_options, lib.WithTokenLifetime(config.token_lifetime))
+	}
+
+	if config.leeway > 0 {
+		oidc_provider_options = append(oidc_provider_options, lib.WithLeeway(config.leeway))
+	}
+
+	// users can have claims like email or name, which are added to their tokens
+	if claims_enricher, ok := credential_store.(lib.ClaimsEnricher); ok {
+		oidc_provider_options = append(oidc_provider_options, lib.WithClaimsEnricher(claims_enricher))
+	}
+
+	oidc_provider := lib.NewJwtOidcProvider(key_ring, config.issuer, oidc_provider_options...)
+
+	refresh_token_store := lib.NewInMemoryRefreshTokenStore(lib.DefaultRefreshTokenLifetime)
+	authorization_code_store := lib.NewInMemoryAuthorizationCodeStore(lib.DefaultAuthorizationCodeLifetime)
+	token_lifetimes := lib.TokenLifetimes{Default: config.token_lifetime, Grants: config.grant_lifetimes}
+
+	// failed logins lock out the username and the client ip for a while
+	login_throttle := lib.NewLoginThrottle(
+		lib.NewInMemoryLoginAttemptStore(lib.DefaultUsernameLoginAttemptPolicy),
+		lib.NewInMemoryLoginAttemptStore(lib.DefaultIpLoginAttemptPolicy),
+	)
+
+	// users who enrolled a second factor need a one time password to log in
+	mfa_store := lib.NewInMemoryMfaStore(config.issuer)
+
 	// setup auth endpoint
-	oidc_provider := lib.NewHmacOidcProvider(config.secret, config.issuer)
-	app_auth_handler := app_handlers.NewAuthHandler(oidc_provider)
+	app_auth_handler := app_handlers.NewAuthHandler(oidc_provider, credential_store, refresh_token_store, login_throttle, mfa_store, token_lifetimes)
 	api_auth_handler := api_handlers.NewAuthHandler(app_auth_handler)
 	router.HandleFunc("/auth", api_auth_handler.Handle).Methods("POST").Headers("Content-Type", "application/json")
 
-	// setup sum endpoint
+	// setup refresh endpoint
+	app_refresh_handler := app_handlers.NewRefreshHandler(oidc_provider, refresh_token_store, token_lifetimes)
+	api_refresh_handler := api_handlers.NewRefreshHandler(app_refresh_handler)
+	router.HandleFunc("/auth/refresh", api_refresh_handler.Handle).Methods("POST").Headers("Content-Type", "application/json")
+
+	// setup authorization endpoint, the login form posts back to it
+	app_authorize_handler := app_handlers.NewAuthorizeHandler(client_store, credential_store, authorization_code_store, login_throttle, mfa_store)
+	api_authorize_handler := api_handlers.NewAuthorizeHandler(app_authorize_handler)
+	router.HandleFunc("/authorize", api_authorize_handler.Handle).Methods("GET")
+	router.HandleFunc("/authorize", api_authorize_handler.Handle).Methods("POST").Headers("Content-Type", "application/x-www-form-urlencoded")
+
+	// with roles configured, routes also need a permission of the roles of the user
+	permission_handler := func(handler http.Handler, permission string) http.Handler {
+		if config.role_permissions == nil {
+			return handler
+		}
+
+		return api_handlers.NewPermissionMiddleware(config.role_permissions, permission).GetHandler(handler)
+	}
+
+	// setup admin endpoints, they need the admin scope and a login with a second factor
+	admin_auth_middleware := api_handlers.NewOidcAuthMiddleware(oidc_provider, config.issuer, "admin")
+	admin_amr_middleware := api_handlers.NewAmrMiddleware("otp")
+	admin_handler := func(permission string, handler http.HandlerFunc) http.Handler {
+		return admin_auth_middleware.GetHandler(admin_amr_middleware.GetHandler(permission_handler(handler, permission)))
+	}
+
+	api_key_store := lib.NewInMemoryApiKeyStore()
+
+	app_api_key_issue_handler := app_handlers.NewApiKeyIssueHandler(api_key_store)
+	api_api_key_issue_handler := api_handlers.NewApiKeyIssueHandler(app_api_key_issue_handler)
+	router.Handle("/admin/api-keys", admin_handler("api-keys:manage", api_api_key_issue_handler.Handle)).Methods("POST").Headers("Content-Type", "application/json")
+
+	app_api_key_revoke_handler := app_handlers.NewApiKeyRevokeHandler(api_key_store)
+	api_api_key_revoke_handler := api_handlers.NewApiKeyRevokeHandler(app_api_key_revoke_handler)
+	router.Handle("/admin/api-keys/{id}", admin_handler("api-keys:manage", api_api_key_revoke_handler.Handle)).Methods("DELETE")
+
+	// users and their second factors are only managed here when the credential store supports it
+	if user_store, ok := credential_store.(lib.UserStore); ok {
+		// setup mfa endpoints, only tokens of this issuer can enroll, the username is
+		// the account shown in authenticator apps
+		mfa_auth_middleware := api_handlers.NewOidcAuthMiddleware(oidc_provider, config.issuer)
+
+		app_mfa_enroll_handler := app_handlers.NewMfaEnrollHandler(mfa_store, user_store)
+		api_mfa_enroll_handler := api_handlers.NewMfaEnrollHandler(app_mfa_enroll_handler)
+		router.Handle("/mfa/enroll", mfa_auth_middleware.GetHandler(http.HandlerFunc(api_mfa_enroll_handler.Handle))).Methods("POST")
+
+		app_mfa_confirm_handler := app_handlers.NewMfaConfirmHandler(mfa_store)
+		api_mfa_confirm_handler := api_handlers.NewMfaConfirmHandler(app_mfa_confirm_handler)
+		router.Handle("/mfa/confirm", mfa_auth_middleware.GetHandler(http.HandlerFunc(api_mfa_confirm_handler.Handle))).Methods("POST").Headers("Content-Type", "application/json")
+
+		app_user_create_handler := app_handlers.NewUserCreateHandler(user_store)
+		api_user_create_handler := api_handlers.NewUserCreateHandler(app_user_create_handler)
+		router.Handle("/admin/users", admin_handler("users:manage", api_user_create_handler.Handle)).Methods("POST").Headers("Content-Type", "application/json")
+
+		app_user_list_handler := app_handlers.NewUserListHandler(user_store)
+		api_user_list_handler := api_handlers.NewUserListHandler(app_user_list_handler)
+		router.Handle("/admin/users", admin_handler("users:manage", api_user_list_handler.Handle)).Methods("GET")
+
+		app_user_disable_handler := app_handlers.NewUserDisableHandler(user_store, refresh_token_store)
+		api_user_disable_handler := api_handlers.NewUserDisableHandler(app_user_disable_handler, true)
+		api_user_enable_handler := api_handlers.NewUserDisableHandler(app_user_disable_handler, false)
+		router.Handle("/admin/users/{id}/disable", admin_handler("users:manage", api_user_disable_handler.Handle)).Methods("POST")
+		router.Handle("/admin/users/{id}/enable", admin_handler("users:manage", api_user_enable_handler.Handle)).Methods("POST")
+
+		app_user_reset_password_handler := app_handlers.NewUserResetPasswordHandler(user_store, refresh_token_store)
+		api_user_reset_password_handler := api_handlers.NewUserResetPasswordHandler(app_user_reset_password_handler)
+		router.Handle("/admin/users/{id}/password", admin_handler("users:manage", api_user_reset_password_handler.Handle)).Methods("POST").Headers("Content-Type", "application/json")
+
+		app_user_set_roles_handler := app_handlers.NewUserSetRolesHandler(user_store, refresh_token_store)
+		api_user_set_roles_handler := api_handlers.NewUserSetRolesHandler(app_user_set_roles_handler)
+		router.Handle("/admin/users/{id}/roles", admin_handler("users:manage", api_user_set_roles_handler.Handle)).Methods("PUT").Headers("Content-Type", "application/json")
+
+		app_user_delete_handler := app_handlers.NewUserDeleteHandler(user_store, refresh_token_store)
+		api_user_delete_handler := api_handlers.NewUserDeleteHandler(app_user_delete_handler)
+		router.Handle("/admin/users/{id}", admin_handler("users:manage", api_user_delete_handler.Handle)).Methods("DELETE")
+
+		// setup password endpoints, users change their password with a token of this
+		// issuer or reset it with a link sent by the notifier
+		password_policy := config.password_policy
+		if password_policy == nil {
+			password_policy = lib.NewPasswordPolicy(lib.DefaultPasswordMinLength, lib.DefaultPasswordHistory, nil)
+		}
+
+		password_auth_middleware := api_handlers.NewOidcAuthMiddleware(oidc_provider, config.issuer)
+		app_password_change_handler := app_handlers.NewPasswordChangeHandler(credential_store, user_store, password_policy, login_throttle, refresh_token_store)
+		api_password_change_handler := api_handlers.NewPasswordChangeHandler(app_password_change_handler)
+		router.Handle("/password/change", password_auth_middleware.GetHandler(http.HandlerFunc(api_password_change_handler.Handle))).Methods("POST").Headers("Content-Type", "application/json")
+
+		if config.notifier != nil {
+			password_reset_store := lib.NewInMemoryPasswordResetStore(lib.DefaultPasswordResetLifetime)
+
+			password_reset_throttle := lib.NewLoginThrottle(
+				lib.NewInMemoryLoginAttemptStore(lib.DefaultUsernamePasswordResetAttemptPolicy),
+				lib.NewInMemoryLoginAttemptStore(lib.DefaultIpPasswordResetAttemptPolicy),
+			)
+
+			app_password_forgot_handler := app_handlers.NewPasswordForgotHandler(user_store, password_reset_store, config.notifier, config.password_reset_url, password_reset_throttle)
+			api_password_forgot_handler := api_handlers.NewPasswordForgotHandler(app_password_forgot_handler)
+			router.HandleFunc("/password/forgot", api_password_forgot_handler.Handle).Methods("POST").Headers("Content-Type", "application/json")
+
+			app_password_reset_handler := app_handlers.NewPasswordResetHandler(user_store, password_reset_store, password_policy, refresh_token_store)
+			api_password_reset_handler := api_handlers.NewPasswordResetHandler(app_password_reset_handler)
+			router.HandleFunc("/password/reset", api_password_reset_handler.Handle).Methods("POST").Headers("Content-Type", "application/json")
+		}
+	}
+
+	// setup oauth token endpoint
+	app_token_handler := app_handlers.NewTokenHandler(oidc_provider, client_store, authorization_code_store, refresh_token_store, token_lifetimes)
+	api_token_handler := api_handlers.NewTokenHandler(app_token_handler)
+	router.HandleFunc("/token", api_token_handler.Handle).Methods("POST").Headers("Content-Type", "application/x-www-form-urlencoded")
+
+	// setup revocation endpoint, clients can only revoke the tokens issued to them, public
+	// clients are identified by their client_id as RFC 7009 allows. Tokens of logins at
+	// /auth belong to no client, whoever holds them can revoke them.
+	revoke_client_auth_middleware := api_handlers.NewOptionalClientAuthMiddleware(client_store)
+	app_revoke_handler := app_handlers.NewRevokeHandler(oidc_provider, revocation_store, refresh_token_store)
+	api_revoke_handler := api_handlers.NewRevokeHandler(app_revoke_handler)
+	auth_revoke_handler := revoke_client_auth_middleware.GetHandler(http.HandlerFunc(api_revoke_handler.Handle))
+	router.Handle("/revoke", auth_revoke_handler).Methods("POST").Headers("Content-Type", "application/x-www-form-urlencoded")
+
+	// setup introspection endpoint
+	client_auth_middleware := api_handlers.NewClientAuthMiddleware(client_store)
+	app_introspect_handler := app_handlers.NewIntrospectHandler(oidc_provider)
+	api_introspect_handler := api_handlers.NewIntrospectHandler(app_introspect_handler)
+	auth_introspect_handler := client_auth_middleware.GetHandler(http.HandlerFunc(api_introspect_handler.Handle))
+	router.Handle("/introspect", auth_introspect_handler).Methods("POST").Headers("Content-Type", "application/x-www-form-urlencoded")
+
+	// setup jwks endpoint
+	app_jwks_handler := app_handlers.NewJwksHandler(oidc_provider)
+	api_jwks_handler := api_handlers.NewJwksHandler(app_jwks_handler)
+	router.HandleFunc("/.well-known/jwks.json", api_jwks_handler.Handle).Methods("GET")
+
+	// setup discovery endpoint
+	base_url := config.base_url
+	if base_url == "" {
+		base_url = config.issuer
+	}
+
+	app_discovery_handler := app_handlers.NewDiscoveryHandler(config.issuer, base_url, signingAlgorithm(config))
+	api_discovery_handler := api_handlers.NewDiscoveryHandler(app_discovery_handler)
+	router.HandleFunc("/.well-known/openid-configuration", api_discovery_handler.Handle).Methods("GET")
+
+	// setup sum endpoint, it also accepts tokens of the external issuers, api keys
+	// and client certificates
 	app_sum_handler := app_handlers.NewSumHandler()
 	api_sum_handler := api_handlers.NewSumHandler(app_sum_handler)
-	api_auth_middleware := api_handlers.NewOidcAuthMiddleware(oidc_provider)
-	auth_sum_handler := api_auth_middleware.GetHandler(http.HandlerFunc(api_sum_handler.Handle))
+	api_auth_middleware := api_handlers.NewMtlsOrFallbackAuthMiddleware(
+		api_handlers.NewMtlsAuthMiddleware(certificate_mapper, "sum:compute"),
+		api_handlers.NewApiKeyOrBearerAuthMiddleware(
+			api_handlers.NewApiKeyAuthMiddleware(api_key_store, "sum:compute"),
+			api_handlers.NewOidcAuthMiddleware(createOidcVerifier(config, oidc_provider), config.issuer, "sum:compute"),
+		),
+	)
+	auth_sum_handler := api_auth_middleware.GetHandler(permission_handler(http.HandlerFunc(api_sum_handler.Handle), "sum:compute"))
 	router.Handle("/sum", auth_sum_handler).Methods("POST").Headers("Content-Type", "application/json")
 
 	return router
 }
 
-func startHttpServer(router *mux.Router) {
-	log.Print("Listening on :8080...")
+func startHttpServer(config *config, router *mux.Router) {
 	server := &http.Server{
 		Handler: router,
 		Addr:    ":8080",
 	}
 
-	err := server.ListenAndServe()
+	if config.tls_cert_file == "" {
+		log.Print("Listening on :8080...")
+		err := server.ListenAndServe()
+		log.Fatal(err)
+	}
+
+	tls_config, err := createTlsConfig(config)
+	if err != nil {
+		log.Fatalf("unable to load client CA: %s\n", err)
+	}
+
+	server.TLSConfig = tls_config
+
+	log.Print("Listening on :8080 with TLS...")
+	err = server.ListenAndServeTLS(config.tls_cert_file, config.tls_key_file)
 	log.Fatal(err)
 }
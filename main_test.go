@@ -2,14 +2,96 @@ package main
 
 import (
 	"coding_exercise/internal/lib"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
+func createTestKeyRing(t *testing.T, config *config) *lib.KeyRing {
+	key_ring, err := createKeyRing(config)
+	require.Nil(t, err)
+	return key_ring
+}
+
+func createTestCredentialStore(t *testing.T) lib.CredentialStore {
+	password_hash, err := bcrypt.GenerateFromPassword([]byte("some-password"), bcrypt.MinCost)
+	require.Nil(t, err)
+
+	credential_store, err := lib.NewInMemoryCredentialStore([]lib.User{
+		{Id: "some-user-id", Username: "some-user", PasswordHash: string(password_hash), Scopes: []string{"sum:compute"}, Claims: map[string]interface{}{"email": "some-user@example.com"}},
+		{Id: "other-user-id", Username: "other-user", PasswordHash: string(password_hash)},
+		{Id: "admin-user-id", Username: "admin-user", PasswordHash: string(password_hash), Scopes: []string{"admin"}, Roles: []string{"admin"}},
+	})
+	require.Nil(t, err)
+
+	return credential_store
+}
+
+func createTestClientStore(t *testing.T) lib.ClientStore {
+	secret_hash, err := bcrypt.GenerateFromPassword([]byte("some-client-secret"), bcrypt.MinCost)
+	require.Nil(t, err)
+
+	client_store, err := lib.NewInMemoryClientStore([]lib.Client{
+		{Id: "some-client", SecretHash: string(secret_hash), Scopes: []string{"sum:compute"}},
+		{Id: "other-client", SecretHash: string(secret_hash), Scopes: []string{"sum:compute"}},
+		{Id: "some-public-client", Scopes: []string{"sum:compute"}, RedirectUris: []string{"http://localhost/callback"}},
+	})
+	require.Nil(t, err)
+
+	return client_store
+}
+
+func createTestCertificateMapper(t *testing.T) lib.CertificateMapper {
+	certificate_mapper, err := lib.NewStaticCertificateMapper([]lib.CertificateIdentity{
+		{Type: lib.CertificateIdentityTypeCn, Name: "some-service", Scopes: []string{"sum:compute"}},
+		{Type: lib.CertificateIdentityTypeCn, Name: "other-service"},
+	})
+	require.Nil(t, err)
+
+	return certificate_mapper
+}
+
+func writeTestPrivateKey(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.Nil(t, err)
+
+	private_key_file := filepath.Join(t.TempDir(), "private.pem")
+	err = os.WriteFile(private_key_file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	require.Nil(t, err)
+
+	return private_key_file
+}
+
 func Test_Integration_Main_initializeRouter_configures_auth_endpoint(t *testing.T) {
 	// Arrange
 	config := &config{
@@ -17,18 +99,1057 @@ func Test_Integration_Main_initializeRouter_configures_auth_endpoint(t *testing.
 		issuer: "some-issuer",
 	}
 
-	sut := initializeRouter(config)
-	recorder := httptest.NewRecorder()
-	body := strings.NewReader(`{"username":"some-user","password":"some-password"}`)
-	req := httptest.NewRequest("POST", "/auth", body)
-	req.Header.Add("Content-Type", "application/json")
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader(`{"username":"some-user","password":"some-password"}`)
+	req := httptest.NewRequest("POST", "/auth", body)
+	req.Header.Add("Content-Type", "application/json")
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `{"token":"`)
+}
+
+func Test_Integration_Main_initializeRouter_configures_auth_endpoint_with_credential_verification(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	recorder := httptest.NewRecorder()
+	body := strings.NewReader(`{"username":"some-user","password":"wrong-password"}`)
+	req := httptest.NewRequest("POST", "/auth", body)
+	req.Header.Add("Content-Type", "application/json")
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.Equal(t, 401, recorder.Code)
+}
+
+func Test_Integration_Main_initializeRouter_configures_auth_endpoint_with_lockout(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	login := func(password string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/auth", strings.NewReader(`{"username":"some-user","password":"`+password+`"}`))
+		req.Header.Add("Content-Type", "application/json")
+		sut.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	for i := 0; i < lib.DefaultUsernameLoginAttemptPolicy.FreeAttempts+1; i++ {
+		require.Equal(t, 401, login("wrong-password").Code)
+	}
+
+	// Act
+	recorder := login("some-password")
+
+	// Assert
+	assert.Equal(t, 429, recorder.Code)
+	assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+}
+
+func Test_Integration_Main_initializeRouter_configures_refresh_endpoint_with_rotation(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+
+	auth_recorder := httptest.NewRecorder()
+	auth_req := httptest.NewRequest("POST", "/auth", strings.NewReader(`{"username":"some-user","password":"some-password"}`))
+	auth_req.Header.Add("Content-Type", "application/json")
+	sut.ServeHTTP(auth_recorder, auth_req)
+	require.Equal(t, 200, auth_recorder.Code)
+
+	var auth_res map[string]string
+	err := json.Unmarshal(auth_recorder.Body.Bytes(), &auth_res)
+	require.Nil(t, err)
+	require.NotEmpty(t, auth_res["refreshToken"])
+
+	refresh := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/auth/refresh", strings.NewReader(`{"refreshToken":"`+auth_res["refreshToken"]+`"}`))
+		req.Header.Add("Content-Type", "application/json")
+		sut.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Act
+	first := refresh()
+	reused := refresh()
+
+	// Assert
+	assert.Equal(t, 200, first.Code)
+	assert.Contains(t, first.Body.String(), `"refreshToken":"`)
+	assert.Equal(t, 401, reused.Code)
+}
+
+func Test_Integration_Main_initializeRouter_configures_revoke_endpoint(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+
+	token_recorder := httptest.NewRecorder()
+	token_req := httptest.NewRequest("POST", "/token", strings.NewReader("grant_type=client_credentials"))
+	token_req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	token_req.SetBasicAuth("some-client", "some-client-secret")
+	sut.ServeHTTP(token_recorder, token_req)
+	require.Equal(t, 200, token_recorder.Code)
+
+	var token_res map[string]interface{}
+	err := json.Unmarshal(token_recorder.Body.Bytes(), &token_res)
+	require.Nil(t, err)
+	token := token_res["access_token"].(string)
+
+	revoke := func(client_id string, client_secret string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/revoke", strings.NewReader("token="+token))
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(client_id, client_secret)
+		sut.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	sum := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/sum", strings.NewReader(`[1,2]`))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", "Bearer "+token)
+		sut.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Act
+	unauthorized := revoke("some-client", "wrong-secret")
+	revoke_by_other_client := revoke("other-client", "some-client-secret")
+	sum_after_other_client := sum()
+	revoke_token := revoke("some-client", "some-client-secret")
+	sum_after_revoke := sum()
+
+	// Assert
+	assert.Equal(t, 401, unauthorized.Code)
+	assert.Equal(t, 200, revoke_by_other_client.Code)
+	assert.Equal(t, 200, sum_after_other_client.Code)
+	assert.Equal(t, 200, revoke_token.Code)
+	assert.Equal(t, 401, sum_after_revoke.Code)
+	assert.Equal(t, `Bearer realm="some-issuer", error="invalid_token", error_description="token has been revoked"`, sum_after_revoke.Header().Get("WWW-Authenticate"))
+}
+
+func Test_Integration_Main_initializeRouter_revoke_endpoint_revokes_tokens_of_logins(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+
+	auth_recorder := httptest.NewRecorder()
+	auth_req := httptest.NewRequest("POST", "/auth", strings.NewReader(`{"username":"some-user","password":"some-password"}`))
+	auth_req.Header.Add("Content-Type", "application/json")
+	sut.ServeHTTP(auth_recorder, auth_req)
+	require.Equal(t, 200, auth_recorder.Code)
+
+	var auth_res map[string]string
+	err := json.Unmarshal(auth_recorder.Body.Bytes(), &auth_res)
+	require.Nil(t, err)
+
+	// tokens of logins belong to no client, holding them is enough to revoke them
+	revoke := func(form string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/revoke", strings.NewReader(form))
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		sut.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Act
+	revoke_token := revoke("token=" + auth_res["token"])
+	revoke_refresh_token := revoke("token=" + auth_res["refreshToken"] + "&token_type_hint=refresh_token")
+
+	sum_recorder := httptest.NewRecorder()
+	sum_req := httptest.NewRequest("POST", "/sum", strings.NewReader(`[1,2]`))
+	sum_req.Header.Add("Content-Type", "application/json")
+	sum_req.Header.Add("Authorization", "Bearer "+auth_res["token"])
+	sut.ServeHTTP(sum_recorder, sum_req)
+
+	refresh_recorder := httptest.NewRecorder()
+	refresh_req := httptest.NewRequest("POST", "/auth/refresh", strings.NewReader(`{"refreshToken":"`+auth_res["refreshToken"]+`"}`))
+	refresh_req.Header.Add("Content-Type", "application/json")
+	sut.ServeHTTP(refresh_recorder, refresh_req)
+
+	// Assert
+	assert.Equal(t, 200, revoke_token.Code)
+	assert.Equal(t, 200, revoke_refresh_token.Code)
+	assert.Equal(t, 401, sum_recorder.Code)
+	assert.Contains(t, sum_recorder.Header().Get("WWW-Authenticate"), "token has been revoked")
+	assert.Equal(t, 401, refresh_recorder.Code)
+	assert.Contains(t, refresh_recorder.Body.String(), "invalid refresh token")
+}
+
+func Test_Integration_Main_initializeRouter_revoke_endpoint_identifies_public_clients(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+
+	token_recorder := exchangeTestCode(sut, authorizeTestUser(t, sut, ""))
+	require.Equal(t, 200, token_recorder.Code)
+
+	var token_res map[string]interface{}
+	err := json.Unmarshal(token_recorder.Body.Bytes(), &token_res)
+	require.Nil(t, err)
+	refresh_token := token_res["refresh_token"].(string)
+
+	revoke := func(client_id string) *httptest.ResponseRecorder {
+		form := url.Values{"token": {refresh_token}, "token_type_hint": {"refresh_token"}}
+		if client_id != "" {
+			form.Set("client_id", client_id)
+		}
+
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/revoke", strings.NewReader(form.Encode()))
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		sut.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	refresh := func() *httptest.ResponseRecorder {
+		form := url.Values{"grant_type": {"refresh_token"}, "client_id": {"some-public-client"}, "refresh_token": {refresh_token}}
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/token", strings.NewReader(form.Encode()))
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		sut.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Act
+	confidential_without_secret := revoke("some-client")
+	without_client := revoke("")
+	refresh_without_client := refresh()
+	require.Equal(t, 200, refresh_without_client.Code)
+
+	// the refresh token rotates, the old one revokes its family once reused
+	err = json.Unmarshal(refresh_without_client.Body.Bytes(), &token_res)
+	require.Nil(t, err)
+	refresh_token = token_res["refresh_token"].(string)
+
+	revoke_token := revoke("some-public-client")
+	refresh_after_revoke := refresh()
+
+	// Assert
+	assert.Equal(t, 401, confidential_without_secret.Code)
+	assert.Equal(t, 200, without_client.Code)
+	assert.Equal(t, 200, revoke_token.Code)
+	assert.Equal(t, 400, refresh_after_revoke.Code)
+	assert.Contains(t, refresh_after_revoke.Body.String(), "invalid_grant")
+}
+
+func Test_Integration_Main_initializeRouter_configures_introspect_endpoint(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+
+	auth_recorder := httptest.NewRecorder()
+	auth_req := httptest.NewRequest("POST", "/auth", strings.NewReader(`{"username":"some-user","password":"some-password"}`))
+	auth_req.Header.Add("Content-Type", "application/json")
+	sut.ServeHTTP(auth_recorder, auth_req)
+	require.Equal(t, 200, auth_recorder.Code)
+
+	var auth_res map[string]string
+	err := json.Unmarshal(auth_recorder.Body.Bytes(), &auth_res)
+	require.Nil(t, err)
+
+	introspect := func(token string, client_secret string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/introspect", strings.NewReader("token="+token))
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("some-client", client_secret)
+		sut.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Act
+	active := introspect(auth_res["token"], "some-client-secret")
+	inactive := introspect("some-invalid-token", "some-client-secret")
+	unauthorized := introspect(auth_res["token"], "wrong-secret")
+
+	// Assert
+	assert.Equal(t, 200, active.Code)
+	assert.Contains(t, active.Body.String(), `"active":true`)
+	assert.Contains(t, active.Body.String(), `"sub":"some-user-id"`)
+	assert.Equal(t, 200, inactive.Code)
+	assert.JSONEq(t, `{"active":false}`, inactive.Body.String())
+	assert.Equal(t, 401, unauthorized.Code)
+}
+
+func Test_Integration_Main_initializeRouter_configures_token_endpoint_with_client_credentials(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+
+	token_recorder := httptest.NewRecorder()
+	token_req := httptest.NewRequest("POST", "/token", strings.NewReader("grant_type=client_credentials"))
+	token_req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	token_req.SetBasicAuth("some-client", "some-client-secret")
+	sut.ServeHTTP(token_recorder, token_req)
+	require.Equal(t, 200, token_recorder.Code)
+
+	var token_res map[string]interface{}
+	err := json.Unmarshal(token_recorder.Body.Bytes(), &token_res)
+	require.Nil(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/sum", strings.NewReader(`[1,2]`))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+token_res["access_token"].(string))
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.Equal(t, "Bearer", token_res["token_type"])
+	assert.Equal(t, "sum:compute", token_res["scope"])
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `{"sha256Sum":"`)
+}
+
+func Test_Integration_Main_initializeRouter_configures_token_lifetime_of_grant(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret:          "some-secret",
+		issuer:          "some-issuer",
+		token_lifetime:  time.Hour,
+		grant_lifetimes: map[string]time.Duration{"client_credentials": 15 * time.Minute},
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/token", strings.NewReader("grant_type=client_credentials"))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("some-client", "some-client-secret")
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	require.Equal(t, 200, recorder.Code)
+	var res struct {
+		ExpiresIn int64 `json:"expires_in"`
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &res)
+	require.Nil(t, err)
+	assert.Equal(t, int64(900), res.ExpiresIn)
+}
+
+func Test_Integration_Main_initializeRouter_adds_claims_of_user_to_token(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	key_ring := createTestKeyRing(t, config)
+	sut := initializeRouter(config, key_ring, createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/auth", strings.NewReader(`{"username":"some-user","password":"some-password"}`))
+	req.Header.Add("Content-Type", "application/json")
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	require.Equal(t, 200, recorder.Code)
+	var res map[string]string
+	err := json.Unmarshal(recorder.Body.Bytes(), &res)
+	require.Nil(t, err)
+
+	claims, err := lib.NewJwtOidcProvider(key_ring, config.issuer).ValidateToken(res["token"])
+	require.Nil(t, err)
+	assert.Equal(t, "some-user@example.com", claims["email"])
+	assert.Equal(t, "some-user", claims["preferred_username"])
+	assert.Equal(t, "some-user-id", claims["sub"])
+}
+
+// testTotpCode computes the current code of an authenticator app for secret, as
+// described in RFC 6238 with the default parameters.
+func testTotpCode(t *testing.T, secret string) string {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	require.Nil(t, err)
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(time.Now().Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func Test_Integration_Main_initializeRouter_configures_mfa_endpoints(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	key_ring := createTestKeyRing(t, config)
+	sut := initializeRouter(config, key_ring, createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	login_recorder := sendTestJson(sut, "POST", "/auth", "", `{"username":"some-user","password":"some-password"}`)
+	require.Equal(t, 200, login_recorder.Code)
+	var login map[string]string
+	require.Nil(t, json.Unmarshal(login_recorder.Body.Bytes(), &login))
+
+	enroll_recorder := sendTestJson(sut, "POST", "/mfa/enroll", login["token"], "")
+	require.Equal(t, 200, enroll_recorder.Code)
+	var enrollment struct {
+		Secret        string   `json:"secret"`
+		Uri           string   `json:"uri"`
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	require.Nil(t, json.Unmarshal(enroll_recorder.Body.Bytes(), &enrollment))
+
+	// Act
+	confirm_recorder := sendTestJson(sut, "POST", "/mfa/confirm", login["token"], `{"otp":"`+testTotpCode(t, enrollment.Secret)+`"}`)
+	without_otp_recorder := sendTestJson(sut, "POST", "/auth", "", `{"username":"some-user","password":"some-password"}`)
+	reenroll_recorder := sendTestJson(sut, "POST", "/mfa/enroll", login["token"], "")
+	with_otp_recorder := sendTestJson(sut, "POST", "/auth", "", `{"username":"some-user","password":"some-password","otp":"`+enrollment.RecoveryCodes[0]+`"}`)
+
+	// Assert
+	assert.True(t, strings.HasPrefix(enrollment.Uri, "otpauth://totp/some-issuer:some-user?"))
+	assert.Equal(t, 200, confirm_recorder.Code)
+	assert.Equal(t, 401, without_otp_recorder.Code)
+	assert.Equal(t, 403, reenroll_recorder.Code)
+	require.Equal(t, 200, with_otp_recorder.Code)
+
+	var res map[string]string
+	require.Nil(t, json.Unmarshal(with_otp_recorder.Body.Bytes(), &res))
+	claims, err := lib.NewJwtOidcProvider(key_ring, config.issuer).ValidateToken(res["token"])
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{"pwd", "otp"}, claims["amr"])
+}
+
+func sendTestJson(sut *mux.Router, method string, path string, token string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Add("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Add("Authorization", "Bearer "+token)
+	}
+
+	sut.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// loginTestUserWithMfa enrolls a second factor for the user and returns a token of
+// a login with it, as needed for the admin endpoints.
+func loginTestUserWithMfa(t *testing.T, sut *mux.Router, username string) string {
+	login_body := `{"username":"` + username + `","password":"some-password"}`
+	var login map[string]string
+	login_recorder := sendTestJson(sut, "POST", "/auth", "", login_body)
+	require.Equal(t, 200, login_recorder.Code)
+	require.Nil(t, json.Unmarshal(login_recorder.Body.Bytes(), &login))
+
+	var enrollment struct {
+		Secret        string   `json:"secret"`
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}
+	enroll_recorder := sendTestJson(sut, "POST", "/mfa/enroll", login["token"], "")
+	require.Equal(t, 200, enroll_recorder.Code)
+	require.Nil(t, json.Unmarshal(enroll_recorder.Body.Bytes(), &enrollment))
+
+	confirm_recorder := sendTestJson(sut, "POST", "/mfa/confirm", login["token"], `{"otp":"`+testTotpCode(t, enrollment.Secret)+`"}`)
+	require.Equal(t, 200, confirm_recorder.Code)
+
+	otp_body := `{"username":"` + username + `","password":"some-password","otp":"` + enrollment.RecoveryCodes[0] + `"}`
+	otp_recorder := sendTestJson(sut, "POST", "/auth", "", otp_body)
+	require.Equal(t, 200, otp_recorder.Code)
+	require.Nil(t, json.Unmarshal(otp_recorder.Body.Bytes(), &login))
+
+	return login["token"]
+}
+
+func Test_Integration_Main_initializeRouter_configures_api_keys_for_sum_endpoint(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	admin_token := loginTestUserWithMfa(t, sut, "admin-user")
+
+	issue_recorder := sendTestJson(sut, "POST", "/admin/api-keys", admin_token, `{"owner":"some-integration","scopes":["sum:compute"],"expiresIn":"24h"}`)
+	require.Equal(t, 200, issue_recorder.Code)
+	var issued map[string]interface{}
+	require.Nil(t, json.Unmarshal(issue_recorder.Body.Bytes(), &issued))
+
+	sum := func() int {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/sum", strings.NewReader("[1,2]"))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("X-API-Key", issued["key"].(string))
+		sut.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// Act
+	sum_code := sum()
+	revoke_recorder := sendTestJson(sut, "DELETE", "/admin/api-keys/"+issued["id"].(string), admin_token, "")
+	revoked_sum_code := sum()
+
+	// Assert
+	assert.Equal(t, 200, sum_code)
+	assert.Equal(t, 200, revoke_recorder.Code)
+	assert.Equal(t, 401, revoked_sum_code)
+}
+
+func Test_Integration_Main_initializeRouter_requires_second_factor_for_admin_endpoints(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	login_recorder := sendTestJson(sut, "POST", "/auth", "", `{"username":"admin-user","password":"some-password"}`)
+	require.Equal(t, 200, login_recorder.Code)
+	var login map[string]string
+	require.Nil(t, json.Unmarshal(login_recorder.Body.Bytes(), &login))
+
+	user_token := loginTestUserWithMfa(t, sut, "some-user")
+
+	// Act
+	password_only_recorder := sendTestJson(sut, "POST", "/admin/api-keys", login["token"], `{"owner":"some-integration"}`)
+	without_scope_recorder := sendTestJson(sut, "POST", "/admin/api-keys", user_token, `{"owner":"some-integration"}`)
+
+	// Assert
+	assert.Equal(t, 403, password_only_recorder.Code)
+	assert.Equal(t, 403, without_scope_recorder.Code)
+}
+
+func Test_Integration_Main_initializeRouter_configures_user_endpoints(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	admin_token := loginTestUserWithMfa(t, sut, "admin-user")
+
+	var created struct {
+		Id string `json:"id"`
+	}
+	create_recorder := sendTestJson(sut, "POST", "/admin/users", admin_token, `{"username":"new-user","password":"new-password","scopes":["sum:compute"],"roles":["some-role"]}`)
+	require.Equal(t, 200, create_recorder.Code)
+	require.Nil(t, json.Unmarshal(create_recorder.Body.Bytes(), &created))
+
+	login := func(password string) *httptest.ResponseRecorder {
+		return sendTestJson(sut, "POST", "/auth", "", `{"username":"new-user","password":"`+password+`"}`)
+	}
+
+	var tokens map[string]string
+	login_recorder := login("new-password")
+	require.Equal(t, 200, login_recorder.Code)
+	require.Nil(t, json.Unmarshal(login_recorder.Body.Bytes(), &tokens))
+
+	// Act
+	duplicate_recorder := sendTestJson(sut, "POST", "/admin/users", admin_token, `{"username":"new-user","password":"other-password"}`)
+	list_recorder := sendTestJson(sut, "GET", "/admin/users", admin_token, "")
+	disable_recorder := sendTestJson(sut, "POST", "/admin/users/"+created.Id+"/disable", admin_token, "")
+	disabled_login_recorder := login("new-password")
+	disabled_refresh_recorder := sendTestJson(sut, "POST", "/auth/refresh", "", `{"refreshToken":"`+tokens["refreshToken"]+`"}`)
+	enable_recorder := sendTestJson(sut, "POST", "/admin/users/"+created.Id+"/enable", admin_token, "")
+	reset_recorder := sendTestJson(sut, "POST", "/admin/users/"+created.Id+"/password", admin_token, `{"password":"reset-password"}`)
+	reset_login_recorder := login("reset-password")
+	roles_recorder := sendTestJson(sut, "PUT", "/admin/users/"+created.Id+"/roles", admin_token, `{"roles":["other-role"]}`)
+	roles_login_recorder := login("reset-password")
+	delete_recorder := sendTestJson(sut, "DELETE", "/admin/users/"+created.Id, admin_token, "")
+	deleted_login_recorder := login("reset-password")
+	unknown_recorder := sendTestJson(sut, "DELETE", "/admin/users/"+created.Id, admin_token, "")
+
+	// Assert
+	require.NotEmpty(t, created.Id)
+	claims, err := lib.NewJwtOidcProvider(createTestKeyRing(t, config), config.issuer).ValidateToken(tokens["token"])
+	require.Nil(t, err)
+	assert.Equal(t, created.Id, claims["sub"])
+	assert.Equal(t, "new-user", claims["preferred_username"])
+
+	assert.Equal(t, 409, duplicate_recorder.Code)
+	assert.Equal(t, 200, list_recorder.Code)
+	assert.Contains(t, list_recorder.Body.String(), `"username":"new-user"`)
+	assert.NotContains(t, list_recorder.Body.String(), "passwordHash")
+	assert.Equal(t, 200, disable_recorder.Code)
+	assert.Equal(t, 401, disabled_login_recorder.Code)
+	assert.Equal(t, 401, disabled_refresh_recorder.Code)
+	assert.Equal(t, 200, enable_recorder.Code)
+	assert.Equal(t, 200, reset_recorder.Code)
+	assert.Equal(t, 200, reset_login_recorder.Code)
+	assert.Equal(t, 200, roles_recorder.Code)
+	require.Equal(t, 200, roles_login_recorder.Code)
+	require.Nil(t, json.Unmarshal(roles_login_recorder.Body.Bytes(), &tokens))
+	roles_claims, err := lib.NewJwtOidcProvider(createTestKeyRing(t, config), config.issuer).ValidateToken(tokens["token"])
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{"other-role"}, roles_claims["roles"])
+	assert.Equal(t, 200, delete_recorder.Code)
+	assert.Equal(t, 401, deleted_login_recorder.Code)
+	assert.Equal(t, 404, unknown_recorder.Code)
+}
+
+func Test_Integration_Main_initializeRouter_configures_permissions_of_roles(t *testing.T) {
+	// Arrange
+	role_permissions, err := lib.NewRolePermissions("some-issuer", map[string][]string{
+		"analyst": {"sum:compute"},
+		"admin":   {"users:manage"},
+	})
+	require.Nil(t, err)
+
+	config := &config{
+		secret:           "some-secret",
+		issuer:           "some-issuer",
+		role_permissions: role_permissions,
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	admin_token := loginTestUserWithMfa(t, sut, "admin-user")
+
+	login := func(username string) string {
+		create_recorder := sendTestJson(sut, "POST", "/admin/users", admin_token, `{"username":"`+username+`","password":"some-password","scopes":["sum:compute"],"roles":["`+username+`"]}`)
+		require.Equal(t, 200, create_recorder.Code)
+
+		var tokens map[string]string
+		login_recorder := sendTestJson(sut, "POST", "/auth", "", `{"username":"`+username+`","password":"some-password"}`)
+		require.Equal(t, 200, login_recorder.Code)
+		require.Nil(t, json.Unmarshal(login_recorder.Body.Bytes(), &tokens))
+		return tokens["token"]
+	}
+
+	analyst_token := login("analyst")
+	viewer_token := login("viewer")
+
+	var some_user map[string]string
+	some_user_recorder := sendTestJson(sut, "POST", "/auth", "", `{"username":"some-user","password":"some-password"}`)
+	require.Equal(t, 200, some_user_recorder.Code)
+	require.Nil(t, json.Unmarshal(some_user_recorder.Body.Bytes(), &some_user))
+
+	client_recorder := httptest.NewRecorder()
+	client_req := httptest.NewRequest("POST", "/token", strings.NewReader("grant_type=client_credentials"))
+	client_req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	client_req.SetBasicAuth("some-client", "some-client-secret")
+	sut.ServeHTTP(client_recorder, client_req)
+	require.Equal(t, 200, client_recorder.Code)
+
+	var client_tokens map[string]interface{}
+	require.Nil(t, json.Unmarshal(client_recorder.Body.Bytes(), &client_tokens))
+
+	// Act
+	analyst_recorder := sendTestJson(sut, "POST", "/sum", analyst_token, "[1,2]")
+	viewer_recorder := sendTestJson(sut, "POST", "/sum", viewer_token, "[1,2]")
+	without_roles_recorder := sendTestJson(sut, "POST", "/sum", some_user["token"], "[1,2]")
+	client_sum_recorder := sendTestJson(sut, "POST", "/sum", client_tokens["access_token"].(string), "[1,2]")
+	list_recorder := sendTestJson(sut, "GET", "/admin/users", admin_token, "")
+	api_key_recorder := sendTestJson(sut, "POST", "/admin/api-keys", admin_token, `{"name":"some-key","scopes":["sum:compute"]}`)
+
+	// Assert
+	claims, err := lib.NewJwtOidcProvider(createTestKeyRing(t, config), config.issuer).ValidateToken(analyst_token)
+	require.Nil(t, err)
+	assert.Equal(t, []interface{}{"analyst"}, claims["roles"])
+
+	assert.Equal(t, 200, analyst_recorder.Code)
+	assert.Equal(t, 403, viewer_recorder.Code)
+	assert.Contains(t, viewer_recorder.Body.String(), "insufficient_permission")
+	// the scope of a user doesn't make up for missing roles, clients only have scopes
+	assert.Equal(t, 403, without_roles_recorder.Code)
+	assert.Equal(t, 200, client_sum_recorder.Code)
+	assert.Equal(t, 200, list_recorder.Code)
+	assert.Equal(t, 403, api_key_recorder.Code)
+}
+
+func Test_Integration_Main_initializeRouter_configures_password_change_endpoint(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	var login map[string]string
+	login_recorder := sendTestJson(sut, "POST", "/auth", "", `{"username":"some-user","password":"some-password"}`)
+	require.Equal(t, 200, login_recorder.Code)
+	require.Nil(t, json.Unmarshal(login_recorder.Body.Bytes(), &login))
+
+	change := func(current_password string, new_password string) *httptest.ResponseRecorder {
+		return sendTestJson(sut, "POST", "/password/change", login["token"], `{"currentPassword":"`+current_password+`","newPassword":"`+new_password+`"}`)
+	}
+
+	// Act
+	wrong_password_recorder := change("wrong-password", "correct horse battery")
+	too_short_recorder := change("some-password", "too-short")
+	reused_recorder := change("some-password", "some-password")
+	changed_recorder := change("some-password", "correct horse battery")
+	old_login_recorder := sendTestJson(sut, "POST", "/auth", "", `{"username":"some-user","password":"some-password"}`)
+	new_login_recorder := sendTestJson(sut, "POST", "/auth", "", `{"username":"some-user","password":"correct horse battery"}`)
+	refresh_recorder := sendTestJson(sut, "POST", "/auth/refresh", "", `{"refreshToken":"`+login["refreshToken"]+`"}`)
+	unauthenticated_recorder := sendTestJson(sut, "POST", "/password/change", "", `{"currentPassword":"correct horse battery","newPassword":"other horse battery"}`)
+
+	// Assert
+	assert.Equal(t, 403, wrong_password_recorder.Code)
+	assert.Equal(t, 400, too_short_recorder.Code)
+	assert.Equal(t, 400, reused_recorder.Code)
+	assert.Equal(t, 200, changed_recorder.Code)
+	assert.Equal(t, 401, old_login_recorder.Code)
+	assert.Equal(t, 200, new_login_recorder.Code)
+	assert.Equal(t, 401, refresh_recorder.Code)
+	assert.Equal(t, 401, unauthenticated_recorder.Code)
+}
+
+// testNotifier hands the notifications sent in the background to the test.
+type testNotifier struct {
+	sent chan lib.Notification
+}
+
+func (n *testNotifier) Notify(notification lib.Notification) error {
+	n.sent <- notification
+	return nil
+}
+
+func Test_Integration_Main_initializeRouter_configures_password_reset_endpoints(t *testing.T) {
+	// Arrange
+	notifier := &testNotifier{sent: make(chan lib.Notification, 1)}
+	config := &config{
+		secret:             "some-secret",
+		issuer:             "some-issuer",
+		notifier:           notifier,
+		password_reset_url: "https://example.com/reset",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+
+	// the links are sent in the background, only the one of the known user arrives
+	unknown_recorder := sendTestJson(sut, "POST", "/password/forgot", "", `{"username":"unknown-user"}`)
+	require.Equal(t, 200, unknown_recorder.Code)
+
+	forgot_recorder := sendTestJson(sut, "POST", "/password/forgot", "", `{"username":"some-user"}`)
+	require.Equal(t, 200, forgot_recorder.Code)
+	notification := <-notifier.sent
+
+	link := regexp.MustCompile(`https://example.com/reset\?token=\S+`).FindString(notification.Body)
+	link_url, err := url.Parse(link)
+	require.Nil(t, err)
+	token := link_url.Query().Get("token")
+	require.NotEmpty(t, token)
+
+	reset := func(new_password string) *httptest.ResponseRecorder {
+		return sendTestJson(sut, "POST", "/password/reset", "", `{"token":"`+token+`","newPassword":"`+new_password+`"}`)
+	}
+
+	// Act
+	reused_password_recorder := reset("some-password")
+	reset_recorder := reset("correct horse battery")
+	reused_token_recorder := reset("other horse battery")
+	login_recorder := sendTestJson(sut, "POST", "/auth", "", `{"username":"some-user","password":"correct horse battery"}`)
+
+	// Assert
+	assert.Equal(t, "some-user@example.com", notification.Recipient)
+	assert.Equal(t, 400, reused_password_recorder.Code)
+	assert.Equal(t, 200, reset_recorder.Code)
+	assert.Equal(t, 400, reused_token_recorder.Code)
+	assert.Equal(t, 200, login_recorder.Code)
+}
+
+// createTestClientCa writes the certificate of a new CA to a file, the returned
+// function issues client certificates of the CA.
+func createTestClientCa(t *testing.T) (string, func(common_name string) tls.Certificate) {
+	ca_key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	ca_template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "some-ca"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	ca_der, err := x509.CreateCertificate(rand.Reader, ca_template, ca_template, &ca_key.PublicKey, ca_key)
+	require.Nil(t, err)
+	ca, err := x509.ParseCertificate(ca_der)
+	require.Nil(t, err)
+
+	client_ca_file := filepath.Join(t.TempDir(), "client_ca.pem")
+	err = os.WriteFile(client_ca_file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca_der}), 0600)
+	require.Nil(t, err)
+
+	issue := func(common_name string) tls.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.Nil(t, err)
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      pkix.Name{CommonName: common_name},
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, ca_key)
+		require.Nil(t, err)
+
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+
+	return client_ca_file, issue
+}
+
+func Test_Integration_Main_initializeRouter_configures_sum_endpoint_with_client_certificates(t *testing.T) {
+	// Arrange
+	client_ca_file, issue := createTestClientCa(t)
+	config := &config{
+		secret:         "some-secret",
+		issuer:         "some-issuer",
+		client_ca_file: client_ca_file,
+	}
+
+	tls_config, err := createTlsConfig(config)
+	require.Nil(t, err)
+
+	server := httptest.NewUnstartedServer(initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t)))
+	server.TLS = tls_config
+	server.StartTLS()
+	defer server.Close()
+
+	sum := func(client_certificates ...tls.Certificate) int {
+		// a new transport for every request, the certificate is bound to the connection
+		transport := server.Client().Transport.(*http.Transport).Clone()
+		transport.TLSClientConfig.Certificates = client_certificates
+		client := &http.Client{Transport: transport}
+
+		res, err := client.Post(server.URL+"/sum", "application/json", strings.NewReader("[1,2]"))
+		require.Nil(t, err)
+		defer res.Body.Close()
+
+		return res.StatusCode
+	}
+
+	// Act
+	service_code := sum(issue("some-service"))
+	without_scope_code := sum(issue("other-service"))
+	unknown_code := sum(issue("unknown-service"))
+	without_certificate_code := sum()
+
+	// Assert
+	assert.Equal(t, 200, service_code)
+	assert.Equal(t, 403, without_scope_code)
+	assert.Equal(t, 401, unknown_code)
+	assert.Equal(t, 401, without_certificate_code)
+}
+
+// testCodeVerifier and its challenge are the example of RFC 7636 appendix B.
+const testCodeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+// authorizeTestUser logs in some-user through the login form of the authorization
+// endpoint and returns the authorization code.
+func authorizeTestUser(t *testing.T, sut *mux.Router, scope string) string {
+	authorize_query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"some-public-client"},
+		"redirect_uri":          {"http://localhost/callback"},
+		"scope":                 {scope},
+		"state":                 {"some-state"},
+		"nonce":                 {"some-nonce"},
+		"code_challenge":        {"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+		"code_challenge_method": {"S256"},
+	}
+
+	form_recorder := httptest.NewRecorder()
+	form_req := httptest.NewRequest("GET", "/authorize?"+authorize_query.Encode(), nil)
+	sut.ServeHTTP(form_recorder, form_req)
+	require.Equal(t, 200, form_recorder.Code)
+	require.Contains(t, form_recorder.Body.String(), `<form method="POST" action="/authorize">`)
+
+	// the form is only accepted with the csrf token of its cookie
+	csrf_token := regexp.MustCompile(`name="csrf_token" value="([^"]+)"`).FindStringSubmatch(form_recorder.Body.String())
+	require.Len(t, csrf_token, 2)
+
+	login_form := url.Values{"username": {"some-user"}, "password": {"some-password"}, "csrf_token": {csrf_token[1]}}
+	for name, values := range authorize_query {
+		login_form[name] = values
+	}
+
+	login_recorder := httptest.NewRecorder()
+	login_req := httptest.NewRequest("POST", "/authorize", strings.NewReader(login_form.Encode()))
+	login_req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	for _, cookie := range form_recorder.Result().Cookies() {
+		login_req.AddCookie(cookie)
+	}
+	sut.ServeHTTP(login_recorder, login_req)
+	require.Equal(t, 302, login_recorder.Code)
+
+	redirect_uri, err := url.Parse(login_recorder.Header().Get("Location"))
+	require.Nil(t, err)
+	require.Equal(t, "some-state", redirect_uri.Query().Get("state"))
+
+	return redirect_uri.Query().Get("code")
+}
+
+func exchangeTestCode(sut *mux.Router, code string) *httptest.ResponseRecorder {
+	token_form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {"some-public-client"},
+		"code":          {code},
+		"redirect_uri":  {"http://localhost/callback"},
+		"code_verifier": {testCodeVerifier},
+	}
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/token", strings.NewReader(token_form.Encode()))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	sut.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func Test_Integration_Main_initializeRouter_configures_authorization_code_flow_with_pkce(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	code := authorizeTestUser(t, sut, "")
+
+	// Act
+	first := exchangeTestCode(sut, code)
+	reused := exchangeTestCode(sut, code)
+
+	// Assert
+	require.Equal(t, 200, first.Code)
+	var token_res map[string]interface{}
+	err := json.Unmarshal(first.Body.Bytes(), &token_res)
+	require.Nil(t, err)
+	assert.NotEmpty(t, token_res["access_token"])
+	assert.NotEmpty(t, token_res["refresh_token"])
+	assert.Equal(t, "sum:compute", token_res["scope"])
+
+	assert.Equal(t, 400, reused.Code)
+	assert.Contains(t, reused.Body.String(), "invalid_grant")
+}
+
+func Test_Integration_Main_initializeRouter_configures_refresh_token_grant(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	code_recorder := exchangeTestCode(sut, authorizeTestUser(t, sut, ""))
+	require.Equal(t, 200, code_recorder.Code)
+
+	var code_res map[string]interface{}
+	require.Nil(t, json.Unmarshal(code_recorder.Body.Bytes(), &code_res))
+
+	refresh := func(client_id string, refresh_token string) *httptest.ResponseRecorder {
+		token_form := url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {client_id},
+			"refresh_token": {refresh_token},
+		}
+
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/token", strings.NewReader(token_form.Encode()))
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		sut.ServeHTTP(recorder, req)
+		return recorder
+	}
 
 	// Act
-	sut.ServeHTTP(recorder, req)
+	refreshed := refresh("some-public-client", code_res["refresh_token"].(string))
+	reused := refresh("some-public-client", code_res["refresh_token"].(string))
 
 	// Assert
-	assert.Equal(t, 200, recorder.Code)
-	assert.Contains(t, recorder.Body.String(), `{"token":"`)
+	require.Equal(t, 200, refreshed.Code)
+	var refresh_res map[string]interface{}
+	require.Nil(t, json.Unmarshal(refreshed.Body.Bytes(), &refresh_res))
+	assert.NotEmpty(t, refresh_res["access_token"])
+	assert.NotEmpty(t, refresh_res["refresh_token"])
+	assert.NotEqual(t, code_res["refresh_token"], refresh_res["refresh_token"])
+	assert.Equal(t, "sum:compute", refresh_res["scope"])
+
+	assert.Equal(t, 400, reused.Code)
+	assert.Contains(t, reused.Body.String(), "invalid_grant")
+}
+
+func Test_Integration_Main_initializeRouter_rejects_id_token_on_sum_endpoint_with_audience(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret:   "some-secret",
+		issuer:   "some-issuer",
+		audience: "https://api.example.com",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	token_recorder := exchangeTestCode(sut, authorizeTestUser(t, sut, "openid sum:compute"))
+	require.Equal(t, 200, token_recorder.Code)
+
+	var token_res struct {
+		AccessToken string `json:"access_token"`
+		IdToken     string `json:"id_token"`
+	}
+	err := json.Unmarshal(token_recorder.Body.Bytes(), &token_res)
+	require.Nil(t, err)
+	require.NotEmpty(t, token_res.IdToken)
+
+	sum := func(token string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/sum", strings.NewReader(`[1,2]`))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", "Bearer "+token)
+		sut.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Act
+	with_access_token := sum(token_res.AccessToken)
+	with_id_token := sum(token_res.IdToken)
+
+	// Assert
+	assert.Equal(t, 200, with_access_token.Code)
+	assert.Equal(t, 401, with_id_token.Code)
 }
 
 func Test_Integration_Main_initializeRouter_configures_sum_endpoint_with_authentication_401(t *testing.T) {
@@ -38,7 +1159,7 @@ func Test_Integration_Main_initializeRouter_configures_sum_endpoint_with_authent
 		issuer: "some-issuer",
 	}
 
-	sut := initializeRouter(config)
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
 	recorder := httptest.NewRecorder()
 	body := strings.NewReader(`[1,2]`)
 	req := httptest.NewRequest("POST", "/sum", body)
@@ -58,14 +1179,14 @@ func Test_Integration_Main_initializeRouter_configures_sum_endpoint_with_authent
 		issuer: "some-issuer",
 	}
 
-	sut := initializeRouter(config)
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
 	recorder := httptest.NewRecorder()
 	body := strings.NewReader(`[1,2]`)
 	req := httptest.NewRequest("POST", "/sum", body)
 	req.Header.Add("Content-Type", "application/json")
 
 	oidc_provider := lib.NewHmacOidcProvider(config.secret, config.issuer)
-	token, err := oidc_provider.GenerateToken("some-username")
+	token, err := oidc_provider.GenerateToken("some-username", lib.TokenOptions{Scopes: []string{"sum:compute"}})
 	require.Nil(t, err)
 
 	req.Header.Add("Authorization", "Bearer "+token)
@@ -77,3 +1198,433 @@ func Test_Integration_Main_initializeRouter_configures_sum_endpoint_with_authent
 	assert.Equal(t, 200, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), `{"sha256Sum":"`)
 }
+
+func Test_Integration_Main_initializeRouter_configures_sum_endpoint_with_scope_403(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+
+	auth_recorder := httptest.NewRecorder()
+	auth_req := httptest.NewRequest("POST", "/auth", strings.NewReader(`{"username":"other-user","password":"some-password"}`))
+	auth_req.Header.Add("Content-Type", "application/json")
+	sut.ServeHTTP(auth_recorder, auth_req)
+	require.Equal(t, 200, auth_recorder.Code)
+
+	var auth_res map[string]string
+	err := json.Unmarshal(auth_recorder.Body.Bytes(), &auth_res)
+	require.Nil(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/sum", strings.NewReader(`[1,2]`))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+auth_res["token"])
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.Equal(t, 403, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "insufficient_scope")
+}
+
+// startTestIdp serves the discovery document and jwks of an external issuer and
+// returns a function signing tokens of it.
+func startTestIdp(t *testing.T) (string, func(claims jwt.MapClaims) string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	jwk, err := lib.NewJwk("RS256", key.Public())
+	require.Nil(t, err)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{"issuer": server.URL, "jwks_uri": server.URL + "/jwks"})
+		case "/jwks":
+			json.NewEncoder(w).Encode(lib.JwkSet{Keys: []lib.Jwk{jwk}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	sign := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = jwk.Kid
+		signed, err := token.SignedString(key)
+		require.Nil(t, err)
+		return signed
+	}
+
+	return server.URL, sign
+}
+
+func Test_Integration_Main_initializeRouter_configures_sum_endpoint_with_external_issuer(t *testing.T) {
+	// Arrange
+	external_issuer, sign := startTestIdp(t)
+	config := &config{
+		secret:            "some-secret",
+		issuer:            "some-issuer",
+		external_issuers:  []string{external_issuer},
+		external_audience: "some-audience",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+
+	local_token, err := lib.NewHmacOidcProvider(config.secret, config.issuer).GenerateToken("some-username", lib.TokenOptions{Scopes: []string{"sum:compute"}})
+	require.Nil(t, err)
+
+	now := time.Now().Unix()
+	external_claims := jwt.MapClaims{"iss": external_issuer, "sub": "some-employee", "aud": "some-audience", "exp": now + 60, "scope": "sum:compute"}
+	other_claims := jwt.MapClaims{"iss": "other-issuer", "sub": "some-employee", "aud": "some-audience", "exp": now + 60, "scope": "sum:compute"}
+	other_audience_claims := jwt.MapClaims{"iss": external_issuer, "sub": "some-employee", "aud": "other-audience", "exp": now + 60, "scope": "sum:compute"}
+
+	codes := map[string]int{}
+	for name, token := range map[string]string{
+		"local":          local_token,
+		"external":       sign(external_claims),
+		"other_issuer":   sign(other_claims),
+		"other_audience": sign(other_audience_claims),
+	} {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/sum", strings.NewReader(`[1,2]`))
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add("Authorization", "Bearer "+token)
+
+		// Act
+		sut.ServeHTTP(recorder, req)
+		codes[name] = recorder.Code
+	}
+
+	// Assert
+	assert.Equal(t, map[string]int{"local": 200, "external": 200, "other_issuer": 401, "other_audience": 401}, codes)
+}
+
+func Test_Integration_Main_initializeRouter_checks_scopes_of_external_users_with_roles(t *testing.T) {
+	// Arrange
+	external_issuer, sign := startTestIdp(t)
+	role_permissions, err := lib.NewRolePermissions("some-issuer", map[string][]string{
+		"analyst": {"sum:compute"},
+		"viewer":  {},
+	})
+	require.Nil(t, err)
+
+	config := &config{
+		secret:            "some-secret",
+		issuer:            "some-issuer",
+		external_issuers:  []string{external_issuer},
+		external_audience: "some-audience",
+		role_permissions:  role_permissions,
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+
+	// the roles of the external issuer are unrelated to the local ones, the scope decides
+	now := time.Now().Unix()
+	with_scope_claims := jwt.MapClaims{"iss": external_issuer, "sub": "some-employee", "aud": "some-audience", "exp": now + 60, "scope": "sum:compute", "roles": []string{"viewer"}}
+	local_viewer_token, err := lib.NewHmacOidcProvider(config.secret, config.issuer).GenerateToken("some-username", lib.TokenOptions{Scopes: []string{"sum:compute"}, Roles: []string{"viewer"}})
+	require.Nil(t, err)
+
+	// Act
+	external_recorder := sendTestJson(sut, "POST", "/sum", sign(with_scope_claims), "[1,2]")
+	local_recorder := sendTestJson(sut, "POST", "/sum", local_viewer_token, "[1,2]")
+
+	// Assert
+	assert.Equal(t, 200, external_recorder.Code)
+	assert.Equal(t, 403, local_recorder.Code)
+}
+
+func Test_Integration_Main_initializeRouter_configures_sum_endpoint_with_asymmetric_signing(t *testing.T) {
+	// Arrange
+	config := &config{
+		issuer:            "some-issuer",
+		signing_algorithm: "ES256",
+		private_key_file:  writeTestPrivateKey(t),
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+
+	auth_recorder := httptest.NewRecorder()
+	auth_req := httptest.NewRequest("POST", "/auth", strings.NewReader(`{"username":"some-user","password":"some-password"}`))
+	auth_req.Header.Add("Content-Type", "application/json")
+	sut.ServeHTTP(auth_recorder, auth_req)
+	require.Equal(t, 200, auth_recorder.Code)
+
+	var auth_res map[string]string
+	err := json.Unmarshal(auth_recorder.Body.Bytes(), &auth_res)
+	require.Nil(t, err)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/sum", strings.NewReader(`[1,2]`))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Bearer "+auth_res["token"])
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `{"sha256Sum":"`)
+}
+
+func Test_Main_createKeyRing_returns_error_on_missing_key_file(t *testing.T) {
+	// Arrange
+	config := &config{
+		issuer:            "some-issuer",
+		signing_algorithm: "RS256",
+		private_key_file:  filepath.Join(t.TempDir(), "missing.pem"),
+	}
+
+	// Act
+	key_ring, err := createKeyRing(config)
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Nil(t, key_ring)
+}
+
+func Test_Main_keyRetention_defaults_to_longest_token_lifetime(t *testing.T) {
+	// Arrange
+	config := &config{
+		token_lifetime:  30 * time.Minute,
+		grant_lifetimes: map[string]time.Duration{"password": 2 * time.Hour},
+		leeway:          time.Minute,
+	}
+
+	// Act
+	key_retention, err := keyRetention(config, []lib.Client{{Id: "some-client", TokenLifetime: lib.Duration(3 * time.Hour)}})
+	config.grant_lifetimes = nil
+	default_retention, default_err := keyRetention(config, nil)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, 3*time.Hour+time.Minute, key_retention)
+	assert.Nil(t, default_err)
+	assert.Equal(t, lib.DefaultKeyRetention, default_retention)
+}
+
+func Test_Main_keyRetention_returns_error_when_shorter_than_token_lifetime(t *testing.T) {
+	// Arrange
+	config := &config{
+		key_retention:  time.Hour,
+		token_lifetime: 30 * time.Minute,
+	}
+
+	// Act
+	key_retention, err := keyRetention(config, nil)
+	client_retention, client_err := keyRetention(config, []lib.Client{{Id: "some-client", TokenLifetime: lib.Duration(2 * time.Hour)}})
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, time.Hour, key_retention)
+	assert.True(t, errors.Is(client_err, errKeyRetentionTooShort))
+	assert.Equal(t, time.Duration(0), client_retention)
+}
+
+func Test_Main_createCredentialStore_returns_error_on_missing_file(t *testing.T) {
+	// Arrange
+	config := &config{
+		credentials_file: filepath.Join(t.TempDir(), "missing.json"),
+	}
+
+	// Act
+	credential_store, err := createCredentialStore(config)
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Nil(t, credential_store)
+}
+
+func Test_Main_createClientStore_returns_error_on_missing_file(t *testing.T) {
+	// Arrange
+	config := &config{
+		clients_file: filepath.Join(t.TempDir(), "missing.json"),
+	}
+
+	// Act
+	client_store, err := createClientStore(config)
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Nil(t, client_store)
+}
+
+func Test_Main_createCertificateMapper_returns_error_on_missing_file(t *testing.T) {
+	// Arrange
+	config := &config{
+		client_certificates_file: filepath.Join(t.TempDir(), "missing.json"),
+	}
+
+	// Act
+	certificate_mapper, err := createCertificateMapper(config)
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Nil(t, certificate_mapper)
+}
+
+func Test_Main_createTlsConfig_returns_error_on_client_ca_without_certificates(t *testing.T) {
+	// Arrange
+	client_ca_file := filepath.Join(t.TempDir(), "client_ca.pem")
+	require.Nil(t, os.WriteFile(client_ca_file, []byte("no certificate"), 0600))
+	config := &config{
+		client_ca_file: client_ca_file,
+	}
+
+	// Act
+	tls_config, err := createTlsConfig(config)
+
+	// Assert
+	assert.NotNil(t, err)
+	assert.Nil(t, tls_config)
+}
+
+func Test_Main_rotateKeys_keeps_tokens_of_previous_key_valid(t *testing.T) {
+	// Arrange
+	secret_file := filepath.Join(t.TempDir(), "secret")
+	err := os.WriteFile(secret_file, []byte("some-secret\n"), 0600)
+	require.Nil(t, err)
+
+	config := &config{
+		secret_file: secret_file,
+		issuer:      "some-issuer",
+	}
+
+	key_ring := createTestKeyRing(t, config)
+	oidc_provider := lib.NewJwtOidcProvider(key_ring, config.issuer)
+	token, err := oidc_provider.GenerateToken("some-username", lib.TokenOptions{})
+	require.Nil(t, err)
+
+	previous_kid := key_ring.SigningKey().Kid
+	err = os.WriteFile(secret_file, []byte("other-secret\n"), 0600)
+	require.Nil(t, err)
+
+	// Act
+	err = rotateKeys(config, key_ring)
+
+	// Assert
+	require.Nil(t, err)
+	assert.NotEqual(t, previous_kid, key_ring.SigningKey().Kid)
+	assert.Equal(t, lib.NewHmacSigningKey([]byte("other-secret")).Kid, key_ring.SigningKey().Kid)
+
+	_, err = oidc_provider.ValidateToken(token)
+	assert.Nil(t, err)
+}
+
+func Test_Main_rotateKeys_returns_error_without_secret_file(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret: "some-secret",
+		issuer: "some-issuer",
+	}
+
+	key_ring := createTestKeyRing(t, config)
+	previous_kid := key_ring.SigningKey().Kid
+
+	// Act
+	err := rotateKeys(config, key_ring)
+
+	// Assert
+	assert.True(t, errors.Is(err, errRotationNeedsSecretFile))
+	assert.Equal(t, previous_kid, key_ring.SigningKey().Kid)
+}
+
+func Test_Main_createKeyRing_returns_error_on_empty_secret_file(t *testing.T) {
+	// Arrange
+	secret_file := filepath.Join(t.TempDir(), "secret")
+	err := os.WriteFile(secret_file, []byte("\n"), 0600)
+	require.Nil(t, err)
+
+	config := &config{
+		secret_file: secret_file,
+		issuer:      "some-issuer",
+	}
+
+	// Act
+	key_ring, err := createKeyRing(config)
+
+	// Assert
+	assert.True(t, errors.Is(err, errEmptySecretFile))
+	assert.Nil(t, key_ring)
+}
+
+func Test_Main_rotateKeys_reloads_private_key_file(t *testing.T) {
+	// Arrange
+	config := &config{
+		issuer:            "some-issuer",
+		signing_algorithm: "ES256",
+		private_key_file:  writeTestPrivateKey(t),
+	}
+
+	key_ring := createTestKeyRing(t, config)
+	config.private_key_file = writeTestPrivateKey(t)
+
+	// Act
+	err := rotateKeys(config, key_ring)
+
+	// Assert
+	require.Nil(t, err)
+	assert.Len(t, key_ring.Jwks().Keys, 2)
+	assert.True(t, errors.Is(rotateKeys(config, key_ring), lib.ErrKeyRingDuplicateKey))
+}
+
+func Test_Integration_Main_initializeRouter_configures_jwks_endpoint(t *testing.T) {
+	// Arrange
+	config := &config{
+		issuer:            "some-issuer",
+		signing_algorithm: "ES256",
+		private_key_file:  writeTestPrivateKey(t),
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	require.Equal(t, 200, recorder.Code)
+
+	var jwks lib.JwkSet
+	err := json.Unmarshal(recorder.Body.Bytes(), &jwks)
+	require.Nil(t, err)
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "EC", jwks.Keys[0].Kty)
+	assert.Equal(t, "ES256", jwks.Keys[0].Alg)
+	assert.NotEmpty(t, jwks.Keys[0].Kid)
+}
+
+func Test_Integration_Main_initializeRouter_configures_discovery_endpoint(t *testing.T) {
+	// Arrange
+	config := &config{
+		secret:   "some-secret",
+		issuer:   "some-issuer",
+		base_url: "https://auth.example.com",
+	}
+
+	sut := initializeRouter(config, createTestKeyRing(t, config), createTestCredentialStore(t), createTestClientStore(t), createTestCertificateMapper(t))
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/.well-known/openid-configuration", nil)
+
+	// Act
+	sut.ServeHTTP(recorder, req)
+
+	// Assert
+	require.Equal(t, 200, recorder.Code)
+
+	var document map[string]interface{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &document)
+	require.Nil(t, err)
+	assert.Equal(t, "some-issuer", document["issuer"])
+	assert.Equal(t, "https://auth.example.com/authorize", document["authorization_endpoint"])
+	assert.Equal(t, "https://auth.example.com/token", document["token_endpoint"])
+	assert.Equal(t, "https://auth.example.com/.well-known/jwks.json", document["jwks_uri"])
+	assert.Equal(t, "https://auth.example.com/revoke", document["revocation_endpoint"])
+	assert.Equal(t, "https://auth.example.com/introspect", document["introspection_endpoint"])
+	assert.Equal(t, []interface{}{"HS512"}, document["id_token_signing_alg_values_supported"])
+}
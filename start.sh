@@ -3,5 +3,7 @@
 # SECRET=$(openssl rand -base64 48)
 export SECRET="some-secret"
 export ISSUER="some-issuer"
+export CREDENTIALS_FILE="configs/credentials.json"
+export CLIENTS_FILE="configs/clients.json"
 
 go run main.go